@@ -0,0 +1,258 @@
+package xlorm
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// adminHealthTimeout 健康检查的超时时间
+const adminHealthTimeout = 2 * time.Second
+
+// adminConfig 管理接口配置
+type adminConfig struct {
+	token string
+	auth  func(r *http.Request) bool
+}
+
+// AdminOption 管理接口选项
+type AdminOption func(*adminConfig)
+
+// WithAdminToken 要求请求携带 Authorization: Bearer <token> 请求头
+func WithAdminToken(token string) AdminOption {
+	return func(c *adminConfig) {
+		c.token = token
+	}
+}
+
+// WithAdminAuth 自定义鉴权，返回 false 时拒绝请求
+func WithAdminAuth(auth func(r *http.Request) bool) AdminOption {
+	return func(c *adminConfig) {
+		c.auth = auth
+	}
+}
+
+// AdminHandler 创建数据库运维管理接口，包含以下路径：
+//
+//	GET        /metrics         性能指标
+//	GET        /pool            连接池状态
+//	GET/DELETE /slow-queries    最近的慢查询 / 清空慢查询
+//	GET        /cache-stats     查询缓存统计
+//	GET/PUT    /log-level       获取 / 修改日志级别，PUT 请求体为 {"level":"debug"}
+//	GET        /health          健康检查，数据库不可用时返回 503
+//	GET        /support-bundle  诊断包（见 DB.SupportBundle）
+//
+// 接口可修改日志级别并导出诊断信息，必须通过 WithAdminToken 或 WithAdminAuth 配置鉴权，否则返回错误；
+// 挂载到子路径时配合 http.StripPrefix 使用，例如：
+//
+//	admin, err := xlorm.AdminHandler(db, xlorm.WithAdminToken(token))
+//	mux.Handle("/admin/db/", http.StripPrefix("/admin/db", admin))
+func AdminHandler(db *DB, opts ...AdminOption) (http.Handler, error) {
+	cfg := &adminConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.token == "" && cfg.auth == nil {
+		return nil, errors.New("管理接口必须配置鉴权（WithAdminToken 或 WithAdminAuth）")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", db.adminMetrics)
+	mux.HandleFunc("/pool", db.adminPool)
+	mux.HandleFunc("/slow-queries", db.adminSlowQueries)
+	mux.HandleFunc("/cache-stats", db.adminCacheStats)
+	mux.HandleFunc("/log-level", db.adminLogLevel)
+	mux.HandleFunc("/health", db.adminHealth)
+	mux.HandleFunc("/support-bundle", db.adminSupportBundle)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.authorized(r) {
+			writeAdminJSON(w, http.StatusUnauthorized, map[string]string{"error": "未授权"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}), nil
+}
+
+// authorized 校验请求是否通过鉴权
+func (c *adminConfig) authorized(r *http.Request) bool {
+	if c.token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(c.token)) != 1 {
+			return false
+		}
+	}
+	if c.auth != nil && !c.auth(r) {
+		return false
+	}
+	return true
+}
+
+// adminMetrics 性能指标
+func (db *DB) adminMetrics(w http.ResponseWriter, r *http.Request) {
+	if !allowAdminMethod(w, r, http.MethodGet) {
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, db.metricsSnapshot())
+}
+
+// adminPool 连接池状态
+func (db *DB) adminPool(w http.ResponseWriter, r *http.Request) {
+	if !allowAdminMethod(w, r, http.MethodGet) {
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, poolStatsMap(db.DB.Stats()))
+}
+
+// poolStatsMap 转换连接池统计用于输出
+func poolStatsMap(stats sql.DBStats) map[string]interface{} {
+	return map[string]interface{}{
+		"max_open_connections": stats.MaxOpenConnections,
+		"open_connections":     stats.OpenConnections,
+		"in_use":               stats.InUse,
+		"idle":                 stats.Idle,
+		"wait_count":           stats.WaitCount,
+		"wait_duration":        stats.WaitDuration.String(),
+		"max_idle_closed":      stats.MaxIdleClosed,
+		"max_idle_time_closed": stats.MaxIdleTimeClosed,
+		"max_lifetime_closed":  stats.MaxLifetimeClosed,
+	}
+}
+
+// adminSlowQueries 最近的慢查询
+func (db *DB) adminSlowQueries(w http.ResponseWriter, r *http.Request) {
+	if !allowAdminMethod(w, r, http.MethodGet, http.MethodDelete) {
+		return
+	}
+	if r.Method == http.MethodDelete {
+		db.ClearSlowQueries()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	queries := db.SlowQueries()
+	result := make([]map[string]interface{}, len(queries))
+	for i, q := range queries {
+		result[i] = map[string]interface{}{
+			"trace_id":    q.TraceID,
+			"operation":   q.Operation,
+			"table":       q.Table,
+			"query":       q.Query,
+			"args":        db.redactArgs(q.Query, q.Args),
+			"fingerprint": q.Fingerprint,
+			"start_time":  q.StartTime,
+			"duration":    q.Duration.String(),
+			"rows":        q.Rows,
+		}
+	}
+	writeAdminJSON(w, http.StatusOK, result)
+}
+
+// adminCacheStats 查询缓存统计
+func (db *DB) adminCacheStats(w http.ResponseWriter, r *http.Request) {
+	if !allowAdminMethod(w, r, http.MethodGet) {
+		return
+	}
+	db.queryCache.mu.RLock()
+	cache := db.queryCache.cache
+	tables := len(db.queryCache.tags)
+	db.queryCache.mu.RUnlock()
+
+	stats := map[string]interface{}{
+		"enabled":       cache != nil,
+		"cached_tables": tables,
+	}
+	if m := db.DBMetrics(); m != nil {
+		stats["hits"] = m.cacheHits.Load()
+		stats["misses"] = m.cacheMisses.Load()
+	}
+	// 内置的内存缓存提供了更详细的统计信息
+	if s, ok := cache.(interface{ Stats() map[string]uint64 }); ok {
+		stats["cache"] = s.Stats()
+	}
+	writeAdminJSON(w, http.StatusOK, stats)
+}
+
+// adminLogLevel 获取或修改日志级别
+func (db *DB) adminLogLevel(w http.ResponseWriter, r *http.Request) {
+	if !allowAdminMethod(w, r, http.MethodGet, http.MethodPut) {
+		return
+	}
+	if r.Method == http.MethodPut {
+		var body struct {
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&body); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": "请求体格式错误: " + err.Error()})
+			return
+		}
+		if err := db.SetLogLevel(body.Level); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		db.logger.Info("日志级别已修改", "level", db.GetLogLevel(), "remote", r.RemoteAddr)
+	}
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"level": db.GetLogLevel(),
+		"debug": db.IsDebug(),
+	})
+}
+
+// adminHealth 健康检查
+func (db *DB) adminHealth(w http.ResponseWriter, r *http.Request) {
+	if !allowAdminMethod(w, r, http.MethodGet) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), adminHealthTimeout)
+	defer cancel()
+	start := time.Now()
+	if err := db.Ping(ctx); err != nil {
+		writeAdminJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"status": "down",
+			"error":  err.Error(),
+		})
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"status":        "up",
+		"db_name":       db.GetDBName(),
+		"xlorm_version": db.GetVersion(),
+		"latency":       time.Since(start).String(),
+		"uptime":        time.Since(db.GetStartTime()).Truncate(time.Second).String(),
+	})
+}
+
+// allowAdminMethod 校验请求方法，不允许时返回 405
+func allowAdminMethod(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeAdminJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "不支持的请求方法"})
+	return false
+}
+
+// writeAdminJSON 输出 JSON 响应
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// adminSupportBundle 下载诊断包
+func (db *DB) adminSupportBundle(w http.ResponseWriter, r *http.Request) {
+	if !allowAdminMethod(w, r, http.MethodGet) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="xlorm-support-bundle.json"`)
+	if err := db.SupportBundle(w); err != nil {
+		db.logger.Error("导出诊断包失败", "error", err)
+	}
+}
@@ -0,0 +1,212 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Sum 计算字段之和，没有匹配记录（结果为 NULL）时返回 0
+// 例如：db.M("orders").Where("status = ?", 1).Sum("amount")
+func (t *Table) Sum(field string) (float64, error) {
+	return t.SumWithContext(t.context(), field)
+}
+
+// SumWithContext 带上下文的Sum
+func (t *Table) SumWithContext(ctx context.Context, field string) (float64, error) {
+	value, err := t.aggregateField(ctx, "sum", "SUM", field)
+	if err != nil {
+		return 0, err
+	}
+	var sum sql.NullFloat64
+	if err := sum.Scan(value); err != nil {
+		return 0, fmt.Errorf("转换聚合结果失败: %v", err)
+	}
+	return sum.Float64, nil
+}
+
+// Avg 计算字段平均值，没有匹配记录（结果为 NULL）时 Valid 为 false
+func (t *Table) Avg(field string) (sql.NullFloat64, error) {
+	return t.AvgWithContext(t.context(), field)
+}
+
+// AvgWithContext 带上下文的Avg
+func (t *Table) AvgWithContext(ctx context.Context, field string) (sql.NullFloat64, error) {
+	var avg sql.NullFloat64
+	value, err := t.aggregateField(ctx, "avg", "AVG", field)
+	if err != nil {
+		return avg, err
+	}
+	if err := avg.Scan(value); err != nil {
+		return avg, fmt.Errorf("转换聚合结果失败: %v", err)
+	}
+	return avg, nil
+}
+
+// Max 获取字段最大值并赋给 dest（如 *int64、*float64、*string、*time.Time），
+// 没有匹配记录（结果为 NULL）时返回 false 且不修改 dest
+// 例如：var last time.Time; ok, err := db.M("orders").Max("created_at", &last)
+func (t *Table) Max(field string, dest interface{}) (bool, error) {
+	return t.MaxWithContext(t.context(), field, dest)
+}
+
+// MaxWithContext 带上下文的Max
+func (t *Table) MaxWithContext(ctx context.Context, field string, dest interface{}) (bool, error) {
+	return t.aggregateInto(ctx, "max", "MAX", field, dest)
+}
+
+// Min 获取字段最小值并赋给 dest，没有匹配记录（结果为 NULL）时返回 false 且不修改 dest
+func (t *Table) Min(field string, dest interface{}) (bool, error) {
+	return t.MinWithContext(t.context(), field, dest)
+}
+
+// MinWithContext 带上下文的Min
+func (t *Table) MinWithContext(ctx context.Context, field string, dest interface{}) (bool, error) {
+	return t.aggregateInto(ctx, "min", "MIN", field, dest)
+}
+
+// CountDistinct 统计字段去重后的记录数，多个字段时按字段组合去重，NULL 值不计入
+func (t *Table) CountDistinct(fields ...string) (int64, error) {
+	return t.CountDistinctWithContext(t.context(), fields...)
+}
+
+// CountDistinctWithContext 带上下文的CountDistinct
+func (t *Table) CountDistinctWithContext(ctx context.Context, fields ...string) (int64, error) {
+	if len(fields) == 0 {
+		t.Release()
+		return 0, errors.New("CountDistinct 至少需要一个字段")
+	}
+	quoted := make([]string, len(fields))
+	for i, field := range fields {
+		if !isValidFieldName(field) {
+			t.Release()
+			return 0, fmt.Errorf("无效的字段名: %s", field)
+		}
+		quoted[i] = quoteColumn(field)
+	}
+	value, err := t.aggregateQuery(ctx, "count_distinct", "COUNT(DISTINCT "+strings.Join(quoted, ", ")+")")
+	if err != nil {
+		return 0, err
+	}
+	var count sql.NullInt64
+	if err := count.Scan(value); err != nil {
+		return 0, fmt.Errorf("转换聚合结果失败: %v", err)
+	}
+	return count.Int64, nil
+}
+
+// aggregateInto 执行聚合查询并将结果赋给 dest
+func (t *Table) aggregateInto(ctx context.Context, operation, fn, field string, dest interface{}) (bool, error) {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		t.Release()
+		return false, fmt.Errorf("dest 必须为非空指针: %T", dest)
+	}
+	table := t.rawTableName()
+	value, err := t.aggregateField(ctx, operation, fn, field)
+	if err != nil || value == nil {
+		return false, err
+	}
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+	if err := assignValue(rv.Elem(), value); err != nil {
+		return false, &ScanError{Table: table, Column: fn + "(" + field + ")", Row: 1, GoType: rv.Elem().Type().String(), Value: value, Err: err}
+	}
+	return true, nil
+}
+
+// aggregateField 对单个字段执行聚合函数
+func (t *Table) aggregateField(ctx context.Context, operation, fn, field string) (interface{}, error) {
+	if !isValidFieldName(field) {
+		t.Release()
+		return nil, fmt.Errorf("无效的字段名: %s", field)
+	}
+	return t.aggregateQuery(ctx, operation, fn+"("+quoteColumn(field)+")")
+}
+
+// aggregateQuery 按当前查询条件执行聚合查询，返回驱动原始值
+func (t *Table) aggregateQuery(ctx context.Context, operation, expr string) (interface{}, error) {
+	t.aggregate = expr
+	return t.scalarQuery(ctx, operation, "AGGREGATE")
+}
+
+// scalarQuery 按当前查询条件执行只返回单个值的查询
+func (t *Table) scalarQuery(ctx context.Context, operation, queryType string) (_ interface{}, err error) {
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, operation, t.rawTableName(), &err)
+	if err = t.checkUse(operation); err != nil {
+		return
+	}
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	defer t.Release()
+	t.resolvePrefix(ctx)
+	startTime := time.Now()
+	query, args := t.buildQuery(queryType)
+	hc := t.newHookContext(ctx, nil)
+	hc.Query, hc.Args = query, args
+	if err := t.runHooks(BeforeQuery, hc); err != nil {
+		return nil, err
+	}
+	if t.dryRun(ctx, operation, query, args) {
+		return nil, nil
+	}
+	var value interface{}
+	t.db.logQuery(ctx, "执行SQL", operation, query, args)
+	if err := t.db.checkQueryCost(ctx, query, args); err != nil {
+		return nil, err
+	}
+	qi := t.newQueryInfo(ctx, operation, query, args)
+	t.db.beforeQuery(qi)
+	err = t.db.QueryRowContext(ctx, t.db.tagQuery(ctx, query), args...).Scan(&value)
+	qi.rows, qi.err = 1, err
+	t.db.afterQuery(qi)
+	if err != nil {
+		t.db.asyncDBMetrics.RecordError()
+		t.db.logger.Error("执行查询失败", operation, query, "args", t.db.redactArgs(query, args), "error", err)
+		return nil, fmt.Errorf("执行查询失败: %v", err)
+	}
+	t.db.asyncDBMetrics.RecordQueryDuration(operation, time.Since(startTime))
+	if err := t.runHooks(AfterQuery, hc); err != nil {
+		return value, err
+	}
+	return value, nil
+}
+
+// Exists 判断是否存在匹配当前条件的记录，使用 SELECT EXISTS(... LIMIT 1)，比 Count 更高效
+// 例如：exists, err := db.M("users").Where("email = ?", email).Exists()
+func (t *Table) Exists() (bool, error) {
+	return t.ExistsWithContext(t.context())
+}
+
+// ExistsWithContext 带上下文的Exists
+func (t *Table) ExistsWithContext(ctx context.Context) (bool, error) {
+	value, err := t.scalarQuery(ctx, "exists", "EXISTS")
+	if err != nil {
+		return false, err
+	}
+	var exists sql.NullBool
+	if err := exists.Scan(value); err != nil {
+		return false, fmt.Errorf("转换查询结果失败: %v", err)
+	}
+	return exists.Bool, nil
+}
+
+// DoesntExist 判断是否不存在匹配当前条件的记录
+func (t *Table) DoesntExist() (bool, error) {
+	return t.DoesntExistWithContext(t.context())
+}
+
+// DoesntExistWithContext 带上下文的DoesntExist
+func (t *Table) DoesntExistWithContext(ctx context.Context) (bool, error) {
+	exists, err := t.ExistsWithContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	return !exists, nil
+}
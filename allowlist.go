@@ -0,0 +1,139 @@
+package xlorm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldAllowlist 表允许使用的字段，用于校验来自用户输入的排序、分组、查询和过滤字段
+// 各类别为空时不限制该类别；字段名不区分大小写，需与调用时的写法一致（如 name 或 u.name）
+type FieldAllowlist struct {
+	Sortable   []string // OrderBy 允许的排序字段
+	Groupable  []string // GroupBy 允许的分组字段
+	Selectable []string // Fields 允许的查询字段
+	Filterable []string // WhereField、WhereIn、WhereNotIn 允许的过滤字段
+}
+
+// fieldAllowlist 编译后的字段白名单
+type fieldAllowlist struct {
+	sortable   map[string]bool
+	groupable  map[string]bool
+	selectable map[string]bool
+	filterable map[string]bool
+}
+
+// newAllowSet 字段白名单集合，列表为空时返回 nil 表示不限制
+func newAllowSet(fields []string) (map[string]bool, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	set := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if !isValidFieldName(field) {
+			return nil, fmt.Errorf("白名单字段非法: %s", field)
+		}
+		set[strings.ToLower(field)] = true
+	}
+	return set, nil
+}
+
+// SetFieldAllowlist 设置表的字段白名单，tableName 为不含表前缀的表名
+// 设置后 OrderBy、GroupBy、Fields 中不在白名单的字段会被拒绝（记录错误日志并忽略该调用），
+// WhereField、WhereIn、WhereNotIn 中不在白名单的字段会被替换为恒假条件，避免忽略过滤条件后返回超出预期的数据，
+// 例如：
+//
+//	db.SetFieldAllowlist("users", xlorm.FieldAllowlist{
+//		Sortable:   []string{"id", "created_at", "name"},
+//		Filterable: []string{"status", "city"},
+//	})
+//	db.M("users").OrderBy(req.Sort) // req.Sort 不在白名单时不会拼接到SQL中
+func (db *DB) SetFieldAllowlist(tableName string, list FieldAllowlist) error {
+	if tableName == "" {
+		return errors.New("表名不能为空")
+	}
+	var compiled fieldAllowlist
+	var err error
+	if compiled.sortable, err = newAllowSet(list.Sortable); err != nil {
+		return err
+	}
+	if compiled.groupable, err = newAllowSet(list.Groupable); err != nil {
+		return err
+	}
+	if compiled.selectable, err = newAllowSet(list.Selectable); err != nil {
+		return err
+	}
+	if compiled.filterable, err = newAllowSet(list.Filterable); err != nil {
+		return err
+	}
+	db.fieldAllowlists.Store(tableName, &compiled)
+	return nil
+}
+
+// RemoveFieldAllowlist 移除表的字段白名单
+func (db *DB) RemoveFieldAllowlist(tableName string) {
+	db.fieldAllowlists.Delete(tableName)
+}
+
+// fieldAllowlist 获取当前表的字段白名单，未设置时返回 nil
+func (t *Table) fieldAllowlist() *fieldAllowlist {
+	if t.db == nil || t.name == "" {
+		return nil
+	}
+	if v, ok := t.db.fieldAllowlists.Load(t.name); ok {
+		return v.(*fieldAllowlist)
+	}
+	return nil
+}
+
+// allowFields 校验字段是否都在白名单集合中，集合为 nil 时不限制，返回第一个不允许的字段
+func allowFields(set map[string]bool, fields ...string) (string, bool) {
+	if set == nil {
+		return "", true
+	}
+	for _, field := range fields {
+		if !set[strings.ToLower(strings.ReplaceAll(field, "`", ""))] {
+			return field, false
+		}
+	}
+	return "", true
+}
+
+// orderByFields 解析排序子句中的字段，例如 "name DESC, id" 返回 name、id
+func orderByFields(order string) []string {
+	var fields []string
+	for _, part := range strings.Split(order, ",") {
+		if parts := strings.Fields(part); len(parts) > 0 {
+			fields = append(fields, parts[0])
+		}
+	}
+	return fields
+}
+
+// WhereField 添加单个字段的比较条件，适用于字段名来自用户输入的过滤场景，字段名会被校验并引用，
+// op 支持 =、!=、<>、<、<=、>、>=、LIKE、NOT LIKE，例如：WhereField(req.Field, "=", req.Value)
+func (t *Table) WhereField(field, op string, value interface{}) *Table {
+	op = strings.ToUpper(strings.Join(strings.Fields(op), " "))
+	switch op {
+	case "=", "!=", "<>", "<", "<=", ">", ">=", "LIKE", "NOT LIKE":
+	default:
+		t.db.logger.Error("不支持的比较运算符", "field", field, "op", op)
+		return t
+	}
+	if !isValidFieldName(field) {
+		t.db.logger.Error("检测到可能的SQL注入尝试", "field", field)
+		return t
+	}
+	if list := t.fieldAllowlist(); list != nil {
+		if _, ok := allowFields(list.filterable, field); !ok {
+			t.db.logger.Error("过滤字段不在白名单中", "table", t.name, "field", field)
+			return t.rejectFilter()
+		}
+	}
+	return t.Where(quoteColumn(field)+" "+op+" ?", value)
+}
+
+// rejectFilter 过滤字段被拒绝时追加恒假条件，查询不返回任何记录
+func (t *Table) rejectFilter() *Table {
+	return t.Where("1 = 0")
+}
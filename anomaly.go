@@ -0,0 +1,184 @@
+package xlorm
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	anomalyFastAlpha       = 0.1         // 短期均值平滑系数（约等于最近20次查询）
+	anomalySlowAlpha       = 0.02        // 基线平滑系数
+	anomalyMinErrorRate    = 0.2         // 错误率告警的最小短期错误率，避免基线接近0时偶发错误误报
+	anomalyCooldown        = time.Minute // 同一SQL指纹的告警间隔
+	anomalyMaxFingerprints = 10000       // 最多跟踪的SQL指纹数量
+)
+
+// AnomalyKind 异常类型
+type AnomalyKind int
+
+const (
+	AnomalyLatency   AnomalyKind = iota // 延迟异常
+	AnomalyErrorRate                    // 错误率异常
+)
+
+// String 返回异常类型名称
+func (k AnomalyKind) String() string {
+	switch k {
+	case AnomalyLatency:
+		return "latency"
+	case AnomalyErrorRate:
+		return "error_rate"
+	default:
+		return "unknown"
+	}
+}
+
+// AnomalyAlert 查询异常告警
+type AnomalyAlert struct {
+	Kind              AnomalyKind   // 异常类型
+	Fingerprint       string        // SQL指纹
+	Table             string        // 表名
+	BaselineLatency   time.Duration // 基线延迟
+	CurrentLatency    time.Duration // 近期延迟
+	BaselineErrorRate float64       // 基线错误率
+	CurrentErrorRate  float64       // 近期错误率
+	Factor            float64       // 告警倍数
+	Samples           int64         // 样本数量
+	Time              time.Time     // 告警时间
+}
+
+// anomalyDetector 查询异常检测器，按SQL指纹维护延迟和错误率的滚动基线
+type anomalyDetector struct {
+	mu         sync.Mutex
+	factor     float64
+	minSamples int64
+	baselines  map[string]*queryBaseline
+}
+
+// queryBaseline 单个SQL指纹的滚动基线
+type queryBaseline struct {
+	samples     int64
+	fastLatency float64 // 近期延迟（纳秒）
+	slowLatency float64 // 基线延迟（纳秒）
+	fastErrRate float64 // 近期错误率
+	slowErrRate float64 // 基线错误率
+	lastAlert   time.Time
+}
+
+// newAnomalyDetector 创建查询异常检测器
+func newAnomalyDetector(factor float64, minSamples int) *anomalyDetector {
+	if minSamples <= 0 {
+		minSamples = 100
+	}
+	return &anomalyDetector{
+		factor:     factor,
+		minSamples: int64(minSamples),
+		baselines:  make(map[string]*queryBaseline),
+	}
+}
+
+// observe 记录一次查询并返回触发的告警
+func (d *anomalyDetector) observe(fingerprint, table string, duration time.Duration, err error) []AnomalyAlert {
+	if fingerprint == "" {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	b, ok := d.baselines[fingerprint]
+	if !ok {
+		if len(d.baselines) >= anomalyMaxFingerprints {
+			return nil
+		}
+		b = &queryBaseline{}
+		d.baselines[fingerprint] = b
+	}
+
+	errSample := 0.0
+	if err != nil {
+		errSample = 1
+	}
+	latency := float64(duration)
+
+	b.samples++
+	if b.samples == 1 {
+		b.fastLatency, b.slowLatency = latency, latency
+		b.fastErrRate, b.slowErrRate = errSample, errSample
+		return nil
+	}
+
+	// 失败的查询不计入延迟基线
+	if err == nil {
+		b.fastLatency += anomalyFastAlpha * (latency - b.fastLatency)
+	}
+	b.fastErrRate += anomalyFastAlpha * (errSample - b.fastErrRate)
+
+	var alerts []AnomalyAlert
+	now := time.Now()
+	if b.samples >= d.minSamples && now.Sub(b.lastAlert) >= anomalyCooldown {
+		alert := AnomalyAlert{
+			Fingerprint:       fingerprint,
+			Table:             table,
+			BaselineLatency:   time.Duration(b.slowLatency),
+			CurrentLatency:    time.Duration(b.fastLatency),
+			BaselineErrorRate: b.slowErrRate,
+			CurrentErrorRate:  b.fastErrRate,
+			Factor:            d.factor,
+			Samples:           b.samples,
+			Time:              now,
+		}
+		if b.slowLatency > 0 && b.fastLatency > d.factor*b.slowLatency {
+			alert.Kind = AnomalyLatency
+			alerts = append(alerts, alert)
+		}
+		if b.fastErrRate >= anomalyMinErrorRate && b.fastErrRate > d.factor*b.slowErrRate {
+			alert.Kind = AnomalyErrorRate
+			alerts = append(alerts, alert)
+		}
+		if len(alerts) > 0 {
+			b.lastAlert = now
+		}
+	}
+
+	// 更新基线
+	if err == nil {
+		b.slowLatency += anomalySlowAlpha * (latency - b.slowLatency)
+	}
+	b.slowErrRate += anomalySlowAlpha * (errSample - b.slowErrRate)
+	return alerts
+}
+
+// reset 清空所有基线
+func (d *anomalyDetector) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.baselines = make(map[string]*queryBaseline)
+}
+
+// ResetAnomalyBaselines 清空异常检测的所有基线，例如在版本发布或扩容后重新建立基线
+func (db *DB) ResetAnomalyBaselines() {
+	if db.anomalyDetector != nil {
+		db.anomalyDetector.reset()
+	}
+}
+
+// detectAnomaly 检测查询异常，触发告警时记录日志、指标并调用回调
+func (db *DB) detectAnomaly(qi *queryInfo, duration time.Duration) {
+	alerts := db.anomalyDetector.observe(qi.getFingerprint(), qi.table, duration, qi.err)
+	for _, alert := range alerts {
+		db.asyncDBMetrics.RecordAnomaly()
+		db.logger.Warn("查询异常",
+			"kind", alert.Kind.String(),
+			"fingerprint", alert.Fingerprint,
+			"table", alert.Table,
+			"baseline_latency", alert.BaselineLatency,
+			"current_latency", alert.CurrentLatency,
+			"baseline_error_rate", alert.BaselineErrorRate,
+			"current_error_rate", alert.CurrentErrorRate,
+			"factor", alert.Factor,
+		)
+		if db.onAnomaly != nil {
+			db.onAnomaly(alert)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAutoIncrementWarnRatio = 0.8 // 默认自增ID使用率告警阈值
+)
+
+// AutoIncrementUsage 自增ID使用情况
+type AutoIncrementUsage struct {
+	Table      string  // 表名
+	Column     string  // 自增字段
+	ColumnType string  // 字段类型
+	Current    uint64  // 当前自增值
+	Max        uint64  // 字段类型最大值
+	Ratio      float64 // 使用率
+}
+
+// CheckAutoIncrement 检查当前数据库所有表的自增ID使用情况，返回使用率不低于 ratio 的表
+// 注意：MySQL 8.0 默认缓存 information_schema 统计信息（information_schema_stats_expiry），自增值可能存在延迟
+func (db *DB) CheckAutoIncrement(ctx context.Context, ratio float64) ([]AutoIncrementUsage, error) {
+	if db == nil || db.DB == nil {
+		return nil, errors.New("数据库连接为空")
+	}
+	if ratio <= 0 || ratio > 1 {
+		ratio = defaultAutoIncrementWarnRatio
+	}
+
+	query := "SELECT t.TABLE_NAME, c.COLUMN_NAME, c.COLUMN_TYPE, t.AUTO_INCREMENT " +
+		"FROM information_schema.TABLES t " +
+		"JOIN information_schema.COLUMNS c ON c.TABLE_SCHEMA = t.TABLE_SCHEMA AND c.TABLE_NAME = t.TABLE_NAME " +
+		"WHERE t.TABLE_SCHEMA = DATABASE() AND t.AUTO_INCREMENT IS NOT NULL AND c.EXTRA LIKE '%auto_increment%'"
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("读取自增ID信息失败: %v", err)
+	}
+	defer rows.Close()
+
+	var usages []AutoIncrementUsage
+	for rows.Next() {
+		var usage AutoIncrementUsage
+		if err := rows.Scan(&usage.Table, &usage.Column, &usage.ColumnType, &usage.Current); err != nil {
+			return nil, fmt.Errorf("读取自增ID信息失败: %v", err)
+		}
+		usage.Max = autoIncrementMax(usage.ColumnType)
+		if usage.Max == 0 {
+			continue
+		}
+		usage.Ratio = float64(usage.Current) / float64(usage.Max)
+		if usage.Ratio >= ratio {
+			usages = append(usages, usage)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取自增ID信息失败: %v", err)
+	}
+	return usages, nil
+}
+
+// monitorAutoIncrement 定期检查自增ID使用情况
+func (db *DB) monitorAutoIncrement(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	db.logger.Debug("开启自增ID检查协程")
+	for {
+		select {
+		case <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			usages, err := db.CheckAutoIncrement(checkCtx, db.autoIncrementWarnRatio)
+			cancel()
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					db.logger.Error("自增ID检查失败", "error", err)
+				}
+				continue
+			}
+			for _, usage := range usages {
+				db.asyncDBMetrics.RecordAutoIncrementWarning()
+				db.logger.Warn("自增ID即将耗尽",
+					"table", usage.Table,
+					"column", usage.Column,
+					"column_type", usage.ColumnType,
+					"current", usage.Current,
+					"max", usage.Max,
+					"ratio", usage.Ratio,
+				)
+				if db.onAutoIncrementWarning != nil {
+					db.onAutoIncrementWarning(usage)
+				}
+			}
+		case <-ctx.Done():
+			db.logger.Debug("停止自增ID检查协程")
+			return nil
+		}
+	}
+}
+
+// autoIncrementMax 根据字段类型计算自增ID最大值，不支持的类型返回0
+func autoIncrementMax(columnType string) uint64 {
+	columnType = strings.ToLower(columnType)
+	unsigned := strings.Contains(columnType, "unsigned")
+	baseType := columnType
+	if i := strings.IndexAny(baseType, "( "); i >= 0 {
+		baseType = baseType[:i]
+	}
+
+	var bits uint
+	switch baseType {
+	case "tinyint":
+		bits = 8
+	case "smallint":
+		bits = 16
+	case "mediumint":
+		bits = 24
+	case "int", "integer":
+		bits = 32
+	case "bigint":
+		bits = 64
+	default:
+		return 0
+	}
+
+	if unsigned {
+		if bits == 64 {
+			return math.MaxUint64
+		}
+		return 1<<bits - 1
+	}
+	return 1<<(bits-1) - 1
+}
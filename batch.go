@@ -2,9 +2,12 @@ package xlorm
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -12,15 +15,66 @@ const (
 	defaultBatchSize = 1000
 )
 
+// execFunc 执行语句的函数，可以是 DB、事务的 ExecContext
+type execFunc func(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+
+// BatchUpdateMode 批量更新时记录字段不一致的处理方式
+type BatchUpdateMode int
+
+const (
+	BatchUpdateStrict        BatchUpdateMode = iota // 要求所有记录的更新字段相同，否则在执行前返回错误（默认）
+	BatchUpdateGroupByFields                        // 按更新字段集合分组，每组分别生成 CASE 语句
+	BatchUpdatePerRow                               // 字段不一致时在同一事务中逐条 UPDATE
+)
+
 // BatchInsert 批量插入数据，使用事务确保原子性和性能
 // data 批量插入的数据
 // batchSize 单词批量插入的数据量，默认：1000
 // totalAffecteds 返回影响的行数
 // err 返回错误信息
 func (t *Table) BatchInsert(data []map[string]interface{}, batchSize int) (totalAffecteds int64, err error) {
-	if batchSize == 0 {
-		batchSize = defaultBatchSize
+	return t.BatchInsertWithContext(t.context(), data, batchSize)
+}
+
+// BatchInsertWithContext 带上下文的BatchInsert，事务及每批插入在 ctx 取消或超时时中止并回滚
+func (t *Table) BatchInsertWithContext(ctx context.Context, data []map[string]interface{}, batchSize int) (totalAffecteds int64, err error) {
+	return t.batchInsert(ctx, data, BatchOptions{BatchSize: batchSize})
+}
+
+// batchInsert 按选项分批插入数据，Workers 选项由 BatchInsertWithOptions 处理
+func (t *Table) batchInsert(ctx context.Context, data []map[string]interface{}, opts BatchOptions) (totalAffecteds int64, err error) {
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, "batch_insert", t.rawTableName(), &err)
+	if err = t.checkUse("batch_insert"); err != nil {
+		return
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = t.defaultBatchSize()
 	}
+	data = t.stampRows(data)
+
+	// 注册了分表规则时按分片键分组写入各分表，各分表分别在独立的事务中写入
+	suffixes, groups, err := t.shardBatch(data)
+	if err != nil {
+		return 0, err
+	}
+	if len(suffixes) > 0 {
+		for _, suffix := range suffixes {
+			shardTable := t.cloneTable()
+			if err = shardTable.setShard(suffix); err == nil {
+				var affected int64
+				affected, err = shardTable.batchInsert(ctx, groups[suffix], opts)
+				totalAffecteds += affected
+			}
+			shardTable.Release()
+			if err != nil {
+				return totalAffecteds, fmt.Errorf("写入分表 %s 失败: %w", suffix, err)
+			}
+		}
+		return totalAffecteds, nil
+	}
+
 	dataLen := len(data)
 	// 检查数据是否为空
 	if dataLen == 0 {
@@ -30,19 +84,30 @@ func (t *Table) BatchInsert(data []map[string]interface{}, batchSize int) (total
 	// 记录开始时间
 	startTime := time.Now()
 
-	// 开启单个事务
-	tx, err := t.db.Begin()
-	if err != nil {
-		return 0, fmt.Errorf("开启事务失败: %v", err)
-	}
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p) // 重新抛出panic
-		} else if err != nil {
-			tx.Rollback()
+	// 默认开启单个事务，试运行时不开启；使用调用方事务时由调用方提交或回滚
+	var tx *Transaction
+	var exec execFunc
+	switch {
+	case t.db.IsDryRun():
+	case opts.Tx != nil:
+		exec = opts.Tx.ExecContext
+	case opts.NoTransaction:
+		exec = t.db.ExecContext
+	default:
+		tx, err = t.db.BeginWithContext(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("开启事务失败: %v", err)
 		}
-	}()
+		exec = tx.ExecContext
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p) // 重新抛出panic
+			} else if err != nil {
+				tx.Rollback()
+			}
+		}()
+	}
 
 	// 预校验字段
 	firstBatchEnd := batchSize
@@ -55,10 +120,9 @@ func (t *Table) BatchInsert(data []map[string]interface{}, batchSize int) (total
 	}
 	checkFieldsLen := len(checkFields)
 
-	// 预计算参数总容量
+	// 预计算单批参数容量
 	fieldCount := len(checkFields)
-	totalArgs := dataLen * fieldCount
-	args := make([]interface{}, 0, totalArgs)
+	args := make([]interface{}, 0, min(batchSize, dataLen)*fieldCount)
 
 	// 预生成占位符
 	placeholder := getCachedPlaceholder(fieldCount, t.db.placeholderCache)
@@ -101,6 +165,7 @@ func (t *Table) BatchInsert(data []map[string]interface{}, batchSize int) (total
 		}
 
 		// 填充参数
+		args = args[:0]
 		for _, item := range batchData {
 			for _, field := range checkFields {
 				cleanField := strings.Trim(field, "`")
@@ -110,9 +175,14 @@ func (t *Table) BatchInsert(data []map[string]interface{}, batchSize int) (total
 
 		// 执行批次插入
 		query := baseQuery + strings.Join(placeholders, ",")
-		result, err := tx.Exec(query, args...)
+		if t.dryRun(ctx, "batch_insert", query, args) {
+			continue
+		}
+		execCtx, cancel := t.withTimeout(ctx)
+		result, err := exec(execCtx, t.db.tagQuery(ctx, query), args...)
+		cancel()
 		if err != nil {
-			t.db.logger.Error("批量插入失败",
+			t.db.logger.ErrorContext(ctx, "批量插入失败",
 				"batchStart", i,
 				"batchEnd", end,
 				"error", err,
@@ -126,15 +196,22 @@ func (t *Table) BatchInsert(data []map[string]interface{}, batchSize int) (total
 		totalAffected += rowsAffected
 	}
 
+	if exec == nil {
+		return 0, nil
+	}
+
 	// 提交事务
-	if err := tx.Commit(); err != nil {
-		return totalAffected, fmt.Errorf("提交事务失败: %v", err)
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return totalAffected, fmt.Errorf("提交事务失败: %v", err)
+		}
 	}
 
 	// 记录性能指标
 	duration := time.Since(startTime)
 	t.db.asyncDBMetrics.RecordQueryDuration("batch_insert", duration)
 	t.db.asyncDBMetrics.RecordAffectedRows(totalAffected)
+	t.invalidateCache()
 
 	if t.db.IsDebug() {
 		t.db.logger.Debug("批量插入完成",
@@ -148,11 +225,106 @@ func (t *Table) BatchInsert(data []map[string]interface{}, batchSize int) (total
 
 }
 
+// BatchOptions 批量写入选项
+type BatchOptions struct {
+	BatchSize     int             // 每批写入的数据量（默认1000）
+	Workers       int             // 并发写入的协程数（默认1，所有批次在同一事务中写入），大于1时每批在独立的事务中写入，仅用于 BatchInsertWithOptions
+	NoTransaction bool            // 不开启事务，每批作为独立的自动提交语句执行，省去开启和提交事务的往返，失败时已写入的批次不会回滚
+	Tx            *Transaction    // 在调用方的事务中写入全部批次，由调用方提交或回滚，不能与 Workers 同时使用
+	UpdateMode    BatchUpdateMode // 批量更新时记录字段不一致的处理方式，仅用于 BatchUpdateWithOptions
+}
+
+// BatchInsertWithOptions 按选项批量插入数据，Workers 大于1时各批次由固定数量的协程并发写入，适用于大量数据导入
+// 并发写入时每批使用独立的事务（NoTransaction 时为自动提交语句），任一批次失败后取消未开始的批次并返回第一个错误，
+// 已提交的批次不会回滚，返回的影响行数为已提交批次的合计
+func (t *Table) BatchInsertWithOptions(ctx context.Context, data []map[string]interface{}, opts BatchOptions) (totalAffecteds int64, err error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = t.defaultBatchSize()
+	}
+	if opts.Workers > 1 && opts.Tx != nil {
+		return 0, errors.New("调用方事务不能用于并发批量写入")
+	}
+	if opts.Workers <= 1 || len(data) <= opts.BatchSize {
+		return t.batchInsert(ctx, data, opts)
+	}
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, "batch_insert", t.rawTableName(), &err)
+	if err = t.checkUse("batch_insert"); err != nil {
+		return
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunks := make(chan []map[string]interface{})
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunks {
+				chunkTable := t.cloneTable()
+				affected, err := chunkTable.batchInsert(ctx, chunk, BatchOptions{BatchSize: len(chunk), NoTransaction: opts.NoTransaction})
+				chunkTable.Release()
+				mu.Lock()
+				totalAffecteds += affected
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	dataLen := len(data)
+dispatch:
+	for i := 0; i < dataLen; i += opts.BatchSize {
+		select {
+		case chunks <- data[i:min(i+opts.BatchSize, dataLen)]:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(chunks)
+	wg.Wait()
+
+	if firstErr == nil {
+		// 调用方取消导致部分批次未分发
+		firstErr = parent.Err()
+	}
+	return totalAffecteds, firstErr
+}
+
 // BatchUpdate 批量更新数据
 // 返回更新的行数和错误
 func (t *Table) BatchUpdate(records []map[string]interface{}, keyField string, batchSize int) (totalAffecteds int64, err error) {
-	if batchSize == 0 {
-		batchSize = defaultBatchSize
+	return t.BatchUpdateWithContext(t.context(), records, keyField, batchSize)
+}
+
+// BatchUpdateWithContext 带上下文的BatchUpdate，事务及每批更新在 ctx 取消或超时时中止并回滚
+// 所有记录的字段必须相同，字段不同的记录使用 BatchUpdateWithOptions 指定 UpdateMode
+func (t *Table) BatchUpdateWithContext(ctx context.Context, records []map[string]interface{}, keyField string, batchSize int) (totalAffecteds int64, err error) {
+	return t.BatchUpdateWithOptions(ctx, records, keyField, BatchOptions{BatchSize: batchSize})
+}
+
+// BatchUpdateWithOptions 按选项批量更新数据，支持 BatchSize、UpdateMode、NoTransaction 和 Tx 选项，例如：
+//
+//	db.M("users").BatchUpdateWithOptions(ctx, records, "id", xlorm.BatchOptions{UpdateMode: xlorm.BatchUpdateGroupByFields})
+func (t *Table) BatchUpdateWithOptions(ctx context.Context, records []map[string]interface{}, keyField string, opts BatchOptions) (totalAffecteds int64, err error) {
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, "batch_update", t.rawTableName(), &err)
+	if err = t.checkUse("batch_update"); err != nil {
+		return
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = t.defaultBatchSize()
 	}
 	recordsLen := len(records)
 	if recordsLen == 0 {
@@ -162,51 +334,89 @@ func (t *Table) BatchUpdate(records []map[string]interface{}, keyField string, b
 		return 0, errors.New("必须指定主键字段")
 	}
 
+	// 执行前校验全部记录并按字段集合分组
+	groups, err := groupBatchUpdate(records, keyField)
+	if err != nil {
+		return 0, err
+	}
+	if len(groups) > 1 && opts.UpdateMode == BatchUpdateStrict {
+		first, other := groups[0], groups[1]
+		return 0, fmt.Errorf("第 %d 条记录的更新字段 [%s] 与第 %d 条记录的更新字段 [%s] 不一致，可通过 BatchOptions.UpdateMode 指定处理方式",
+			other.firstIndex+1, strings.Join(other.fields, ", "), first.firstIndex+1, strings.Join(first.fields, ", "))
+	}
+
 	startTime := time.Now()
 	if t.db.IsDebug() {
 		t.db.logger.Debug("开始批量更新",
 			"table", t.tableName,
 			"count", recordsLen,
+			"fieldSets", len(groups),
 		)
 	}
-	// 开启事务
-	tx, err := t.db.Begin()
-	if err != nil {
-		return 0, fmt.Errorf("开启事务失败: %v", err)
-	}
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p) // 重新抛出panic
-		} else if err != nil {
-			tx.Rollback()
+	// 默认开启事务，试运行时不开启；使用调用方事务时由调用方提交或回滚
+	var tx *Transaction
+	var exec execFunc
+	switch {
+	case t.db.IsDryRun():
+	case opts.Tx != nil:
+		exec = opts.Tx.ExecContext
+	case opts.NoTransaction:
+		exec = t.db.ExecContext
+	default:
+		tx, err = t.db.BeginWithContext(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("开启事务失败: %v", err)
 		}
-	}()
+		exec = tx.ExecContext
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p) // 重新抛出panic
+			} else if err != nil {
+				tx.Rollback()
+			}
+		}()
+	}
 
 	var totalAffected int64
-	for i := 0; i < recordsLen; i += batchSize {
-		end := i + batchSize
-		if end > recordsLen {
-			end = recordsLen
+	if len(groups) > 1 && opts.UpdateMode == BatchUpdatePerRow {
+		// 字段不一致时逐条更新
+		for _, record := range records {
+			affected, err := t.updateRow(ctx, exec, record, keyField)
+			if err != nil {
+				return totalAffected, err
+			}
+			totalAffected += affected
 		}
-
-		batch := records[i:end]
-		affected, err := t.updateBatch(tx, batch, keyField)
-		if err != nil {
-			return totalAffected, err
+	} else {
+		for _, group := range groups {
+			groupLen := len(group.records)
+			for i := 0; i < groupLen; i += batchSize {
+				batch := group.records[i:min(i+batchSize, groupLen)]
+				affected, err := t.updateBatch(ctx, exec, batch, keyField, group.fields)
+				if err != nil {
+					return totalAffected, err
+				}
+				totalAffected += affected
+			}
 		}
-		totalAffected += affected
+	}
+	if exec == nil {
+		return 0, nil
 	}
 
 	// 提交事务
-	if err := tx.Commit(); err != nil {
-		return totalAffected, fmt.Errorf("提交事务失败: %v", err)
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return totalAffected, fmt.Errorf("提交事务失败: %v", err)
+		}
 	}
 
 	duration := time.Since(startTime)
 	// 记录性能指标
 	t.db.asyncDBMetrics.RecordQueryDuration("batch_update", duration)
 	t.db.asyncDBMetrics.RecordAffectedRows(totalAffected)
+	t.invalidateCache()
 
 	if t.db.IsDebug() {
 		t.db.logger.Info("批量更新完成",
@@ -219,61 +429,82 @@ func (t *Table) BatchUpdate(records []map[string]interface{}, keyField string, b
 	return totalAffected, nil
 }
 
-// updateBatch 更新一批数据
-func (t *Table) updateBatch(tx *Transaction, records []map[string]interface{}, keyField string) (int64, error) {
-	if len(records) == 0 {
-		return 0, nil
-	}
+// batchUpdateGroup 更新字段相同的一组记录
+type batchUpdateGroup struct {
+	fields     []string                 // 更新字段（不含主键，按名称排序）
+	records    []map[string]interface{} // 组内记录，保持原有顺序
+	firstIndex int                      // 组内第一条记录在全部记录中的下标
+}
 
-	// 提取更新字段
-	var updateFields []string
-	for field := range records[0] {
-		if field != keyField {
-			updateFields = append(updateFields, field)
+// groupBatchUpdate 校验批量更新的记录并按更新字段集合分组，分组按首次出现的顺序排列
+func groupBatchUpdate(records []map[string]interface{}, keyField string) ([]*batchUpdateGroup, error) {
+	if !isValidFieldName(keyField) {
+		return nil, fmt.Errorf("无效的主键字段: %s", keyField)
+	}
+	var groups []*batchUpdateGroup
+	index := make(map[string]*batchUpdateGroup)
+	for i, record := range records {
+		if _, ok := record[keyField]; !ok {
+			return nil, fmt.Errorf("第 %d 条记录缺少主键字段: %s", i+1, keyField)
 		}
+		fields := make([]string, 0, len(record)-1)
+		for field := range record {
+			if field == keyField {
+				continue
+			}
+			if !isValidFieldName(field) {
+				return nil, fmt.Errorf("第 %d 条记录的字段名无效: %s", i+1, field)
+			}
+			fields = append(fields, field)
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("第 %d 条记录没有要更新的字段", i+1)
+		}
+		sort.Strings(fields)
+		key := strings.Join(fields, ",")
+		group, ok := index[key]
+		if !ok {
+			group = &batchUpdateGroup{fields: fields, firstIndex: i}
+			index[key] = group
+			groups = append(groups, group)
+		}
+		group.records = append(group.records, record)
 	}
-	if len(updateFields) == 0 {
-		return 0, errors.New("没有要更新的字段")
+	return groups, nil
+}
+
+// updateBatch 更新一批字段相同的数据，fields 为更新字段
+func (t *Table) updateBatch(ctx context.Context, exec execFunc, records []map[string]interface{}, keyField string, fields []string) (int64, error) {
+	if len(records) == 0 {
+		return 0, nil
 	}
 
 	// 构建CASE语句
 	var query strings.Builder
-	query.WriteString("UPDATE")
+	query.WriteString("UPDATE ")
 	query.WriteString(t.tableName)
 	query.WriteString(" SET ")
 
 	var args []interface{}
-	for i, field := range updateFields {
+	for i, field := range fields {
 		if i > 0 {
 			query.WriteString(", ")
 		}
-		query.WriteString("`")
-		query.WriteString(field)
-		query.WriteString("` = CASE `")
-		query.WriteString(keyField)
-		query.WriteString("`")
+		query.WriteString(quoteColumn(field))
+		query.WriteString(" = CASE ")
+		query.WriteString(quoteColumn(keyField))
 
 		for _, record := range records {
-			keyValue, ok := record[keyField]
-			if !ok {
-				return 0, fmt.Errorf("记录缺少主键字段: %s", keyField)
-			}
-
-			value, ok := record[field]
-			if !ok {
-				return 0, fmt.Errorf("记录缺少更新字段: %s", field)
-			}
-
 			query.WriteString(" WHEN ? THEN ? ")
-			args = append(args, keyValue, value)
+			args = append(args, record[keyField], record[field])
 		}
 		query.WriteString(" END")
 	}
 
 	// 添加WHERE条件
-	query.WriteString(" WHERE `")
-	query.WriteString(keyField)
-	query.WriteString("` IN (")
+	query.WriteString(" WHERE ")
+	query.WriteString(quoteColumn(keyField))
+	query.WriteString(" IN (")
 
 	for i, record := range records {
 		if i > 0 {
@@ -284,15 +515,56 @@ func (t *Table) updateBatch(tx *Transaction, records []map[string]interface{}, k
 	}
 	query.WriteString(")")
 
-	// 执行SQL
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
-	defer cancel()
+	return t.execBatchUpdate(ctx, exec, query.String(), args)
+}
 
-	if t.db.IsDebug() {
-		t.db.logger.Debug("执行SQL", "updateBatch", query.String(), "args", args)
+// updateRow 以单条 UPDATE 语句更新一条记录
+func (t *Table) updateRow(ctx context.Context, exec execFunc, record map[string]interface{}, keyField string) (int64, error) {
+	fields := make([]string, 0, len(record)-1)
+	for field := range record {
+		if field != keyField {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	var query strings.Builder
+	query.WriteString("UPDATE ")
+	query.WriteString(t.tableName)
+	query.WriteString(" SET ")
+	args := make([]interface{}, 0, len(fields)+1)
+	for i, field := range fields {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString(quoteColumn(field))
+		query.WriteString(" = ?")
+		args = append(args, record[field])
+	}
+	query.WriteString(" WHERE ")
+	query.WriteString(quoteColumn(keyField))
+	query.WriteString(" = ?")
+	args = append(args, record[keyField])
+
+	return t.execBatchUpdate(ctx, exec, query.String(), args)
+}
+
+// execBatchUpdate 执行批量更新语句，未设置查询超时时间时每条语句最多执行30秒
+func (t *Table) execBatchUpdate(ctx context.Context, exec execFunc, query string, args []interface{}) (int64, error) {
+	if t.dryRun(ctx, "batch_update", query, args) {
+		return 0, nil
 	}
 
-	result, err := tx.ExecContext(ctx, query.String(), args...)
+	timeout := t.queryTimeout()
+	if timeout <= 0 {
+		timeout = time.Second * 30
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	t.db.logQuery(ctx, "执行SQL", "updateBatch", query, args)
+
+	result, err := exec(ctx, t.db.tagQuery(ctx, query), args...)
 	if err != nil {
 		return 0, fmt.Errorf("执行SQL失败: %v", err)
 	}
@@ -310,7 +582,7 @@ func (t *Table) extractBatchFields(data []map[string]interface{}) ([]string, err
 	fields := make([]string, 0, len(data[0]))
 	for field := range data[0] {
 		// 转义字段名
-		escapedField := escapeSQLIdentifier(field)
+		escapedField := QuoteIdentifier(field)
 		fields = append(fields, escapedField)
 	}
 
@@ -9,18 +9,22 @@ import (
 
 // builder SQL查询构建器结构体
 type builder struct {
-	groupBy   string        // GROUP BY 子句
-	having    string        // HAVING 子句
-	orderBy   string        // ORDER BY 子句
-	table     string        // 表名
-	fields    []string      // 字段列表
-	where     []string      // WHERE 条件
-	joins     []string      // JOIN 子句
-	args      []interface{} // 查询参数
-	limit     int64         // 查询限制
-	offset    int64         // 查询偏移
-	forUpdate bool          // 是否为 FOR UPDATE 查询
-	errs      []error       // 错误列表
+	groupBy    string        // GROUP BY 子句
+	having     string        // HAVING 子句
+	orderBy    string        // ORDER BY 子句
+	table      string        // 表名
+	fields     []string      // 字段列表
+	where      []string      // WHERE 条件
+	whereOps   []string      // WHERE 条件之间的连接符（AND/OR），与 where 一一对应
+	joins      []string      // JOIN 子句
+	joinArgs   []interface{} // JOIN 子句参数
+	args       []interface{} // 查询参数
+	limit      int64         // 查询限制
+	offset     int64         // 查询偏移
+	forUpdate  bool          // 是否为 FOR UPDATE 查询
+	skipLocked bool          // FOR UPDATE 是否跳过已加锁的行
+	errs       []error       // 错误列表
+	db         *DB           // 所属数据库，用于检查服务端是否支持所需功能
 
 	// 新增位运算相关字段
 	conditionFlags uint64
@@ -31,6 +35,7 @@ type builder struct {
 func (db *DB) NewBuilder(table string) *builder {
 	b := builderPool.Get().(*builder)
 	b.Reset()
+	b.db = db
 	if table == "" {
 		b.errs = append(b.errs, errors.New("table名称不能为空"))
 		return b
@@ -49,15 +54,19 @@ func (b *builder) Reset() *builder {
 	b.table = ""
 	b.fields = nil
 	b.where = nil
+	b.whereOps = nil
 	b.args = nil
 	b.joins = nil
+	b.joinArgs = nil
 	b.groupBy = ""
 	b.having = ""
 	b.orderBy = ""
 	b.limit = 0
 	b.offset = 0
 	b.forUpdate = false
+	b.skipLocked = false
 	b.errs = nil
+	b.db = nil
 	b.conditionFlags = 0
 	b.conditionIndex = 0
 	return b
@@ -100,8 +109,7 @@ func (b *builder) Where(condition string, args ...interface{}) *builder {
 		return b
 	}
 
-	b.where = append(b.where, condition)
-	b.args = append(b.args, args...)
+	b.addCondition("AND", condition, args)
 
 	// 更新位标记和索引
 	if b.conditionIndex == 0 {
@@ -130,8 +138,7 @@ func (b *builder) OrWhere(condition string, args ...interface{}) *builder {
 		return b
 	}
 
-	b.where = append(b.where, fmt.Sprintf("OR %s", condition))
-	b.args = append(b.args, args...)
+	b.addCondition("OR", condition, args)
 
 	// 更新位标记和索引
 	b.conditionFlags |= condOR
@@ -159,8 +166,7 @@ func (b *builder) NotWhere(condition string, args ...interface{}) *builder {
 
 	// 为 NOT 条件添加 NOT 前缀
 	notCondition := "NOT (" + condition + ")"
-	b.where = append(b.where, notCondition)
-	b.args = append(b.args, args...)
+	b.addCondition("AND", notCondition, args)
 	// 更新位标记和索引
 	b.conditionFlags |= condNOT
 	b.conditionIndex++
@@ -168,12 +174,103 @@ func (b *builder) NotWhere(condition string, args ...interface{}) *builder {
 	return b
 }
 
+// WhereIn 添加 IN 查询条件，values 为切片，会自动展开为 (?,?,?) 占位符
+// 空切片生成恒假条件 1 = 0
+func (b *builder) WhereIn(field string, values interface{}) *builder {
+	condition, args, err := buildInCondition(field, values, false)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("WhereIn条件非法: %v", err))
+		return b
+	}
+	return b.Where(condition, args...)
+}
+
+// WhereNotIn 添加 NOT IN 查询条件，空切片生成恒真条件 1 = 1
+func (b *builder) WhereNotIn(field string, values interface{}) *builder {
+	condition, args, err := buildInCondition(field, values, true)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("WhereNotIn条件非法: %v", err))
+		return b
+	}
+	return b.Where(condition, args...)
+}
+
+// WhereGroup 添加以 AND 连接的条件组，组内条件会被括号包裹
+// 例如：Where("a = ?", 1).WhereGroup(func(g *builder) { g.Where("b = ?", 2).OrWhere("c = ?", 3) })
+// 生成：a = ? AND (b = ? OR c = ?)
+func (b *builder) WhereGroup(fn func(*builder)) *builder {
+	return b.whereGroup("AND", fn)
+}
+
+// OrWhereGroup 添加以 OR 连接的条件组，组内条件会被括号包裹
+// 例如：WhereGroup(func(g *builder) { g.Where("a = ?", 1).Where("b = ?", 2) }).
+// OrWhereGroup(func(g *builder) { g.Where("c = ?", 3).Where("d = ?", 4) })
+// 生成：(a = ? AND b = ?) OR (c = ? AND d = ?)
+func (b *builder) OrWhereGroup(fn func(*builder)) *builder {
+	return b.whereGroup("OR", fn)
+}
+
+// whereGroup 构建条件组
+func (b *builder) whereGroup(op string, fn func(*builder)) *builder {
+	if fn == nil {
+		return b
+	}
+	group := &builder{}
+	fn(group)
+	if len(group.errs) > 0 {
+		b.errs = append(b.errs, group.errs...)
+		return b
+	}
+	if len(group.where) == 0 {
+		return b
+	}
+
+	b.addCondition(op, "("+group.joinConditions()+")", group.args)
+
+	// 更新位标记和索引
+	if op == "OR" {
+		b.conditionFlags |= condOR
+	} else if b.conditionIndex == 0 {
+		b.conditionFlags |= condAND
+	}
+	b.conditionIndex++
+	return b
+}
+
+// addCondition 追加条件及其连接符和参数
+func (b *builder) addCondition(op, condition string, args []interface{}) {
+	b.where = append(b.where, condition)
+	b.whereOps = append(b.whereOps, op)
+	b.args = append(b.args, args...)
+}
+
+// joinConditions 按各条件的连接符拼接条件，AND 的优先级高于 OR
+func (b *builder) joinConditions() string {
+	var query strings.Builder
+	for i, condition := range b.where {
+		if i > 0 {
+			query.WriteByte(' ')
+			query.WriteString(b.whereOps[i])
+			query.WriteByte(' ')
+		}
+		query.WriteString(condition)
+	}
+	return query.String()
+}
+
 // Join 添加表连接
-func (b *builder) Join(join string) *builder {
+// args 为连接条件中 ? 占位符对应的参数，例如：Join("JOIN orders o ON o.user_id = u.id AND o.status = ?", 1)
+func (b *builder) Join(join string, args ...interface{}) *builder {
 	if join == "" {
 		return b
 	}
 
+	// 增强校验：检查是否有未参数化的值
+	if strings.Count(join, "?") != len(args) {
+		b.errs = append(b.errs, fmt.Errorf("Join参数数量不匹配: join:%s,args_count:%d", join, len(args)))
+		return b
+	}
+
 	// 检查SQL注入风险
 	if strings.ContainsAny(join, ";\x00") {
 		b.errs = append(b.errs, fmt.Errorf("Join检测到可能的SQL注入尝试: %s", join))
@@ -181,22 +278,86 @@ func (b *builder) Join(join string) *builder {
 	}
 
 	b.joins = append(b.joins, join)
+	b.joinArgs = append(b.joinArgs, args...)
 	return b
 }
 
+// InnerJoin 添加内连接，table 支持别名，例如：InnerJoin("orders o", "o.user_id = u.id AND o.status = ?", 1)
+func (b *builder) InnerJoin(table, on string, args ...interface{}) *builder {
+	return b.typedJoin("INNER JOIN", table, on, args)
+}
+
+// LeftJoin 添加左连接，table 支持别名，例如：LeftJoin("orders AS o", "o.user_id = u.id AND o.status = ?", 1)
+func (b *builder) LeftJoin(table, on string, args ...interface{}) *builder {
+	return b.typedJoin("LEFT JOIN", table, on, args)
+}
+
+// RightJoin 添加右连接，table 支持别名
+func (b *builder) RightJoin(table, on string, args ...interface{}) *builder {
+	return b.typedJoin("RIGHT JOIN", table, on, args)
+}
+
+// typedJoin 引用表名和别名后构建连接子句，参数按连接出现的顺序排在 WHERE 参数之前
+func (b *builder) typedJoin(kind, table, on string, args []interface{}) *builder {
+	quoted, err := quoteJoinTable(table)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	if strings.TrimSpace(on) == "" {
+		b.errs = append(b.errs, fmt.Errorf("%s 的连接条件不能为空: %s", kind, table))
+		return b
+	}
+	return b.Join(kind+" "+quoted+" ON "+on, args...)
+}
+
+// quoteJoinTable 引用连接表名，支持 "table"、"table alias"、"table AS alias" 及 schema.table 形式
+func quoteJoinTable(table string) (string, error) {
+	parts := strings.Fields(table)
+	if len(parts) == 3 && strings.EqualFold(parts[1], "AS") {
+		parts = []string{parts[0], parts[2]}
+	}
+	if len(parts) == 0 || len(parts) > 2 {
+		return "", fmt.Errorf("连接表名非法: %s", table)
+	}
+	name := strings.ReplaceAll(parts[0], "`", "")
+	if !isValidFieldName(name) {
+		return "", fmt.Errorf("连接表名非法: %s", table)
+	}
+	quoted := quoteColumn(name)
+	if len(parts) == 2 {
+		alias := strings.Trim(parts[1], "`")
+		if !isValidFieldName(alias) || strings.Contains(alias, ".") {
+			return "", fmt.Errorf("连接表别名非法: %s", table)
+		}
+		quoted += " " + quoteColumn(alias)
+	}
+	return quoted, nil
+}
+
 // GroupBy 添加分组条件
 func (b *builder) GroupBy(groupBy string) *builder {
 	if groupBy == "" {
 		return b
 	}
 
-	// 检查SQL注入风险
-	if strings.ContainsAny(groupBy, ";\x00") {
-		b.errs = append(b.errs, fmt.Errorf("GroupBy检测到可能的SQL注入尝试: %s", groupBy))
+	sanitized, err := sanitizeGroupBy(groupBy)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("GroupBy检测到可能的SQL注入尝试: %v", err))
 		return b
 	}
 
-	b.groupBy = groupBy
+	b.groupBy = sanitized
+	return b
+}
+
+// GroupByRaw 使用原始SQL片段分组，不做校验
+func (b *builder) GroupByRaw(raw RawSQL) *builder {
+	if raw.sql == "" {
+		b.errs = append(b.errs, errEmptyRaw)
+		return b
+	}
+	b.groupBy = raw.sql
 	return b
 }
 
@@ -206,9 +367,8 @@ func (b *builder) Having(having string) *builder {
 		return b
 	}
 
-	// 检查SQL注入风险
-	if strings.ContainsAny(having, ";\x00") {
-		b.errs = append(b.errs, fmt.Errorf("Having检测到可能的SQL注入尝试: %s", having))
+	if err := sanitizeHaving(having); err != nil {
+		b.errs = append(b.errs, fmt.Errorf("Having检测到可能的SQL注入尝试: %v", err))
 		return b
 	}
 
@@ -216,24 +376,39 @@ func (b *builder) Having(having string) *builder {
 	return b
 }
 
+// HavingRaw 使用原始SQL片段作为分组过滤条件，不做校验
+func (b *builder) HavingRaw(raw RawSQL) *builder {
+	if raw.sql == "" {
+		b.errs = append(b.errs, errEmptyRaw)
+		return b
+	}
+	b.having = raw.sql
+	return b
+}
+
 // OrderBy 添加排序条件
 func (b *builder) OrderBy(order string) *builder {
 	if order == "" {
 		return b
 	}
 
-	if !isValidSafeOrderBy(order) {
-		b.errs = append(b.errs, fmt.Errorf("OrderBy检测到不可用的排序字段: %s", order))
+	sanitized, err := sanitizeOrderBy(order)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("OrderBy检测到不可用的排序字段: %v", err))
 		return b
 	}
 
-	// 检查SQL注入风险
-	if strings.ContainsAny(order, ";\x00") {
-		b.errs = append(b.errs, fmt.Errorf("OrderBy检测到可能的SQL注入尝试: %s", order))
+	b.orderBy = sanitized
+	return b
+}
+
+// OrderByRaw 使用原始SQL片段排序，不做校验
+func (b *builder) OrderByRaw(raw RawSQL) *builder {
+	if raw.sql == "" {
+		b.errs = append(b.errs, errEmptyRaw)
 		return b
 	}
-
-	b.orderBy = order
+	b.orderBy = raw.sql
 	return b
 }
 
@@ -263,6 +438,13 @@ func (b *builder) ForUpdate(forUpdate bool) *builder {
 	return b
 }
 
+// SkipLocked 设置 FOR UPDATE 是否跳过已被其他事务加锁的行（FOR UPDATE SKIP LOCKED），常用于任务队列
+// 需要 MySQL 8.0.1 或 MariaDB 10.6 及以上版本，服务端不支持时 Build 返回 ErrUnsupportedFeature
+func (b *builder) SkipLocked(skipLocked bool) *builder {
+	b.skipLocked = skipLocked
+	return b
+}
+
 // Page 设置分页
 func (b *builder) Page(page, pageSize int64) *builder {
 	if page <= 0 || pageSize <= 0 {
@@ -306,6 +488,7 @@ func (b *builder) Build() (string, []interface{}, error) {
 			query.WriteString(whereString)
 		}
 	}
+	args := b.buildArgs(b.args)
 
 	// 添加分组
 	if b.groupBy != "" {
@@ -340,9 +523,163 @@ func (b *builder) Build() (string, []interface{}, error) {
 	// 添加行锁
 	if b.forUpdate {
 		query.WriteString(" FOR UPDATE")
+		if b.skipLocked {
+			query.WriteString(" SKIP LOCKED")
+		}
+	}
+	if b.skipLocked {
+		if !b.forUpdate {
+			b.errs = append(b.errs, errors.New("SKIP LOCKED 必须与 FOR UPDATE 一起使用"))
+		} else if b.db != nil {
+			if err := b.db.RequireFeature(FeatureSkipLocked); err != nil {
+				b.errs = append(b.errs, err)
+			}
+		}
+	}
+
+	return query.String(), args, errors.Join(b.errs...)
+}
+
+// BuildUpdate 构建UPDATE语句，支持多表关联更新
+// 例如：UPDATE a JOIN b ON a.id = b.a_id SET a.x = b.y WHERE ...
+// data 的值可以是普通值，也可以是 Expr 表达式（用于引用其他表的字段，如 xlorm.Expr("b.y")）
+// 参数顺序为：JOIN参数、SET参数、WHERE参数
+func (b *builder) BuildUpdate(data map[string]interface{}) (string, []interface{}, error) {
+	defer b.ReleaseBuilder()
+	if len(data) == 0 {
+		b.errs = append(b.errs, errors.New("更新操作必须指定字段"))
+		return "", nil, errors.Join(b.errs...)
+	}
+	if len(b.where) == 0 {
+		b.errs = append(b.errs, errors.New("更新操作必须指定 WHERE 条件"))
+		return "", nil, errors.Join(b.errs...)
+	}
+	if len(b.joins) > 0 && (b.orderBy != "" || b.limit > 0) {
+		b.errs = append(b.errs, errors.New("多表更新不支持 ORDER BY 和 LIMIT"))
+		return "", nil, errors.Join(b.errs...)
+	}
+
+	fields, values, _ := extractFromMap(data)
+	for _, field := range fields {
+		if !isValidFieldName(field) {
+			b.errs = append(b.errs, fmt.Errorf("更新字段包含非法字符: %s", field))
+		}
+	}
+	setClause, setArgs, err := buildSetClause(fields, values)
+	if err != nil {
+		b.errs = append(b.errs, err)
+	}
+	if len(b.errs) > 0 {
+		return "", nil, errors.Join(b.errs...)
+	}
+
+	var query strings.Builder
+	query.WriteString("UPDATE ")
+	query.WriteString(b.table)
+
+	// 添加连接
+	if len(b.joins) > 0 {
+		query.WriteByte(' ')
+		query.WriteString(strings.Join(b.joins, " "))
+	}
+
+	query.WriteString(" SET ")
+	query.WriteString(setClause)
+
+	whereString, whereArgs := b.GetWhere(true)
+	query.WriteString(whereString)
+
+	// 添加排序
+	if b.orderBy != "" {
+		query.WriteString(" ORDER BY ")
+		query.WriteString(b.orderBy)
+	}
+
+	// 添加限制
+	if b.limit > 0 {
+		query.WriteString(" LIMIT ")
+		query.WriteString(strconv.FormatInt(b.limit, 10))
 	}
 
-	return query.String(), b.args, errors.Join(b.errs...)
+	args := b.buildArgs(setArgs, whereArgs)
+	return query.String(), args, nil
+}
+
+// BuildDelete 构建DELETE语句，支持多表关联删除
+// targets 为要删除数据的表或别名，存在 JOIN 时必须指定，例如：
+// NewBuilder("orders o").Join("LEFT JOIN users u ON u.id = o.user_id").Where("u.id IS NULL").BuildDelete("o")
+// 生成：DELETE `o` FROM orders o LEFT JOIN users u ON u.id = o.user_id WHERE u.id IS NULL
+func (b *builder) BuildDelete(targets ...string) (string, []interface{}, error) {
+	defer b.ReleaseBuilder()
+	if len(b.where) == 0 {
+		b.errs = append(b.errs, errors.New("删除操作必须指定 WHERE 条件"))
+		return "", nil, errors.Join(b.errs...)
+	}
+	if len(b.joins) > 0 && len(targets) == 0 {
+		b.errs = append(b.errs, errors.New("多表删除必须指定要删除的表"))
+		return "", nil, errors.Join(b.errs...)
+	}
+	if len(b.joins) > 0 && (b.orderBy != "" || b.limit > 0) {
+		b.errs = append(b.errs, errors.New("多表删除不支持 ORDER BY 和 LIMIT"))
+		return "", nil, errors.Join(b.errs...)
+	}
+	quotedTargets := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if !isValidFieldName(target) {
+			b.errs = append(b.errs, fmt.Errorf("删除目标包含非法字符: %s", target))
+			continue
+		}
+		quotedTargets = append(quotedTargets, quoteColumn(target))
+	}
+	if len(b.errs) > 0 {
+		return "", nil, errors.Join(b.errs...)
+	}
+
+	var query strings.Builder
+	query.WriteString("DELETE ")
+	if len(quotedTargets) > 0 {
+		query.WriteString(strings.Join(quotedTargets, ", "))
+		query.WriteByte(' ')
+	}
+	query.WriteString("FROM ")
+	query.WriteString(b.table)
+
+	// 添加连接
+	if len(b.joins) > 0 {
+		query.WriteByte(' ')
+		query.WriteString(strings.Join(b.joins, " "))
+	}
+
+	whereString, whereArgs := b.GetWhere(true)
+	query.WriteString(whereString)
+
+	// 添加排序
+	if b.orderBy != "" {
+		query.WriteString(" ORDER BY ")
+		query.WriteString(b.orderBy)
+	}
+
+	// 添加限制
+	if b.limit > 0 {
+		query.WriteString(" LIMIT ")
+		query.WriteString(strconv.FormatInt(b.limit, 10))
+	}
+
+	return query.String(), b.buildArgs(whereArgs), nil
+}
+
+// buildArgs 按 JOIN 参数在前的顺序合并参数
+func (b *builder) buildArgs(argGroups ...[]interface{}) []interface{} {
+	size := len(b.joinArgs)
+	for _, group := range argGroups {
+		size += len(group)
+	}
+	args := make([]interface{}, 0, size)
+	args = append(args, b.joinArgs...)
+	for _, group := range argGroups {
+		args = append(args, group...)
+	}
+	return args
 }
 
 // GetWhere 获取WHERE子句
@@ -359,26 +696,10 @@ func (b *builder) GetWhere(addPreStr bool) (string, []interface{}) {
 
 		// 使用位运算快速判断条件类型
 		switch {
-		case b.conditionFlags&condOR != 0:
-			// 存在 OR 条件，使用括号确保正确性
+		case b.conditionFlags&(condOR|condNOT) != 0:
+			// 存在 OR/NOT 条件，使用括号确保正确性
 			query.WriteByte('(')
-			for i, condition := range b.where {
-				if i > 0 {
-					query.WriteString(" OR ")
-				}
-				query.WriteString(condition)
-			}
-			query.WriteByte(')')
-
-		case b.conditionFlags&condNOT != 0:
-			// 存在 NOT 条件，使用括号确保正确性
-			query.WriteByte('(')
-			for i, condition := range b.where {
-				if i > 0 {
-					query.WriteString(" AND ")
-				}
-				query.WriteString(condition)
-			}
+			query.WriteString(b.joinConditions())
 			query.WriteByte(')')
 
 		default:
@@ -0,0 +1,239 @@
+package xlorm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// specForbiddenWords 加载查询定义时禁止出现在SQL片段中的关键字
+var specForbiddenWords = map[string]bool{
+	"SELECT":             true,
+	"UNION":              true,
+	"INSERT":             true,
+	"UPDATE":             true,
+	"DELETE":             true,
+	"DROP":               true,
+	"ALTER":              true,
+	"CREATE":             true,
+	"TRUNCATE":           true,
+	"GRANT":              true,
+	"INTO":               true,
+	"OUTFILE":            true,
+	"DUMPFILE":           true,
+	"LOAD_FILE":          true,
+	"SLEEP":              true,
+	"BENCHMARK":          true,
+	"GET_LOCK":           true,
+	"INFORMATION_SCHEMA": true,
+	"PERFORMANCE_SCHEMA": true,
+	"MYSQL":              true,
+	"SYS":                true,
+}
+
+// QuerySpec 可序列化的查询定义，用于保存筛选条件、定时报表等场景
+type QuerySpec struct {
+	Table      string           `json:"table"`
+	Fields     []string         `json:"fields,omitempty"`
+	Joins      []QuerySpecJoin  `json:"joins,omitempty"`
+	Where      []QuerySpecWhere `json:"where,omitempty"`
+	GroupBy    string           `json:"group_by,omitempty"`
+	Having     string           `json:"having,omitempty"`
+	OrderBy    string           `json:"order_by,omitempty"`
+	Limit      int64            `json:"limit,omitempty"`
+	Offset     int64            `json:"offset,omitempty"`
+	ForUpdate  bool             `json:"for_update,omitempty"`
+	SkipLocked bool             `json:"skip_locked,omitempty"`
+}
+
+// QuerySpecJoin 查询定义中的表连接
+type QuerySpecJoin struct {
+	Join string        `json:"join"`
+	Args []interface{} `json:"args,omitempty"`
+}
+
+// QuerySpecWhere 查询定义中的条件
+type QuerySpecWhere struct {
+	Op        string        `json:"op"` // 与前一个条件的连接符：AND 或 OR
+	Condition string        `json:"condition"`
+	Args      []interface{} `json:"args,omitempty"`
+}
+
+// Spec 导出查询构建器的查询定义
+func (b *builder) Spec() (QuerySpec, error) {
+	if len(b.errs) > 0 {
+		return QuerySpec{}, errors.Join(b.errs...)
+	}
+	spec := QuerySpec{
+		Table:      b.table,
+		Fields:     append([]string(nil), b.fields...),
+		GroupBy:    b.groupBy,
+		Having:     b.having,
+		OrderBy:    b.orderBy,
+		Limit:      b.limit,
+		Offset:     b.offset,
+		ForUpdate:  b.forUpdate,
+		SkipLocked: b.skipLocked,
+	}
+
+	// 按占位符数量拆分参数
+	joinArgs := b.joinArgs
+	for _, join := range b.joins {
+		n := strings.Count(join, "?")
+		spec.Joins = append(spec.Joins, QuerySpecJoin{Join: join, Args: append([]interface{}(nil), joinArgs[:n]...)})
+		joinArgs = joinArgs[n:]
+	}
+	args := b.args
+	for i, condition := range b.where {
+		n := strings.Count(condition, "?")
+		spec.Where = append(spec.Where, QuerySpecWhere{Op: b.whereOps[i], Condition: condition, Args: append([]interface{}(nil), args[:n]...)})
+		args = args[n:]
+	}
+	return spec, nil
+}
+
+// MarshalJSON 将查询构建器序列化为 JSON 格式的查询定义
+func (b *builder) MarshalJSON() ([]byte, error) {
+	spec, err := b.Spec()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON 从 JSON 格式的查询定义重建查询构建器
+// 加载时执行严格校验：SQL片段中不允许出现注释、字符串字面量、子查询及危险函数，参数只能为基本类型
+func (b *builder) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	var spec QuerySpec
+	if err := dec.Decode(&spec); err != nil {
+		return fmt.Errorf("解析查询定义失败: %v", err)
+	}
+	return b.LoadSpec(spec)
+}
+
+// LoadSpec 从查询定义重建查询构建器，校验规则同 UnmarshalJSON
+func (b *builder) LoadSpec(spec QuerySpec) error {
+	b.Reset()
+	if err := validateSpecTable(spec.Table); err != nil {
+		return err
+	}
+	b.table = spec.Table
+	b.Fields(spec.Fields...)
+
+	for _, join := range spec.Joins {
+		args, err := normalizeSpecArgs(join.Args)
+		if err != nil {
+			b.errs = append(b.errs, fmt.Errorf("Join参数非法: %v", err))
+			continue
+		}
+		if err := validateSpecFragment(join.Join); err != nil {
+			b.errs = append(b.errs, fmt.Errorf("Join非法: %v", err))
+			continue
+		}
+		b.Join(join.Join, args...)
+	}
+
+	for _, where := range spec.Where {
+		args, err := normalizeSpecArgs(where.Args)
+		if err != nil {
+			b.errs = append(b.errs, fmt.Errorf("where参数非法: %v", err))
+			continue
+		}
+		if err := validateSpecFragment(where.Condition); err != nil {
+			b.errs = append(b.errs, fmt.Errorf("where条件非法: %v", err))
+			continue
+		}
+		switch strings.ToUpper(where.Op) {
+		case "", "AND":
+			b.Where(where.Condition, args...)
+		case "OR":
+			b.OrWhere(where.Condition, args...)
+		default:
+			b.errs = append(b.errs, fmt.Errorf("不支持的条件连接符: %s", where.Op))
+		}
+	}
+
+	for _, fragment := range []string{spec.GroupBy, spec.Having} {
+		if err := validateSpecFragment(fragment); err != nil {
+			b.errs = append(b.errs, err)
+		}
+	}
+	b.GroupBy(spec.GroupBy).Having(spec.Having).OrderBy(spec.OrderBy)
+	if spec.Limit < 0 || spec.Offset < 0 {
+		b.errs = append(b.errs, fmt.Errorf("limit和offset不能为负数: limit=%d, offset=%d", spec.Limit, spec.Offset))
+	}
+	b.Limit(spec.Limit).Offset(spec.Offset).ForUpdate(spec.ForUpdate).SkipLocked(spec.SkipLocked)
+	return errors.Join(b.errs...)
+}
+
+// BuilderFromJSON 从 JSON 格式的查询定义创建查询构建器
+func (db *DB) BuilderFromJSON(data []byte) (*builder, error) {
+	b := builderPool.Get().(*builder)
+	if err := b.UnmarshalJSON(data); err != nil {
+		b.ReleaseBuilder()
+		return nil, err
+	}
+	b.db = db
+	return b, nil
+}
+
+// validateSpecTable 校验查询定义中的表名，允许 schema.table 和别名
+func validateSpecTable(table string) error {
+	if table == "" {
+		return errors.New("table名称不能为空")
+	}
+	for i := 0; i < len(table); i++ {
+		c := table[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '`' || c == ' ') {
+			return fmt.Errorf("table包含非法字符: %s", table)
+		}
+	}
+	return validateSpecFragment(table)
+}
+
+// validateSpecFragment 校验查询定义中的SQL片段
+// 值必须通过 ? 占位符传入，因此不允许字符串字面量；同时拒绝注释、语句分隔符以及子查询和危险函数
+func validateSpecFragment(fragment string) error {
+	if fragment == "" {
+		return nil
+	}
+	if strings.ContainsAny(fragment, ";\x00'\"\\#") || strings.Contains(fragment, "--") || strings.Contains(fragment, "/*") {
+		return fmt.Errorf("SQL片段包含非法字符: %s", fragment)
+	}
+	words := strings.FieldsFunc(fragment, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_')
+	})
+	for _, word := range words {
+		if specForbiddenWords[strings.ToUpper(word)] {
+			return fmt.Errorf("SQL片段包含不允许的关键字 %s: %s", word, fragment)
+		}
+	}
+	return nil
+}
+
+// normalizeSpecArgs 转换并校验查询定义中的参数，仅允许 null、布尔、数字和字符串
+func normalizeSpecArgs(args []interface{}) ([]interface{}, error) {
+	result := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case nil, bool, string, int, int64, float64:
+			result[i] = v
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				result[i] = n
+			} else if f, err := v.Float64(); err == nil {
+				result[i] = f
+			} else {
+				return nil, fmt.Errorf("第 %d 个参数不是合法数字: %s", i+1, v)
+			}
+		default:
+			return nil, fmt.Errorf("第 %d 个参数类型不支持: %T", i+1, arg)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,112 @@
+package xlorm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ResultMeta 结果集的 HTTP 缓存元数据，用于条件请求（304 Not Modified）
+type ResultMeta struct {
+	ETag         string    // 结果集内容的哈希，带双引号，可直接作为 ETag 响应头
+	LastModified time.Time // 结果集中更新时间字段的最大值，未指定字段或无有效值时为零值
+}
+
+// NewResultMeta 根据结果集生成缓存元数据，updatedField 为更新时间字段（如 updated_at），为空时不计算 LastModified
+// 字段值支持 time.Time 以及 "2006-01-02 15:04:05" 格式的字符串和 []byte
+func NewResultMeta(rows []map[string]interface{}, updatedField string) ResultMeta {
+	var meta ResultMeta
+	// encoding/json 和 fmt 均按键排序输出 map，相同的结果集得到相同的哈希
+	data, err := json.Marshal(rows)
+	if err != nil {
+		data = fmt.Appendf(nil, "%v", rows)
+	}
+	sum := sha256.Sum256(data)
+	meta.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+	if updatedField == "" {
+		return meta
+	}
+	for _, row := range rows {
+		if t, ok := resultMetaTime(row[updatedField]); ok && t.After(meta.LastModified) {
+			meta.LastModified = t
+		}
+	}
+	return meta
+}
+
+// FindAllWithMeta 查询记录并生成结果集的缓存元数据，updatedField 为更新时间字段，例如：
+//
+//	rows, meta, err := db.M("products").Where("category_id = ?", id).FindAllWithMeta(r.Context(), "updated_at")
+//	if meta.NotModified(r) {
+//		w.WriteHeader(http.StatusNotModified)
+//		return
+//	}
+//	meta.SetHeaders(w.Header())
+func (t *Table) FindAllWithMeta(ctx context.Context, updatedField string) ([]map[string]interface{}, ResultMeta, error) {
+	rows, err := t.findAllWithContext(ctx, "findAllWithMeta")
+	if err != nil {
+		return nil, ResultMeta{}, err
+	}
+	return rows, NewResultMeta(rows, updatedField), nil
+}
+
+// SetHeaders 写入 ETag 和 Last-Modified 响应头
+func (m ResultMeta) SetHeaders(h http.Header) {
+	if m.ETag != "" {
+		h.Set("ETag", m.ETag)
+	}
+	if !m.LastModified.IsZero() {
+		h.Set("Last-Modified", m.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// NotModified 根据请求的 If-None-Match 和 If-Modified-Since 判断客户端缓存是否仍然有效
+// 请求带 If-None-Match 时忽略 If-Modified-Since
+func (m ResultMeta) NotModified(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || (m.ETag != "" && tag == m.ETag) {
+				return true
+			}
+		}
+		return false
+	}
+	if m.LastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// HTTP 日期精度为秒
+	return !m.LastModified.Truncate(time.Second).After(since)
+}
+
+// resultMetaTime 将更新时间字段的值转换为 time.Time
+func resultMetaTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, !v.IsZero()
+	case []byte:
+		return resultMetaTime(string(v))
+	case string:
+		t, err := time.ParseInLocation(time.DateTime, v, time.Local)
+		return t, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
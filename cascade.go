@@ -0,0 +1,270 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	maxCascadeDepth = 16 // 级联删除最大深度
+)
+
+// CascadeStep 级联删除中的一条删除语句
+type CascadeStep struct {
+	Table string        // 表名
+	Query string        // 删除语句
+	Args  []interface{} // 参数
+}
+
+// foreignKey 外键元数据
+type foreignKey struct {
+	constraint string   // 约束名称
+	table      string   // 引用方（子表）表名
+	columns    []string // 子表外键字段
+	refColumns []string // 被引用（父表）字段
+}
+
+// DeleteCascade 在事务中级联删除主键为 id 的记录
+// 通过 information_schema 读取外键元数据，按依赖顺序先删除子表数据，再删除当前表数据
+// 适用于未设置 ON DELETE CASCADE 的表结构，返回所有删除语句影响的总行数；试运行时只收集SQL不执行
+func (t *Table) DeleteCascade(id interface{}) (int64, error) {
+	defer t.Release()
+	ctx := t.context()
+	startTime := time.Now()
+	steps, err := t.planCascadeDelete(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if t.db.dryRunCaptureFor(ctx) != nil {
+		for _, step := range steps {
+			t.db.captureDryRun(ctx, "delete_cascade", step.Table, step.Query, step.Args)
+		}
+		return 0, nil
+	}
+
+	var totalAffected int64
+	err = t.db.ExecTx(func(tx *Transaction) error {
+		for _, step := range steps {
+			t.db.logQuery(ctx, "执行SQL", "deleteCascade", step.Query, step.Args)
+			result, err := tx.ExecContext(ctx, step.Query, step.Args...)
+			if err != nil {
+				t.db.asyncDBMetrics.RecordError()
+				t.db.logger.ErrorContext(ctx, "执行SQL失败", "deleteCascade", step.Query, "args", t.db.redactArgs(step.Query, step.Args), "error", err)
+				return fmt.Errorf("级联删除表 %s 失败: %v", step.Table, err)
+			}
+			rowsAffected, _ := result.RowsAffected()
+			totalAffected += rowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	t.db.asyncDBMetrics.RecordQueryDuration("delete_cascade", time.Since(startTime))
+	t.db.asyncDBMetrics.RecordAffectedRows(totalAffected)
+
+	tables := make([]string, len(steps))
+	for i, step := range steps {
+		tables[i] = step.Table
+	}
+	if err := t.db.invalidateTables(tables...); err != nil {
+		t.db.logger.Error("使查询缓存失效失败", "tables", tables, "error", err)
+	}
+	return totalAffected, nil
+}
+
+// DeleteCascadeDryRun 列出级联删除将按顺序执行的语句，不实际执行
+func (t *Table) DeleteCascadeDryRun(id interface{}) ([]CascadeStep, error) {
+	defer t.Release()
+	return t.planCascadeDelete(t.context(), id)
+}
+
+// planCascadeDelete 生成级联删除计划
+func (t *Table) planCascadeDelete(ctx context.Context, id interface{}) ([]CascadeStep, error) {
+	if id == nil {
+		return nil, errors.New("级联删除必须指定主键值")
+	}
+	table := t.rawTableName()
+	if table == "" {
+		return nil, errors.New("表名不能为空")
+	}
+
+	pk, err := t.db.loadPrimaryKey(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	condition := quoteColumn(pk) + " = ?"
+	path := map[string]bool{table: true}
+	return t.db.planCascade(ctx, table, condition, []interface{}{id}, t.cascadeScopes, path, 0)
+}
+
+// cascadeScopes 获取级联删除中某表的全局和表级作用域条件，当前表还包含其内部作用域，
+// 确保多租户等作用域同样限制级联删除的范围
+func (t *Table) cascadeScopes(table string) ([]string, []interface{}) {
+	if table == t.rawTableName() {
+		scopes, args := t.globalScopeConditions()
+		return append(scopes, t.scopes...), append(args, t.scopeArgs...)
+	}
+	child := tablePool.Get().(*Table)
+	child.Reset()
+	child.db = t.db
+	child.ctx = t.ctx
+	schema, name := splitTableName(table)
+	child.name = strings.TrimPrefix(name, t.db.tablePre)
+	if schema != "" {
+		child.name = schema + "." + child.name
+	}
+	child.tableName = t.db.GetTableName(child.name)
+	child.noGlobalScopes = t.noGlobalScopes
+	defer child.Release()
+	return child.globalScopeConditions()
+}
+
+// planCascade 递归生成删除语句，子表语句排在父表之前；scope 返回各表的作用域条件，与删除条件以 AND 连接
+func (db *DB) planCascade(ctx context.Context, table, condition string, args []interface{}, scope func(table string) ([]string, []interface{}), path map[string]bool, depth int) ([]CascadeStep, error) {
+	if depth > maxCascadeDepth {
+		return nil, fmt.Errorf("级联删除层级超过上限 %d", maxCascadeDepth)
+	}
+	if scopes, scopeArgs := scope(table); len(scopes) > 0 {
+		condition = condition + " AND " + strings.Join(scopes, " AND ")
+		args = append(append([]interface{}(nil), args...), scopeArgs...)
+	}
+
+	refs, err := db.loadForeignKeys(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []CascadeStep
+	for _, ref := range refs {
+		if path[ref.table] {
+			return nil, fmt.Errorf("检测到循环外键引用: %s -> %s (%s)", ref.table, table, ref.constraint)
+		}
+
+		// 子表条件：外键字段 IN (父表中待删除记录的被引用字段)
+		var childCondition strings.Builder
+		if len(ref.columns) == 1 {
+			childCondition.WriteString(quoteColumn(ref.columns[0]))
+		} else {
+			childCondition.WriteByte('(')
+			childCondition.WriteString(quoteColumns(ref.columns))
+			childCondition.WriteByte(')')
+		}
+		childCondition.WriteString(" IN (SELECT ")
+		childCondition.WriteString(quoteColumns(ref.refColumns))
+		childCondition.WriteString(" FROM ")
+		childCondition.WriteString(quoteColumn(table))
+		childCondition.WriteString(" WHERE ")
+		childCondition.WriteString(condition)
+		childCondition.WriteByte(')')
+
+		path[ref.table] = true
+		childSteps, err := db.planCascade(ctx, ref.table, childCondition.String(), args, scope, path, depth+1)
+		delete(path, ref.table)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, childSteps...)
+	}
+
+	steps = append(steps, CascadeStep{
+		Table: table,
+		Query: "DELETE FROM " + quoteColumn(table) + " WHERE " + condition,
+		Args:  args,
+	})
+	return steps, nil
+}
+
+// loadPrimaryKey 读取表的单列主键，table 支持 schema.table 形式，未指定 schema 时为当前库
+func (db *DB) loadPrimaryKey(ctx context.Context, table string) (string, error) {
+	schema, name := splitTableName(table)
+	query := "SELECT COLUMN_NAME FROM information_schema.KEY_COLUMN_USAGE " +
+		"WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ? AND CONSTRAINT_NAME = 'PRIMARY' " +
+		"ORDER BY ORDINAL_POSITION"
+	rows, err := db.QueryContext(ctx, query, schema, name)
+	if err != nil {
+		return "", fmt.Errorf("读取主键信息失败: %v", err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return "", fmt.Errorf("读取主键信息失败: %v", err)
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("读取主键信息失败: %v", err)
+	}
+
+	switch len(columns) {
+	case 0:
+		return "", fmt.Errorf("表 %s 没有主键", table)
+	case 1:
+		return columns[0], nil
+	default:
+		return "", fmt.Errorf("表 %s 为联合主键，不支持级联删除", table)
+	}
+}
+
+// loadForeignKeys 读取引用指定表的所有外键，table 支持 schema.table 形式；
+// 子表与被引用表同库时沿用被引用表的 schema 写法，否则子表名带上其所在的库名
+func (db *DB) loadForeignKeys(ctx context.Context, table string) ([]foreignKey, error) {
+	schema, name := splitTableName(table)
+	query := "SELECT CONSTRAINT_NAME, TABLE_SCHEMA, TABLE_SCHEMA = REFERENCED_TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME, REFERENCED_COLUMN_NAME " +
+		"FROM information_schema.KEY_COLUMN_USAGE " +
+		"WHERE REFERENCED_TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND REFERENCED_TABLE_NAME = ? " +
+		"ORDER BY TABLE_SCHEMA, TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION"
+	rows, err := db.QueryContext(ctx, query, schema, name)
+	if err != nil {
+		return nil, fmt.Errorf("读取外键信息失败: %v", err)
+	}
+	defer rows.Close()
+
+	var refs []foreignKey
+	for rows.Next() {
+		var constraint, childSchema, childTable, column, refColumn string
+		var sameSchema bool
+		if err := rows.Scan(&constraint, &childSchema, &sameSchema, &childTable, &column, &refColumn); err != nil {
+			return nil, fmt.Errorf("读取外键信息失败: %v", err)
+		}
+		switch {
+		case !sameSchema:
+			childTable = childSchema + "." + childTable
+		case schema != "":
+			childTable = schema + "." + childTable
+		}
+		// 同一约束的多个字段合并为一个外键
+		if n := len(refs); n > 0 && refs[n-1].constraint == constraint && refs[n-1].table == childTable {
+			refs[n-1].columns = append(refs[n-1].columns, column)
+			refs[n-1].refColumns = append(refs[n-1].refColumns, refColumn)
+			continue
+		}
+		refs = append(refs, foreignKey{
+			constraint: constraint,
+			table:      childTable,
+			columns:    []string{column},
+			refColumns: []string{refColumn},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取外键信息失败: %v", err)
+	}
+	return refs, nil
+}
+
+// quoteColumns 使用反引号包裹多个字段名并以逗号连接
+func quoteColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteColumn(column)
+	}
+	return strings.Join(quoted, ", ")
+}
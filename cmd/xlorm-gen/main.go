@@ -0,0 +1,78 @@
+// xlorm-gen 根据已有数据库的表结构生成模型代码
+//
+// 用法：
+//
+//	xlorm-gen -host 127.0.0.1 -user root -password root -database test_db -prefix test_ -out ./model
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jiankeluoluo/xlorm"
+)
+
+func main() {
+	host := flag.String("host", "127.0.0.1", "数据库主机")
+	port := flag.Int("port", 3306, "数据库端口")
+	user := flag.String("user", "root", "用户名")
+	password := flag.String("password", os.Getenv("XLORM_GEN_PASSWORD"), "密码（默认读取环境变量 XLORM_GEN_PASSWORD）")
+	database := flag.String("database", "", "数据库名称")
+	prefix := flag.String("prefix", "", "表前缀，生成的表名常量和 TableName 不含该前缀")
+	tables := flag.String("tables", "", "需要生成的表，多个以逗号分隔，为空时生成全部表")
+	pkg := flag.String("pkg", "", "包名（默认为输出目录名）")
+	out := flag.String("out", "./model", "输出目录")
+	flag.Parse()
+
+	if *pkg == "" {
+		*pkg = filepath.Base(filepath.Clean(*out))
+	}
+	var only []string
+	if *tables != "" {
+		for _, table := range strings.Split(*tables, ",") {
+			if table = strings.TrimSpace(table); table != "" {
+				only = append(only, table)
+			}
+		}
+	}
+
+	db, err := xlorm.New(&xlorm.Config{
+		Host:        *host,
+		Port:        *port,
+		Username:    *user,
+		Password:    *password,
+		Database:    *database,
+		TablePrefix: *prefix,
+		LogDir:      filepath.Join(os.TempDir(), "xlorm-gen"),
+		LogLevel:    "error",
+	})
+	if err != nil {
+		fail(err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	files, err := db.GenerateModels(ctx, xlorm.GenerateOptions{Package: *pkg, Tables: only})
+	if err != nil {
+		fail(err)
+	}
+	if err := xlorm.WriteModels(*out, files); err != nil {
+		fail(err)
+	}
+	for _, file := range files {
+		fmt.Println(filepath.Join(*out, file.Name))
+	}
+}
+
+// fail 输出错误并退出
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "xlorm-gen:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,310 @@
+package xlorm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GenerateOptions 模型代码生成选项
+type GenerateOptions struct {
+	Package string   // 包名（默认 model）
+	Tables  []string // 需要生成的表（含表前缀），为空时生成当前数据库的全部表
+}
+
+// GeneratedFile 生成的模型代码
+type GeneratedFile struct {
+	Table  string // 表名（含表前缀）
+	Name   string // 文件名，例如 user_profile.go
+	Source []byte // 已格式化的Go代码
+}
+
+// schemaColumn information_schema 中的字段信息
+type schemaColumn struct {
+	name       string
+	dataType   string
+	columnType string
+	nullable   bool
+	primary    bool
+	comment    string
+}
+
+// schemaTable information_schema 中的表信息
+type schemaTable struct {
+	name    string
+	comment string
+	columns []schemaColumn
+}
+
+// commonInitialisms Go 命名中保持全大写的缩写
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true,
+	"GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"LHS": true, "QPS": true, "RAM": true, "RHS": true, "RPC": true, "SLA": true, "SMTP": true,
+	"SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true,
+	"UID": true, "UUID": true, "URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true,
+}
+
+// GenerateModels 读取当前数据库的 information_schema，为每张表生成模型代码：
+// 带 db 标签的结构体（主键带 pk 标记）、表名常量、TableName 方法（实现 Tabler，已去除表前缀）及字段名常量
+// 可为空的字段生成为指针类型，DECIMAL 生成为 string 以保留精度
+func (db *DB) GenerateModels(ctx context.Context, opts GenerateOptions) ([]GeneratedFile, error) {
+	if db == nil || db.DB == nil {
+		return nil, errors.New("数据库连接为空")
+	}
+	if opts.Package == "" {
+		opts.Package = "model"
+	}
+	tables, err := db.loadSchemaTables(ctx, opts.Tables)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]GeneratedFile, 0, len(tables))
+	for _, table := range tables {
+		source, err := db.generateModel(opts.Package, table)
+		if err != nil {
+			return nil, fmt.Errorf("生成表 %s 的模型失败: %v", table.name, err)
+		}
+		files = append(files, GeneratedFile{
+			Table:  table.name,
+			Name:   strings.TrimPrefix(table.name, db.tablePre) + ".go",
+			Source: source,
+		})
+	}
+	return files, nil
+}
+
+// WriteModels 将生成的模型代码写入目录，目录不存在时自动创建
+func WriteModels(dir string, files []GeneratedFile) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(dir, file.Name), file.Source, 0644); err != nil {
+			return fmt.Errorf("写入文件 %s 失败: %v", file.Name, err)
+		}
+	}
+	return nil
+}
+
+// loadSchemaTables 读取表和字段信息
+func (db *DB) loadSchemaTables(ctx context.Context, only []string) ([]*schemaTable, error) {
+	rows, err := db.QueryContext(ctx,
+		"SELECT TABLE_NAME, TABLE_COMMENT FROM information_schema.TABLES "+
+			"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME")
+	if err != nil {
+		return nil, fmt.Errorf("读取表信息失败: %v", err)
+	}
+	wanted := make(map[string]bool, len(only))
+	for _, name := range only {
+		wanted[name] = true
+	}
+	var tables []*schemaTable
+	index := make(map[string]*schemaTable)
+	for rows.Next() {
+		table := &schemaTable{}
+		if err := rows.Scan(&table.name, &table.comment); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("读取表信息失败: %v", err)
+		}
+		if len(wanted) > 0 && !wanted[table.name] {
+			continue
+		}
+		tables = append(tables, table)
+		index[table.name] = table
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取表信息失败: %v", err)
+	}
+	for name := range wanted {
+		if index[name] == nil {
+			return nil, fmt.Errorf("表 %s 不存在", name)
+		}
+	}
+
+	rows, err = db.QueryContext(ctx,
+		"SELECT TABLE_NAME, COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY, COLUMN_COMMENT "+
+			"FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() ORDER BY TABLE_NAME, ORDINAL_POSITION")
+	if err != nil {
+		return nil, fmt.Errorf("读取字段信息失败: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tableName, nullable, key string
+		var column schemaColumn
+		if err := rows.Scan(&tableName, &column.name, &column.dataType, &column.columnType, &nullable, &key, &column.comment); err != nil {
+			return nil, fmt.Errorf("读取字段信息失败: %v", err)
+		}
+		table := index[tableName]
+		if table == nil {
+			continue
+		}
+		column.nullable = nullable == "YES"
+		column.primary = key == "PRI"
+		table.columns = append(table.columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取字段信息失败: %v", err)
+	}
+	return tables, nil
+}
+
+// generateModel 生成单张表的模型代码
+func (db *DB) generateModel(pkg string, table *schemaTable) ([]byte, error) {
+	bareName := strings.TrimPrefix(table.name, db.tablePre)
+	typeName := goIdentifier(bareName)
+	fieldNames := make([]string, len(table.columns))
+	used := make(map[string]bool)
+	imports := make(map[string]bool)
+	for i, column := range table.columns {
+		name := goIdentifier(column.name)
+		for used[name] {
+			name += "_"
+		}
+		used[name] = true
+		fieldNames[i] = name
+		if strings.Contains(goColumnType(column), "time.") {
+			imports["time"] = true
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by xlorm-gen. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for path := range imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		buf.WriteString("import (\n")
+		for _, path := range paths {
+			fmt.Fprintf(&buf, "%q\n", path)
+		}
+		buf.WriteString(")\n\n")
+	}
+
+	fmt.Fprintf(&buf, "// %sTable 表名（不含表前缀）\nconst %sTable = %q\n\n", typeName, typeName, bareName)
+
+	fmt.Fprintf(&buf, "// %s %s\n", typeName, commentOr(table.comment, "表 "+table.name))
+	fmt.Fprintf(&buf, "type %s struct {\n", typeName)
+	for i, column := range table.columns {
+		tag := column.name
+		if column.primary {
+			tag += ",pk"
+		}
+		fmt.Fprintf(&buf, "%s %s `db:%q`", fieldNames[i], goColumnType(column), tag)
+		if comment := singleLine(column.comment); comment != "" {
+			buf.WriteString(" // " + comment)
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(&buf, "// TableName 表名（不含表前缀）\nfunc (%s) TableName() string {\nreturn %sTable\n}\n\n", typeName, typeName)
+
+	fmt.Fprintf(&buf, "// %sColumns 字段名\nvar %sColumns = struct {\n", typeName, typeName)
+	for _, name := range fieldNames {
+		fmt.Fprintf(&buf, "%s string\n", name)
+	}
+	buf.WriteString("}{\n")
+	for i, column := range table.columns {
+		fmt.Fprintf(&buf, "%s: %q,\n", fieldNames[i], column.name)
+	}
+	buf.WriteString("}\n")
+
+	return format.Source(buf.Bytes())
+}
+
+// goColumnType 字段类型对应的Go类型
+func goColumnType(column schemaColumn) string {
+	unsigned := strings.Contains(column.columnType, "unsigned")
+	var typ string
+	switch column.dataType {
+	case "tinyint":
+		switch {
+		case strings.HasPrefix(column.columnType, "tinyint(1)"):
+			typ = "bool"
+		case unsigned:
+			typ = "uint8"
+		default:
+			typ = "int8"
+		}
+	case "smallint":
+		typ = "int16"
+		if unsigned {
+			typ = "uint16"
+		}
+	case "mediumint", "int", "integer":
+		typ = "int32"
+		if unsigned {
+			typ = "uint32"
+		}
+	case "bigint":
+		typ = "int64"
+		if unsigned {
+			typ = "uint64"
+		}
+	case "float":
+		typ = "float32"
+	case "double", "real":
+		typ = "float64"
+	case "bit":
+		typ = "uint64"
+	case "year":
+		typ = "int16"
+	case "date", "datetime", "timestamp":
+		typ = "time.Time"
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return "[]byte"
+	default:
+		// decimal、json、time、enum、set 及文本类型
+		typ = "string"
+	}
+	if column.nullable {
+		return "*" + typ
+	}
+	return typ
+}
+
+// goIdentifier 将蛇形命名转换为导出的Go标识符，例如 user_id 转换为 UserID
+func goIdentifier(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if upper := strings.ToUpper(part); commonInitialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	ident := sb.String()
+	if ident == "" || !unicode.IsLetter([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+	return ident
+}
+
+// commentOr 返回单行注释，为空时使用默认值
+func commentOr(comment, fallback string) string {
+	if comment = singleLine(comment); comment != "" {
+		return comment
+	}
+	return fallback
+}
+
+// singleLine 将注释合并为一行
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,121 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CollationMismatch 字符集或排序规则与连接不一致的字段
+type CollationMismatch struct {
+	Table     string // 表名
+	Column    string // 字段名
+	Charset   string // 字段字符集
+	Collation string // 字段排序规则
+}
+
+// CollationReport 字符集及排序规则检查结果
+type CollationReport struct {
+	ConfiguredCharset   string              // 配置的字符集
+	ConfiguredCollation string              // 配置的连接排序规则
+	ServerCharset       string              // 服务端默认字符集
+	ServerCollation     string              // 服务端默认排序规则
+	ConnectionCharset   string              // 连接字符集
+	ConnectionCollation string              // 连接排序规则
+	DatabaseCharset     string              // 数据库默认字符集
+	DatabaseCollation   string              // 数据库默认排序规则
+	Mismatches          []CollationMismatch // 与连接字符集或排序规则不一致的字段
+}
+
+// Tables 获取存在不一致字段的表名
+func (r *CollationReport) Tables() []string {
+	var tables []string
+	seen := make(map[string]bool)
+	for _, m := range r.Mismatches {
+		if !seen[m.Table] {
+			seen[m.Table] = true
+			tables = append(tables, m.Table)
+		}
+	}
+	return tables
+}
+
+// Warnings 将检查结果转换为告警列表
+func (r *CollationReport) Warnings() []SelfCheckWarning {
+	var warnings []SelfCheckWarning
+	if r.ConfiguredCharset != "" && !strings.EqualFold(r.ConnectionCharset, r.ConfiguredCharset) {
+		warnings = append(warnings, SelfCheckWarning{
+			Check:      "charset",
+			Message:    fmt.Sprintf("连接字符集 %s 与配置的 Charset %s 不一致", r.ConnectionCharset, r.ConfiguredCharset),
+			Suggestion: "确认服务端支持配置的字符集，或修改 Config.Charset",
+		})
+	}
+	if r.ConfiguredCollation != "" && !strings.EqualFold(r.ConnectionCollation, r.ConfiguredCollation) {
+		warnings = append(warnings, SelfCheckWarning{
+			Check:      "charset",
+			Message:    fmt.Sprintf("连接排序规则 %s 与配置的 Collation %s 不一致", r.ConnectionCollation, r.ConfiguredCollation),
+			Suggestion: "确认 Collation 属于 Charset 指定的字符集",
+		})
+	}
+	if !strings.EqualFold(r.DatabaseCharset, r.ConnectionCharset) {
+		warnings = append(warnings, SelfCheckWarning{
+			Check:      "charset",
+			Message:    fmt.Sprintf("数据库默认字符集 %s 与连接字符集 %s 不一致，新建表将使用 %s", r.DatabaseCharset, r.ConnectionCharset, r.DatabaseCharset),
+			Suggestion: fmt.Sprintf("执行 ALTER DATABASE CHARACTER SET %s，或修改 Config.Charset", r.ConnectionCharset),
+		})
+	}
+	if len(r.Mismatches) > 0 {
+		warnings = append(warnings, SelfCheckWarning{
+			Check: "charset",
+			Message: fmt.Sprintf("以下表存在字符集或排序规则与连接（%s/%s）不一致的字段，以这些字段为条件或关联时会发生隐式转换而无法使用索引: %s",
+				r.ConnectionCharset, r.ConnectionCollation, strings.Join(r.Tables(), ", ")),
+			Suggestion: fmt.Sprintf("执行 ALTER TABLE ... CONVERT TO CHARACTER SET %s COLLATE %s 统一字段，或通过 Config.Collation 使连接排序规则与表一致",
+				r.ConnectionCharset, r.ConnectionCollation),
+		})
+	}
+	return warnings
+}
+
+// CheckCollation 检查配置的字符集、连接、数据库及当前数据库中各字段的字符集和排序规则是否一致
+// 字段与连接的字符集或排序规则不一致时，以该字段为条件的查询会发生隐式转换导致索引失效
+func (db *DB) CheckCollation(ctx context.Context) (*CollationReport, error) {
+	if db == nil || db.DB == nil {
+		return nil, errors.New("数据库连接为空")
+	}
+	report := &CollationReport{
+		ConfiguredCharset:   db.config.Charset,
+		ConfiguredCollation: db.config.Collation,
+	}
+	err := db.QueryRowContext(ctx,
+		"SELECT @@character_set_server, @@collation_server, @@character_set_connection, @@collation_connection, "+
+			"DEFAULT_CHARACTER_SET_NAME, DEFAULT_COLLATION_NAME FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = DATABASE()",
+	).Scan(&report.ServerCharset, &report.ServerCollation, &report.ConnectionCharset, &report.ConnectionCollation,
+		&report.DatabaseCharset, &report.DatabaseCollation)
+	if err != nil {
+		return nil, fmt.Errorf("读取字符集信息失败: %v", err)
+	}
+
+	rows, err := db.QueryContext(ctx,
+		"SELECT c.TABLE_NAME, c.COLUMN_NAME, c.CHARACTER_SET_NAME, c.COLLATION_NAME FROM information_schema.COLUMNS c "+
+			"JOIN information_schema.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME "+
+			"WHERE c.TABLE_SCHEMA = DATABASE() AND t.TABLE_TYPE = 'BASE TABLE' AND c.CHARACTER_SET_NAME IS NOT NULL "+
+			"AND (c.CHARACTER_SET_NAME <> ? OR c.COLLATION_NAME <> ?) ORDER BY c.TABLE_NAME, c.ORDINAL_POSITION",
+		report.ConnectionCharset, report.ConnectionCollation,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("读取字段字符集信息失败: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m CollationMismatch
+		if err := rows.Scan(&m.Table, &m.Column, &m.Charset, &m.Collation); err != nil {
+			return nil, fmt.Errorf("读取字段字符集信息失败: %v", err)
+		}
+		report.Mismatches = append(report.Mismatches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取字段字符集信息失败: %v", err)
+	}
+	return report, nil
+}
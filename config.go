@@ -1,38 +1,115 @@
 package xlorm
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
 	"time"
+
+	"github.com/go-sql-driver/mysql"
 )
 
+// CredentialProvider 动态凭据提供函数，用于 RDS IAM 令牌、Vault 动态凭据等轮换场景，返回的用户名为空时使用 Config.Username
+// RDS IAM 认证还需开启 TLS 并设置 Params["allowCleartextPasswords"] = "true"
+type CredentialProvider func(ctx context.Context) (user, password string, err error)
+
+// LogAttrsExtractor 从上下文中提取日志属性，返回的属性附加到使用该上下文记录的日志上
+type LogAttrsExtractor func(ctx context.Context) []slog.Attr
+
 // Config 数据库配置结构体
 type Config struct {
-	DBName              string        //数据库别名称、用于区分不同数据库
-	Driver              string        // 数据库驱动
-	Host                string        // 主机地址
-	Username            string        // 用户名
-	Password            string        // 密码
-	Database            string        // 数据库名称
-	Charset             string        // 字符集
-	TablePrefix         string        // 表前缀
-	LogDir              string        // 日志目录
-	LogLevel            string        // 日志级别（支持：debug|info|warn|error）
-	ConnMaxLifetime     time.Duration // 连接最大生命周期
-	ConnMaxIdleTime     time.Duration // 连接最大空闲时间
-	ConnTimeout         time.Duration // 连接超时时间
-	ReadTimeout         time.Duration // 读取超时时间
-	WriteTimeout        time.Duration // 写入超时时间
-	SlowQueryTime       time.Duration // 慢查询阈值
-	PoolStatsInterval   time.Duration // 连接池统计频率
-	Port                int
-	LogBufferSize       int  // 日志缓冲区数量（默认5000）
-	MaxOpenConns        int  // 最大打开连接数（默认0）
-	MaxIdleConns        int  // 最大空闲连接数（默认0）
-	LogRotationMaxAge   int  // 日志保留天数，默认30天
-	DBMetricsBufferSize int  // 异步指标缓冲区数量（默认1000）
-	LogRotationEnabled  bool // 是否启用日志轮转
-	EnablePoolStats     bool // 是否启用性能指标（默认false）
-	Debug               bool // 是否开启调试模式（默认false）
+	DBName                     string                                             //数据库别名称、用于区分不同数据库
+	Driver                     string                                             // 数据库驱动
+	DSN                        string                                             // 预先构建的 DSN（如 user:pass@tcp(host:3306)/db?tls=custom），设置后忽略 Host、Port、Socket、Username、Password、Database、Charset，Collation、TLSConfig、Params 等其他连接配置追加到 DSN 上
+	Host                       string                                             // 主机地址
+	Socket                     string                                             // Unix socket 路径（如 /var/run/mysqld/mysqld.sock），设置后通过 socket 连接，忽略 Host、Port
+	Username                   string                                             // 用户名
+	Password                   string                                             // 密码
+	CredentialProvider         CredentialProvider                                 // 动态凭据，设置后每次建立新连接前调用以获取用户名和密码
+	Database                   string                                             // 数据库名称
+	Charset                    string                                             // 字符集
+	Collation                  string                                             // 连接排序规则（如 utf8mb4_general_ci），设置后强制连接使用该排序规则，需属于 Charset 指定的字符集
+	TLSConfig                  string                                             // TLS 配置名称：true、false、skip-verify、preferred 或通过 mysql.RegisterTLSConfig 注册的名称
+	TLS                        *tls.Config                                        // 自定义 TLS 配置，优先于 TLSConfig，未设置 ServerName 时使用连接地址的主机名
+	Params                     map[string]string                                  // 附加的 DSN 参数，驱动不识别的参数在连接建立时作为系统变量设置（如 time_zone: "'+08:00'"）
+	SQLMode                    string                                             // 连接使用的 sql_mode，设置后覆盖服务端默认值
+	SQLModeFlags               []string                                           // 每个连接在 sql_mode 基础上追加的标志（如 STRICT_TRANS_TABLES、ONLY_FULL_GROUP_BY），启动时校验是否生效
+	TablePrefix                string                                             // 表前缀
+	TableSchema                string                                             // 表默认所属数据库（schema），设置后表名为 schema.前缀表名
+	TablePrefixResolver        func(ctx context.Context) (prefix string, ok bool) // 根据上下文解析表前缀（如按租户分表），ok 为 false 时使用 TablePrefix
+	LogDir                     string                                             // 日志目录
+	LogLevel                   string                                             // 日志级别（支持：debug|info|warn|error）
+	ProfileDir                 string                                             // 慢查询快照目录（默认 LogDir/profiles）
+	ConnMaxLifetime            time.Duration                                      // 连接最大生命周期
+	ConnMaxIdleTime            time.Duration                                      // 连接最大空闲时间
+	ConnTimeout                time.Duration                                      // 连接超时时间
+	ReadTimeout                time.Duration                                      // 读取超时时间
+	WriteTimeout               time.Duration                                      // 写入超时时间
+	SlowQueryTime              time.Duration                                      // 慢查询阈值
+	DefaultQueryTimeout        time.Duration                                      // Table 操作和 Exec 每条语句的默认超时时间（默认0，不限制），可通过 Table.Timeout 单独设置
+	PoolStatsInterval          time.Duration                                      // 连接池统计频率
+	AutoIncrementCheckInterval time.Duration                                      // 自增ID耗尽检查频率（默认0，不检查）
+	ProfileMinInterval         time.Duration                                      // 两次慢查询快照的最小间隔（默认1分钟）
+	QueryKillerInterval        time.Duration                                      // 长查询检查频率（默认0，不开启）
+	QueryKillerTimeout         time.Duration                                      // 查询执行超过该时长将被终止（默认5分钟）
+	QueryKillerAllowlist       []string                                           // 包含这些关键字的查询不会被终止
+	CostCheckMode              string                                             // 执行前通过 EXPLAIN 检查查询成本：warn 记录告警，block 拒绝执行（默认不检查，建议仅在非生产环境开启）
+	CostCheckPatterns          []string                                           // 仅检查包含这些关键字（如表名）的查询，为空时检查所有只读查询
+	SchemaVersionTable         string                                             // 记录迁移版本的表（默认 schema_migrations，兼容 golang-migrate）
+	SchemaVersionColumn        string                                             // 迁移表中的版本字段，取最大值作为当前结构版本（默认 version）
+	SchemaVersionMode          string                                             // 结构版本不一致时的处理方式：block 拒绝启动，warn 记录告警（默认 block）
+	Port                       int
+	LogBufferSize              int                      // 日志缓冲区数量（默认5000）
+	LogOverflowPolicy          LogOverflowPolicy        // 日志缓冲区满时的处理策略：LogOverflowDrop 丢弃（默认）、LogOverflowBlock 阻塞等待、LogOverflowSpill 同步写入备用处理器
+	LogBlockTimeout            time.Duration            // LogOverflowBlock 策略的最长等待时间（默认100ms），超时后丢弃
+	LogSpillHandler            slog.Handler             // LogOverflowSpill 策略同步写入的备用处理器（如 slog.NewTextHandler(os.Stderr, nil)），为空时同步写入主日志处理器
+	OnLogDrop                  func(slog.Record)        // 日志因缓冲区满被丢弃时的回调，在记录日志的协程中同步调用，回调中不应再使用该日志实例
+	MaxOpenConns               int                      // 最大打开连接数（默认0）
+	MaxIdleConns               int                      // 最大空闲连接数（默认0）
+	LogRotationMaxAge          int                      // 日志保留天数，默认30天
+	DBMetricsBufferSize        int                      // 异步指标缓冲区数量（默认1000）
+	EventBufferSize            int                      // 查询事件缓冲区数量（默认1000）
+	SlowLogSize                int                      // 保留最近慢查询的条数（默认100，小于0时不保留）
+	PoolStatsHistorySize       int                      // 保留最近连接池统计的条数（默认60）
+	AnomalyMinSamples          int                      // 建立查询基线所需的最少样本数（默认100）
+	CostCheckMaxRows           int64                    // 预估扫描行数超过该值时视为成本过高（默认10000）
+	RequiredSchemaVersion      int64                    // 代码要求的数据库结构版本，启动时校验（默认0，不校验）
+	PoolWaitCountThreshold     int64                    // 开启 EnablePoolStats 后，采集间隔内等待连接的次数达到该值时告警（默认0，不检测）
+	LogRotationEnabled         bool                     // 是否启用日志轮转
+	EnablePoolStats            bool                     // 是否启用性能指标（默认false）
+	Debug                      bool                     // 是否开启调试模式（默认false）
+	DebugSignals               bool                     // 是否监听 SIGUSR1 切换调试模式、SIGUSR2 循环切换日志级别（默认false，Windows 不支持）
+	DebugControlFile           string                   // 控制文件路径，内容为日志级别（debug|info|warn|error），修改后自动生效，debug 同时开启调试模式
+	DryRun                     bool                     // 是否开启全局试运行模式，只生成SQL不执行（默认false）
+	RetryDriverErrors          bool                     // 遇到 commands out of sync、busy buffer、invalid connection 等驱动连接状态错误时是否丢弃该连接并重试一次：只读查询均重试，写入仅在语句未发送时重试（默认false）
+	ProfileSlowQueries         bool                     // 是否为查询设置 pprof 标签并在慢查询时采集 goroutine 快照（默认false）
+	ExplainSlowQueries         bool                     // 是否异步 EXPLAIN 只读慢查询并将执行计划写入日志（默认false）
+	MaxExecutionTimeHint       bool                     // 设置了查询超时时是否为 SELECT 添加 MAX_EXECUTION_TIME 提示，由服务端中止超时的查询（默认false）
+	InterpolateParams          bool                     // 是否在客户端插值SQL参数，减少预编译的网络往返（默认false）
+	StrictScan                 bool                     // 结果映射到结构体时是否启用严格模式：结果中有结构体不存在的列或结构体字段在结果中缺失时返回错误，用于在测试中发现结构体与表结构不一致（默认false，忽略多余的列，缺失的字段保持原值）
+	CheckTableMisuse           bool                     // 是否检测 Table 的误用：执行后（Release 后）继续复用、或在创建它以外的 goroutine 中使用时记录错误日志并返回 ErrTableMisuse，检测模式下 Table 不再放回对象池（默认false，建议仅在测试中开启）
+	AutoIncrementWarnRatio     float64                  // 自增ID使用率告警阈值（默认0.8）
+	AnomalyFactor              float64                  // 查询延迟或错误率超过基线该倍数时告警（默认0，不检测，需大于1）
+	PoolInUseRatioThreshold    float64                  // 开启 EnablePoolStats 后，使用中的连接数占 MaxOpenConns 的比例达到该值时告警（默认0，不检测，MaxOpenConns 为0时不检测）
+	OnAutoIncrementWarning     func(AutoIncrementUsage) // 自增ID即将耗尽时的回调
+	OnAnomaly                  func(AnomalyAlert)       // 查询异常回调，在查询路径中同步调用，应尽快返回
+	OnPoolEvent                func(PoolEvent)          // 连接池事件回调（新建、关闭、复用重置、达到最大生命周期），在连接池操作中同步调用，应尽快返回
+	OnPoolStatsAlert           func(PoolStatsAlert)     // 连接池统计越过 PoolWaitCountThreshold 或 PoolInUseRatioThreshold 时的回调，在连接池统计协程中调用
+	Logger                     *slog.Logger             // 外部日志实例，设置后不再创建日志文件，LogDir、LogLevel 等日志配置不生效，Close 时也不会关闭该日志
+	LogHandler                 slog.Handler             // 日志处理器（如 slog.NewJSONHandler(os.Stdout, nil)），设置后不再创建日志文件，日志经异步缓冲后交给该处理器，级别由处理器控制；Logger 优先
+	LogOutput                  io.Writer                // 日志输出（如 os.Stdout），设置后不再创建日志文件，以 JSON 格式异步写入，级别由 LogLevel 控制；Logger、LogHandler 优先
+	LogAttrsExtractor          LogAttrsExtractor        // 从上下文提取日志属性（如请求ID、用户ID），带上下文记录的日志（SQL日志、执行失败、慢查询等）自动附加这些属性
+	LogRedactColumns           []string                 // 日志中脱敏的列名（不区分大小写，如 password、id_card），SQL日志中与这些列比较或写入这些列的参数显示为 ******
+	LogSampling                map[string]int           // 按级别采样日志（如 {"debug": 10} 表示每10条调试日志只记录1条），缓解高并发下调试日志丢弃，错误级别始终记录；运行时可通过 SetLogSampling 调整
+	QueryCache                 Cache                    // 查询结果缓存，配合 Table.Cache 使用
+	NamedQueryFiles            []string                 // 启动时加载并校验的命名查询文件（glob 表达式）
+	WarmStatements             []string                 // 连接池每个新建的连接预先编译的关键语句，也可通过 DB.WarmStatements 追加
+	SelfCheckOnStart           bool                     // 启动时执行 SelfCheck 并将告警写入日志（默认false）
 }
 
 // Validate 验证配置
@@ -40,17 +117,75 @@ func (cfg *Config) Validate() error {
 	if cfg == nil {
 		return errors.New("配置不能为空")
 	}
-	if cfg.Host == "" {
-		return errors.New("数据库主机不能为空")
+	if cfg.DSN != "" {
+		if _, err := mysql.ParseDSN(cfg.DSN); err != nil {
+			return fmt.Errorf("无效DSN: %v", err)
+		}
+	} else {
+		if cfg.Socket == "" {
+			if cfg.Host == "" {
+				return errors.New("数据库主机不能为空")
+			}
+			if cfg.Port <= 0 || cfg.Port > 65535 {
+				return errors.New("无效端口号")
+			}
+		}
+		if cfg.Username == "" && cfg.CredentialProvider == nil {
+			return errors.New("数据库用户名不能为空")
+		}
+		if cfg.Database == "" {
+			return errors.New("数据库名称不能为空")
+		}
+	}
+	if err := validateDSNParams(cfg.Params); err != nil {
+		return err
+	}
+	return cfg.validateOptions()
+}
+
+// validateOptions 验证连接参数以外的配置
+func (cfg *Config) validateOptions() error {
+	if cfg.Collation != "" && (!isValidFieldName(cfg.Collation) || strings.Contains(cfg.Collation, ".")) {
+		return fmt.Errorf("无效排序规则: %s", cfg.Collation)
+	}
+	if err := validateSQLModes(parseSQLModes(cfg.SQLMode)); err != nil {
+		return err
+	}
+	if err := validateSQLModes(cfg.SQLModeFlags); err != nil {
+		return err
+	}
+	if cfg.DefaultQueryTimeout < 0 {
+		return errors.New("查询超时时间不能为负数")
+	}
+	if err := validateLogSampling(cfg.LogSampling); err != nil {
+		return err
+	}
+	switch cfg.LogOverflowPolicy {
+	case LogOverflowDrop, LogOverflowBlock, LogOverflowSpill:
+	default:
+		return fmt.Errorf("无效的日志溢出策略: %s", cfg.LogOverflowPolicy)
+	}
+	if cfg.LogBlockTimeout < 0 {
+		return errors.New("日志阻塞等待时间不能为负数")
+	}
+	switch cfg.CostCheckMode {
+	case "", "warn", "block":
+	default:
+		return fmt.Errorf("无效的查询成本检查模式: %s", cfg.CostCheckMode)
 	}
-	if cfg.Port <= 0 || cfg.Port > 65535 {
-		return errors.New("无效端口号")
+	if cfg.RequiredSchemaVersion < 0 {
+		return errors.New("结构版本不能为负数")
 	}
-	if cfg.Username == "" {
-		return errors.New("数据库用户名不能为空")
+	switch cfg.SchemaVersionMode {
+	case "", "block", "warn":
+	default:
+		return fmt.Errorf("无效的结构版本校验模式: %s", cfg.SchemaVersionMode)
 	}
-	if cfg.Database == "" {
-		return errors.New("数据库名称不能为空")
+	if cfg.SchemaVersionTable != "" && !isValidFieldName(cfg.SchemaVersionTable) {
+		return fmt.Errorf("无效的迁移表名: %s", cfg.SchemaVersionTable)
+	}
+	if cfg.SchemaVersionColumn != "" && (!isValidFieldName(cfg.SchemaVersionColumn) || strings.Contains(cfg.SchemaVersionColumn, ".")) {
+		return fmt.Errorf("无效的版本字段名: %s", cfg.SchemaVersionColumn)
 	}
 	if cfg.LogLevel == "" {
 		cfg.LogLevel = "debug"
@@ -60,3 +195,111 @@ func (cfg *Config) Validate() error {
 	}
 	return nil
 }
+
+// maskedSecret 脱敏后的敏感配置
+const maskedSecret = "******"
+
+// ConfigChange 两份配置之间的差异项
+type ConfigChange struct {
+	Field string      // 配置字段名
+	From  interface{} // 当前配置中的值
+	To    interface{} // 对比配置中的值
+}
+
+// String 返回差异项的描述
+func (c ConfigChange) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Field, c.From, c.To)
+}
+
+// EffectiveConfig 获取当前实际生效的配置（已补全默认值，并包含运行时调整的日志级别、调试模式等），密码已脱敏
+func (db *DB) EffectiveConfig() Config {
+	cfg := db.config
+	cfg.QueryKillerAllowlist = append([]string(nil), cfg.QueryKillerAllowlist...)
+	cfg.NamedQueryFiles = append([]string(nil), cfg.NamedQueryFiles...)
+	cfg.LogLevel = db.GetLogLevel()
+	cfg.LogSampling = nil
+	for level, n := range db.GetLogSampling() {
+		if n > 1 {
+			if cfg.LogSampling == nil {
+				cfg.LogSampling = make(map[string]int)
+			}
+			cfg.LogSampling[level] = n
+		}
+	}
+	cfg.Debug = db.IsDebug()
+	cfg.EnablePoolStats = db.poolStatsEnabled.Load()
+	db.queryCache.mu.RLock()
+	cfg.QueryCache = db.queryCache.cache
+	db.queryCache.mu.RUnlock()
+	if cfg.Password != "" {
+		cfg.Password = maskedSecret
+	}
+	cfg.DSN = maskDSN(cfg.DSN)
+	return cfg
+}
+
+// maskDSN 脱敏 DSN 中的密码，无法解析时整体脱敏
+func maskDSN(dsn string) string {
+	if dsn == "" {
+		return ""
+	}
+	mysqlCfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return maskedSecret
+	}
+	if mysqlCfg.Passwd != "" {
+		mysqlCfg.Passwd = maskedSecret
+	}
+	return mysqlCfg.FormatDSN()
+}
+
+// Diff 对比两份配置，返回值不同的字段；密码只显示是否变化，函数和接口类型字段只比较是否设置及类型
+// 例如对比生效配置与另一环境的配置：db.EffectiveConfig().Diff(&stagingCfg)
+func (cfg *Config) Diff(other *Config) []ConfigChange {
+	if cfg == nil || other == nil {
+		return nil
+	}
+	var changes []ConfigChange
+	a, b := reflect.ValueOf(cfg).Elem(), reflect.ValueOf(other).Elem()
+	typ := a.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		from, to := configValue(a.Field(i)), configValue(b.Field(i))
+		if field.Name == "DSN" {
+			// DSN 中的密码脱敏后对比，差异中不输出明文
+			from, to = maskDSN(from.(string)), maskDSN(to.(string))
+		}
+		if field.Name == "Password" {
+			// 脱敏后的密码无法与明文比较，变化时也不输出明文
+			if from == to || from == maskedSecret || to == maskedSecret {
+				continue
+			}
+			from, to = maskedSecret, maskedSecret
+		}
+		if field.Name == "Password" || !reflect.DeepEqual(from, to) {
+			changes = append(changes, ConfigChange{Field: field.Name, From: from, To: to})
+		}
+	}
+	return changes
+}
+
+// configValue 转换配置字段值用于对比和展示
+func configValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Func:
+		if v.IsNil() {
+			return "<nil>"
+		}
+		return "<func>"
+	case reflect.Interface:
+		if v.IsNil() {
+			return "<nil>"
+		}
+		return fmt.Sprintf("<%T>", v.Interface())
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return "[]"
+		}
+	}
+	return v.Interface()
+}
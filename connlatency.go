@@ -0,0 +1,98 @@
+package xlorm
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// connLatencyBounds 语句延迟分桶上界，超过最后一个上界的计入最后一个桶
+var connLatencyBounds = []time.Duration{
+	time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 500 * time.Millisecond, time.Second,
+}
+
+// connLatencyLabels 语句延迟分桶名称
+var connLatencyLabels = []string{"<=1ms", "<=5ms", "<=10ms", "<=50ms", "<=100ms", "<=500ms", "<=1s", ">1s"}
+
+// connAgeBounds、connAgeLabels 连接存活时长分组，用于调整 ConnMaxLifetime
+var (
+	connAgeBounds = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute, time.Hour}
+	connAgeLabels = []string{"<1m", "1m-5m", "5m-15m", "15m-1h", ">=1h"}
+)
+
+// connIdleBounds、connIdleLabels 连接执行语句前的空闲时长分组，用于调整 ConnMaxIdleTime
+var (
+	connIdleBounds = []time.Duration{time.Second, 10 * time.Second, time.Minute, 5 * time.Minute}
+	connIdleLabels = []string{"<1s", "1s-10s", "10s-1m", "1m-5m", ">=5m"}
+)
+
+// latencyHistogram 按分组统计的语句延迟直方图
+type latencyHistogram struct {
+	bounds []time.Duration // 分组上界（不含）
+	labels []string        // 分组名称
+	groups []latencyGroup
+}
+
+// latencyGroup 一个分组的延迟统计
+type latencyGroup struct {
+	count   atomic.Int64
+	total   atomic.Int64 // 总耗时（纳秒）
+	buckets [8]atomic.Int64
+}
+
+// newLatencyHistogram 创建延迟直方图
+func newLatencyHistogram(bounds []time.Duration, labels []string) *latencyHistogram {
+	return &latencyHistogram{bounds: bounds, labels: labels, groups: make([]latencyGroup, len(labels))}
+}
+
+// record 记录一次延迟，key 决定所属分组
+func (h *latencyHistogram) record(key, latency time.Duration) {
+	g := &h.groups[bucketIndex(h.bounds, key, false)]
+	g.count.Add(1)
+	g.total.Add(int64(latency))
+	g.buckets[bucketIndex(connLatencyBounds, latency, true)].Add(1)
+}
+
+// snapshot 导出各分组的统计，没有记录的分组不导出
+func (h *latencyHistogram) snapshot() map[string]interface{} {
+	result := make(map[string]interface{}, len(h.groups))
+	for i := range h.groups {
+		g := &h.groups[i]
+		count := g.count.Load()
+		if count == 0 {
+			continue
+		}
+		buckets := make(map[string]int64, len(connLatencyLabels))
+		for j, label := range connLatencyLabels {
+			buckets[label] = g.buckets[j].Load()
+		}
+		result[h.labels[i]] = map[string]interface{}{
+			"count":        count,
+			"average_time": time.Duration(g.total.Load() / count),
+			"buckets":      buckets,
+		}
+	}
+	return result
+}
+
+// reset 清空统计
+func (h *latencyHistogram) reset() {
+	for i := range h.groups {
+		g := &h.groups[i]
+		g.count.Store(0)
+		g.total.Store(0)
+		for j := range g.buckets {
+			g.buckets[j].Store(0)
+		}
+	}
+}
+
+// bucketIndex 获取值所在的分桶下标，inclusive 为 true 时上界计入本桶
+func bucketIndex(bounds []time.Duration, v time.Duration, inclusive bool) int {
+	for i, bound := range bounds {
+		if v < bound || (inclusive && v == bound) {
+			return i
+		}
+	}
+	return len(bounds)
+}
@@ -0,0 +1,125 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// consistencyPollInterval 等待从库追上一致性令牌时的轮询间隔
+const consistencyPollInterval = 50 * time.Millisecond
+
+// ConsistencyToken 读己之写的一致性令牌，记录写入完成时主库已执行的 GTID 集合，要求主从库开启 gtid_mode=ON
+// 从库按 GTID 集合判断是否已应用，不依赖事务在从库上的提交顺序，多线程并行复制时未开启
+// replica_preserve_commit_order 同样准确；令牌可通过 String 序列化后跨服务传递，再由 ParseConsistencyToken 还原
+type ConsistencyToken struct {
+	gtidSet string // 主库已执行的 GTID 集合
+}
+
+// IsZero 是否为空令牌，空令牌对任何库都视为已满足
+func (t ConsistencyToken) IsZero() bool {
+	return t.gtidSet == ""
+}
+
+// String 序列化令牌
+func (t ConsistencyToken) String() string {
+	if t.gtidSet == "" {
+		return ""
+	}
+	return "gtid:" + t.gtidSet
+}
+
+// ParseConsistencyToken 解析 String 序列化的令牌，空字符串解析为空令牌
+func ParseConsistencyToken(s string) (ConsistencyToken, error) {
+	if s == "" {
+		return ConsistencyToken{}, nil
+	}
+	kind, value, _ := strings.Cut(s, ":")
+	if kind != "gtid" || value == "" {
+		return ConsistencyToken{}, fmt.Errorf("一致性令牌格式错误: %s", s)
+	}
+	return ConsistencyToken{gtidSet: value}, nil
+}
+
+// ConsistencyToken 在主库写入后获取一致性令牌，之后的读取可通过 Fresh 路由到已应用该写入的从库
+// 主库未开启 GTID 时返回错误：按时间比较无法确认从库已应用该写入，空闲时也无法追上
+func (db *DB) ConsistencyToken(ctx context.Context) (ConsistencyToken, error) {
+	if db == nil || db.DB == nil {
+		return ConsistencyToken{}, errors.New("数据库连接为空")
+	}
+	if ctx == nil {
+		ctx = db.GetContext()
+	}
+	var gtidSet string
+	if err := db.DB.QueryRowContext(ctx, "SELECT @@GLOBAL.gtid_executed").Scan(&gtidSet); err != nil {
+		return ConsistencyToken{}, fmt.Errorf("获取一致性令牌失败: %v", err)
+	}
+	if gtidSet = strings.ReplaceAll(gtidSet, "\n", ""); gtidSet == "" {
+		return ConsistencyToken{}, errors.New("获取一致性令牌失败: 主库未开启 GTID（gtid_mode=ON）")
+	}
+	return ConsistencyToken{gtidSet: gtidSet}, nil
+}
+
+// Reached 判断当前库（通常为从库）是否已应用令牌对应的写入
+func (db *DB) Reached(ctx context.Context, token ConsistencyToken) (bool, error) {
+	if db == nil || db.DB == nil {
+		return false, errors.New("数据库连接为空")
+	}
+	if token.IsZero() {
+		return true, nil
+	}
+	if ctx == nil {
+		ctx = db.GetContext()
+	}
+	var reached bool
+	if err := db.DB.QueryRowContext(ctx, "SELECT GTID_SUBSET(?, @@GLOBAL.gtid_executed)", token.gtidSet).Scan(&reached); err != nil {
+		return false, fmt.Errorf("检查一致性令牌失败: %v", err)
+	}
+	return reached, nil
+}
+
+// WaitForToken 等待当前库应用令牌对应的写入，ctx 取消或超时时返回错误
+func (db *DB) WaitForToken(ctx context.Context, token ConsistencyToken) error {
+	if ctx == nil {
+		ctx = db.GetContext()
+	}
+	ticker := time.NewTicker(consistencyPollInterval)
+	defer ticker.Stop()
+	for {
+		reached, err := db.Reached(ctx, token)
+		if err != nil {
+			return err
+		}
+		if reached {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Fresh 获取满足令牌的读库：按顺序返回第一个已应用该写入的从库，都未追上（或检查失败）时返回主库 db，例如：
+//
+//	token, _ := primary.ConsistencyToken(ctx) // 写入后获取
+//	order, err := primary.Fresh(ctx, token, replica1, replica2).M("orders").Where("id = ?", id).Find()
+func (db *DB) Fresh(ctx context.Context, token ConsistencyToken, replicas ...*DB) *DB {
+	if token.IsZero() && len(replicas) > 0 {
+		return replicas[0]
+	}
+	for _, replica := range replicas {
+		reached, err := replica.Reached(ctx, token)
+		if err != nil {
+			replica.logger.Warn("检查一致性令牌失败", "token", token.String(), "error", err)
+			continue
+		}
+		if reached {
+			return replica
+		}
+	}
+	return db
+}
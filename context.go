@@ -0,0 +1,176 @@
+package xlorm
+
+import (
+	"context"
+	"log/slog"
+)
+
+// queryLoggingKey 单次请求SQL日志开关的上下文键
+type queryLoggingKey struct{}
+
+// WithQueryLogging 返回开启（或关闭）SQL日志的上下文
+// 开启后，使用该上下文执行的SQL即使在全局日志级别高于 debug、未开启调试模式时也会被记录，
+// 便于在生产环境中排查单个请求的问题；关闭后即使开启了调试模式也不记录该上下文执行的SQL
+func WithQueryLogging(ctx context.Context, enabled bool) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, queryLoggingKey{}, enabled)
+}
+
+// queryLogging 获取上下文的SQL日志开关，set 为 false 表示未设置
+func queryLogging(ctx context.Context) (enabled, set bool) {
+	if ctx == nil {
+		return false, false
+	}
+	enabled, set = ctx.Value(queryLoggingKey{}).(bool)
+	return enabled, set
+}
+
+// queryLoggingEnabled 判断上下文是否开启了SQL日志
+func queryLoggingEnabled(ctx context.Context) bool {
+	enabled, _ := queryLogging(ctx)
+	return enabled
+}
+
+// shouldLogSQL 判断是否记录SQL日志：上下文的开关优先，未设置时取决于调试模式
+func (db *DB) shouldLogSQL(ctx context.Context) bool {
+	if enabled, set := queryLogging(ctx); set {
+		return enabled
+	}
+	return db.IsDebug()
+}
+
+// logSQL 记录执行的SQL
+// 调试模式下以 debug 级别记录；上下文开启SQL日志时提升到当前日志级别，确保不被过滤
+func (db *DB) logSQL(ctx context.Context, msg string, args ...any) {
+	if !db.shouldLogSQL(ctx) {
+		return
+	}
+	forced := queryLoggingEnabled(ctx)
+	level := slog.LevelDebug
+	if forced && db.logLevelVar != nil && db.logLevelVar.Level() > level {
+		level = db.logLevelVar.Level()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	db.logger.Log(ctx, level, msg, args...)
+}
+
+// contextLogHandler 为带上下文的日志记录附加追踪ID及 Config.LogAttrsExtractor 提取的属性，并按级别采样
+type contextLogHandler struct {
+	slog.Handler
+	extractor LogAttrsExtractor
+	sampler   *logSampler
+}
+
+// newContextLogHandler 包装日志处理器
+func newContextLogHandler(h slog.Handler, extractor LogAttrsExtractor, sampler *logSampler) *contextLogHandler {
+	return &contextLogHandler{Handler: h, extractor: extractor, sampler: sampler}
+}
+
+// Handle 实现 slog.Handler
+func (h *contextLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !queryLoggingEnabled(ctx) && !h.sampler.keep(r.Level) {
+		return nil
+	}
+	if attrs := h.contextAttrs(ctx); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs 实现 slog.Handler
+func (h *contextLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return newContextLogHandler(h.Handler.WithAttrs(attrs), h.extractor, h.sampler)
+}
+
+// WithGroup 实现 slog.Handler
+func (h *contextLogHandler) WithGroup(name string) slog.Handler {
+	return newContextLogHandler(h.Handler.WithGroup(name), h.extractor, h.sampler)
+}
+
+// contextAttrs 提取上下文中的日志属性
+func (h *contextLogHandler) contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	var attrs []slog.Attr
+	if traceID := TraceIDFromContext(ctx); traceID != "" {
+		attrs = append(attrs, slog.String("trace_id", traceID))
+	}
+	if h.extractor != nil {
+		attrs = append(attrs, h.extractor(ctx)...)
+	}
+	return attrs
+}
+
+// unwrapLogHandler 获取被包装的日志处理器
+func unwrapLogHandler(h slog.Handler) slog.Handler {
+	if ch, ok := h.(*contextLogHandler); ok {
+		return ch.Handler
+	}
+	return h
+}
+
+// LoggerWith 返回附加了上下文中追踪ID及 Config.LogAttrsExtractor 提取属性的日志实例，
+// 便于应用日志与SQL日志使用相同的请求关联字段
+func (db *DB) LoggerWith(ctx context.Context) *slog.Logger {
+	h, ok := db.logger.Handler().(*contextLogHandler)
+	if !ok {
+		return db.logger
+	}
+	attrs := h.contextAttrs(ctx)
+	if len(attrs) == 0 {
+		return db.logger
+	}
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	// 属性已固定在返回的实例上，不再从上下文重复提取
+	return slog.New(h.Handler).With(args...)
+}
+
+// traceIDKey 追踪ID的上下文键
+type traceIDKey struct{}
+
+// WithTraceID 返回携带追踪ID的上下文，追踪ID会出现在查询事件、慢查询快照等观测数据中
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext 获取上下文中的追踪ID，未设置时返回空字符串
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	traceID, _ := ctx.Value(traceIDKey{}).(string)
+	return traceID
+}
+
+// tablePrefixKey 表前缀的上下文键
+type tablePrefixKey struct{}
+
+// WithTablePrefix 返回携带表前缀的上下文，使用该上下文执行的操作将使用该前缀（如按租户分表）
+// 优先级：Table.WithPrefix > 上下文 > Config.TablePrefixResolver > Config.TablePrefix
+func WithTablePrefix(ctx context.Context, prefix string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, tablePrefixKey{}, prefix)
+}
+
+// tablePrefixFromContext 获取上下文中的表前缀
+func tablePrefixFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	prefix, ok := ctx.Value(tablePrefixKey{}).(string)
+	return prefix, ok
+}
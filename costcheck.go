@@ -0,0 +1,117 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultCostCheckMaxRows  = 10000 // 默认预估扫描行数上限
+	costCheckFullScanMinRows = 1000  // 预估行数低于该值的全表扫描（小表）不视为缺少索引
+)
+
+// ErrQueryTooExpensive 查询成本超出限制，CostCheckMode 为 block 时返回
+var ErrQueryTooExpensive = errors.New("查询成本超出限制")
+
+// QueryCost EXPLAIN 估算的查询成本
+type QueryCost struct {
+	Rows      int64    // 各步骤预估扫描行数之和
+	FullScans []string // 未使用索引的全表扫描（预估行数不低于1000）
+	Plan      string   // EXPLAIN 输出，每行一条记录
+}
+
+// costGuard 执行前的查询成本检查
+type costGuard struct {
+	block    bool     // 超出限制时是否拒绝执行
+	maxRows  int64    // 预估扫描行数上限
+	patterns []string // 仅检查包含这些关键字的SQL
+}
+
+// newCostGuard 根据配置创建查询成本检查，未开启时返回 nil
+func newCostGuard(cfg *Config) *costGuard {
+	if cfg.CostCheckMode == "" {
+		return nil
+	}
+	g := &costGuard{
+		block:   cfg.CostCheckMode == "block",
+		maxRows: cfg.CostCheckMaxRows,
+	}
+	if g.maxRows <= 0 {
+		g.maxRows = defaultCostCheckMaxRows
+	}
+	for _, pattern := range cfg.CostCheckPatterns {
+		g.patterns = append(g.patterns, strings.ToLower(pattern))
+	}
+	return g
+}
+
+// matches 判断SQL是否需要检查
+func (g *costGuard) matches(query string) bool {
+	if !isReadOnlyQuery(query) {
+		return false
+	}
+	if len(g.patterns) == 0 {
+		return true
+	}
+	lower := strings.ToLower(query)
+	for _, pattern := range g.patterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExplainCost 使用 EXPLAIN 估算查询成本，不实际执行查询
+func (db *DB) ExplainCost(ctx context.Context, query string, args ...interface{}) (*QueryCost, error) {
+	result, err := db.Explain(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &QueryCost{
+		Rows:      result.EstimatedRows(),
+		FullScans: result.FullScans(costCheckFullScanMinRows),
+		Plan:      result.Plan,
+	}, nil
+}
+
+// checkQueryCost 执行前检查查询成本，超出限制时记录告警，block 模式下返回 ErrQueryTooExpensive
+func (db *DB) checkQueryCost(ctx context.Context, query string, args []interface{}) error {
+	g := db.costGuard
+	if g == nil || !g.matches(query) {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cost, err := db.ExplainCost(ctx, query, args...)
+	if err != nil {
+		// 成本检查失败不影响查询本身
+		db.logger.Warn("查询成本检查失败", "query", query, "error", err)
+		return nil
+	}
+
+	var reasons []string
+	if cost.Rows > g.maxRows {
+		reasons = append(reasons, fmt.Sprintf("预估扫描 %d 行，超过上限 %d", cost.Rows, g.maxRows))
+	}
+	if len(cost.FullScans) > 0 {
+		reasons = append(reasons, "全表扫描未使用索引: "+strings.Join(cost.FullScans, ", "))
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	reason := strings.Join(reasons, "；")
+	db.logger.Warn("查询成本过高",
+		"query", query,
+		"args", db.redactArgs(query, args),
+		"reason", reason,
+		"plan", cost.Plan,
+	)
+	if g.block {
+		return fmt.Errorf("%w: %s, query:%s", ErrQueryTooExpensive, reason, query)
+	}
+	return nil
+}
@@ -179,14 +179,11 @@ func (t *Table) GetUpdateSQL(data interface{}) (string, []interface{}, error) {
 	}
 
 	// 构建SQL语句
-	query, whereArgs, err := t.buildUpdateSQL(fields)
+	query, args, err := t.buildUpdateSQL(fields, values)
 	if err != nil {
 		return "", nil, err
 	}
 
-	// 合并参数
-	args := append(values, whereArgs...)
-
 	return query, args, nil
 }
 
@@ -0,0 +1,92 @@
+package xlorm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// debugControlInterval 控制文件检查间隔
+const debugControlInterval = 2 * time.Second
+
+// logLevelCycle SIGUSR2 循环切换的日志级别顺序
+var logLevelCycle = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// toggleDebug 切换调试模式，开启时日志级别调整为 debug，关闭时恢复开启前的级别
+func (db *DB) toggleDebug() {
+	db.ctxMu.Lock()
+	if db.debug.Load() {
+		db.debug.Store(false)
+		db.logLevelVar.Set(db.levelBeforeDebug)
+	} else {
+		db.levelBeforeDebug = db.logLevelVar.Level()
+		db.debug.Store(true)
+		db.logLevelVar.Set(slog.LevelDebug)
+	}
+	db.ctxMu.Unlock()
+	db.logger.Warn("调试模式已切换", "debug", db.IsDebug(), "level", db.GetLogLevel())
+}
+
+// cycleLogLevel 按 debug、info、warn、error 的顺序切换到下一个日志级别
+func (db *DB) cycleLogLevel() {
+	db.ctxMu.Lock()
+	current := db.logLevelVar.Level()
+	next := logLevelCycle[0]
+	for i, level := range logLevelCycle {
+		if level == current {
+			next = logLevelCycle[(i+1)%len(logLevelCycle)]
+			break
+		}
+	}
+	db.logLevelVar.Set(next)
+	db.ctxMu.Unlock()
+	// 使用 Warn 级别确保切换到 error 之前的提示可见
+	db.logger.Warn("日志级别已切换", "level", db.GetLogLevel())
+}
+
+// watchDebugControlFile 定期检查控制文件，内容变化时调整日志级别和调试模式
+func (db *DB) watchDebugControlFile(ctx context.Context, path string) error {
+	ticker := time.NewTicker(debugControlInterval)
+	defer ticker.Stop()
+	db.logger.Debug("开启调试控制文件监听协程", "path", path)
+
+	var last []byte
+	for {
+		content, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			content = bytes.TrimSpace(content)
+			if !bytes.Equal(content, last) {
+				last = content
+				db.applyDebugControl(string(content))
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			db.logger.Error("读取调试控制文件失败", "path", path, "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			db.logger.Debug("停止调试控制文件监听协程")
+			return nil
+		}
+	}
+}
+
+// applyDebugControl 应用控制文件的内容
+func (db *DB) applyDebugControl(content string) {
+	level := strings.ToLower(content)
+	if level == "" {
+		return
+	}
+	if err := db.SetLogLevel(level); err != nil {
+		db.logger.Error("调试控制文件内容无效", "content", content, "error", err)
+		return
+	}
+	db.SetDebug(level == "debug")
+	db.logger.Warn("日志级别已通过控制文件修改", "level", db.GetLogLevel(), "debug", db.IsDebug())
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package xlorm
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchDebugSignals 监听 SIGUSR1 切换调试模式、SIGUSR2 循环切换日志级别
+func (db *DB) watchDebugSignals(ctx context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(signals)
+	db.logger.Debug("开启调试信号监听协程")
+	for {
+		select {
+		case sig := <-signals:
+			if sig == syscall.SIGUSR1 {
+				db.toggleDebug()
+			} else {
+				db.cycleLogLevel()
+			}
+		case <-ctx.Done():
+			db.logger.Debug("停止调试信号监听协程")
+			return nil
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package xlorm
+
+import "context"
+
+// watchDebugSignals Windows 不支持 SIGUSR1、SIGUSR2，可使用 Config.DebugControlFile
+func (db *DB) watchDebugSignals(ctx context.Context) error {
+	db.logger.Warn("当前系统不支持通过信号切换调试模式，请使用 DebugControlFile")
+	return nil
+}
@@ -0,0 +1,78 @@
+package xlorm
+
+import (
+	"sort"
+)
+
+// RowDiff 两个结果集之间的差异
+type RowDiff struct {
+	Added   []map[string]interface{} // after 中新增的记录，按 after 中的顺序
+	Removed []map[string]interface{} // before 中被删除的记录，按 before 中的顺序
+	Changed []RowChange              // 键相同但字段值不同的记录，按 after 中的顺序
+}
+
+// RowChange 一条记录的字段变化
+type RowChange struct {
+	Key    map[string]interface{} // 键字段的值
+	Before map[string]interface{} // 变化前的记录
+	After  map[string]interface{} // 变化后的记录
+	Fields []string               // 值不同的字段（按名称排序），只在一侧存在的字段也视为变化
+}
+
+// Empty 两个结果集是否没有差异
+func (d *RowDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffRows 按 keyCols 比较两个结果集，返回新增、删除和变化的记录，可用于审计展示、判断缓存是否需要失效及测试断言
+// 值的比较方式与 Reconcile 相同：[]byte 与 string、time.Time 与其字符串形式视为相等
+// 记录缺少键字段或键重复时返回错误
+func DiffRows(before, after []map[string]interface{}, keyCols []string) (*RowDiff, error) {
+	beforeByKey, err := indexReconcileRows(before, keyCols)
+	if err != nil {
+		return nil, err
+	}
+	afterByKey, err := indexReconcileRows(after, keyCols)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RowDiff{}
+	for _, row := range before {
+		if _, ok := afterByKey[reconcileKey(row, keyCols)]; !ok {
+			diff.Removed = append(diff.Removed, row)
+		}
+	}
+	for _, row := range after {
+		old, ok := beforeByKey[reconcileKey(row, keyCols)]
+		if !ok {
+			diff.Added = append(diff.Added, row)
+			continue
+		}
+		if fields := changedFields(old, row); len(fields) > 0 {
+			key := make(map[string]interface{}, len(keyCols))
+			for _, col := range keyCols {
+				key[col] = row[col]
+			}
+			diff.Changed = append(diff.Changed, RowChange{Key: key, Before: old, After: row, Fields: fields})
+		}
+	}
+	return diff, nil
+}
+
+// changedFields 获取两条记录中值不同的字段，按名称排序
+func changedFields(before, after map[string]interface{}) []string {
+	var fields []string
+	for field, value := range after {
+		if old, ok := before[field]; !ok || !reconcileValueEqual(old, value) {
+			fields = append(fields, field)
+		}
+	}
+	for field := range before {
+		if _, ok := after[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,54 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// QueryContext 执行查询，开启 Config.RetryDriverErrors 时遇到驱动连接状态错误会丢弃该连接并在新连接上重试一次
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	if err != nil && db.shouldRetryDriverError(ctx, err, true) {
+		db.logger.Warn("驱动连接状态异常，丢弃连接后重试", "query", query, "error", err)
+		rows, err = db.DB.QueryContext(ctx, query, args...)
+	}
+	return rows, err
+}
+
+// ExecContext 执行语句，开启 Config.RetryDriverErrors 时遇到驱动连接状态错误会丢弃该连接并在新连接上重试一次
+// 写入语句仅在确定未发送到服务端时重试，避免重复写入
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := db.DB.ExecContext(ctx, query, args...)
+	if err != nil && db.shouldRetryDriverError(ctx, err, isReadOnlyQuery(query)) {
+		db.logger.Warn("驱动连接状态异常，丢弃连接后重试", "query", query, "error", err)
+		result, err = db.DB.ExecContext(ctx, query, args...)
+	}
+	return result, err
+}
+
+// shouldRetryDriverError 判断错误是否可以重试，readOnly 为 false 时只有语句未发送的错误可以重试
+// 协议错乱时驱动已关闭连接（IsValid 返回 false），database/sql 会丢弃该连接，重试使用连接池中的其他连接；
+// busy buffer 表示语句尚未发送，写入也可以安全重试
+func (db *DB) shouldRetryDriverError(ctx context.Context, err error, readOnly bool) bool {
+	if !db.config.RetryDriverErrors || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, mysql.ErrBusyBuffer) {
+		return true
+	}
+	return readOnly && isDriverEdgeError(err)
+}
+
+// isDriverEdgeError 是否为驱动连接状态错误（连接上的协议状态已错乱，该连接不可再用）
+func isDriverEdgeError(err error) bool {
+	if errors.Is(err, mysql.ErrPktSync) || errors.Is(err, mysql.ErrPktSyncMul) ||
+		errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, mysql.ErrBusyBuffer) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "commands out of sync") || strings.Contains(msg, "busy buffer")
+}
@@ -0,0 +1,116 @@
+package xlorm
+
+import (
+	"context"
+	"sync"
+)
+
+// dryRunKey 试运行的上下文键
+type dryRunKey struct{}
+
+// CapturedSQL 试运行生成的SQL
+type CapturedSQL struct {
+	Operation string        // 操作类型，例如 insert、update、delete、find
+	Table     string        // 表名
+	Query     string        // SQL语句
+	Args      []interface{} // SQL参数
+}
+
+// SQLCapture 收集试运行生成的SQL，并发安全
+type SQLCapture struct {
+	mu         sync.Mutex
+	statements []CapturedSQL
+}
+
+// Statements 获取已收集的SQL，按生成顺序排列
+func (c *SQLCapture) Statements() []CapturedSQL {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]CapturedSQL(nil), c.statements...)
+}
+
+// Reset 清空已收集的SQL
+func (c *SQLCapture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = nil
+}
+
+// add 收集一条SQL
+func (c *SQLCapture) add(stmt CapturedSQL) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = append(c.statements, stmt)
+}
+
+// WithDryRun 返回开启试运行的上下文，使用该上下文的 Insert/Update/Delete/Find/Count 等操作只生成SQL不执行，
+// 生成的SQL收集到返回的 SQLCapture，例如：
+//
+//	ctx, capture := xlorm.WithDryRun(ctx)
+//	db.M("users").Where("id = ?", 1).UpdateWithContext(ctx, map[string]interface{}{"name": "bob"})
+//	stmts := capture.Statements()
+//
+// 试运行时 Before 钩子正常执行，After 钩子不执行；写操作返回0，查询返回空结果（Find 返回 sql.ErrNoRows）
+func WithDryRun(ctx context.Context) (context.Context, *SQLCapture) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	capture := &SQLCapture{}
+	return context.WithValue(ctx, dryRunKey{}, capture), capture
+}
+
+// SetDryRun 开启或关闭全局试运行模式，开启后所有操作只生成SQL不执行，SQL 收集到 DryRunCapture
+// 用于单元测试或审查生成的SQL，收集的SQL不会自动清理，长时间开启时需定期调用 DryRunCapture().Reset()
+func (db *DB) SetDryRun(enable bool) *DB {
+	db.dryRun.Store(enable)
+	return db
+}
+
+// IsDryRun 判断是否开启了全局试运行模式
+func (db *DB) IsDryRun() bool {
+	return db.dryRun.Load()
+}
+
+// DryRunCapture 获取全局试运行模式下收集的SQL
+func (db *DB) DryRunCapture() *SQLCapture {
+	return db.dryRunCapture
+}
+
+// ToSQL 获取当前条件生成的查询SQL和参数，不执行查询，也不释放 Table
+func (t *Table) ToSQL() (string, []interface{}) {
+	return t.GetQuerySQL("SELECT")
+}
+
+// dryRun 处于试运行时收集SQL并返回 true，调用方应跳过执行
+func (t *Table) dryRun(ctx context.Context, operation, query string, args []interface{}) bool {
+	return t.db.captureDryRun(ctx, operation, t.tableName, query, args)
+}
+
+// dryRunCaptureFor 获取上下文或全局试运行模式的收集器，未处于试运行时返回 nil
+func (db *DB) dryRunCaptureFor(ctx context.Context) *SQLCapture {
+	if ctx != nil {
+		if capture, _ := ctx.Value(dryRunKey{}).(*SQLCapture); capture != nil {
+			return capture
+		}
+	}
+	if !db.IsDryRun() {
+		return nil
+	}
+	return db.dryRunCapture
+}
+
+// captureDryRun 处于试运行时收集SQL并返回 true，调用方应跳过执行
+func (db *DB) captureDryRun(ctx context.Context, operation, table, query string, args []interface{}) bool {
+	capture := db.dryRunCaptureFor(ctx)
+	if capture == nil {
+		return false
+	}
+	capture.add(CapturedSQL{
+		Operation: operation,
+		Table:     table,
+		Query:     query,
+		Args:      append([]interface{}(nil), args...),
+	})
+	db.logQuery(ctx, "试运行", operation, query, args)
+	return true
+}
@@ -0,0 +1,114 @@
+package xlorm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// buildMySQLConfig 根据配置构建驱动配置，设置了 Config.DSN 时在其基础上追加配置中的连接参数
+func buildMySQLConfig(cfg *Config, instanceID string) (*mysql.Config, error) {
+	dsn := cfg.DSN
+	if dsn == "" {
+		addr := fmt.Sprintf("tcp(%s:%d)", cfg.Host, cfg.Port)
+		if cfg.Socket != "" {
+			addr = "unix(" + cfg.Socket + ")"
+		}
+		dsn = fmt.Sprintf(
+			"%s:%s@%s/%s?charset=%s&parseTime=True&loc=Local&timeout=%s&readTimeout=%s&writeTimeout=%s",
+			cfg.Username,
+			cfg.Password,
+			addr,
+			cfg.Database,
+			cfg.Charset,
+			safeTimeout(cfg.ConnTimeout),  // 带最小值的超时
+			safeTimeout(cfg.ReadTimeout),  // 带最小值的读超时
+			safeTimeout(cfg.WriteTimeout), // 带最小值的写超时
+		)
+	} else if !strings.Contains(dsn[strings.LastIndexByte(dsn, '/')+1:], "?") {
+		dsn += "?"
+	}
+	dsn += dsnParams(cfg)
+
+	mysqlCfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	// 时间字段映射依赖 parseTime，DSN 中未设置的超时使用配置值
+	mysqlCfg.ParseTime = true
+	if mysqlCfg.Timeout == 0 {
+		mysqlCfg.Timeout = max(cfg.ConnTimeout, time.Second)
+	}
+	if mysqlCfg.ReadTimeout == 0 {
+		mysqlCfg.ReadTimeout = max(cfg.ReadTimeout, time.Second)
+	}
+	if mysqlCfg.WriteTimeout == 0 {
+		mysqlCfg.WriteTimeout = max(cfg.WriteTimeout, time.Second)
+	}
+	// 实例标识，通过连接属性识别本实例发起的连接
+	attr := instanceAttrName + ":" + instanceID
+	if mysqlCfg.ConnectionAttributes != "" {
+		attr = mysqlCfg.ConnectionAttributes + "," + attr
+	}
+	mysqlCfg.ConnectionAttributes = attr
+	if cfg.TLS != nil {
+		mysqlCfg.TLS = cfg.TLS
+	}
+	if provider := cfg.CredentialProvider; provider != nil {
+		// 驱动在每次建立连接前传入配置副本，修改只影响本次连接
+		err = mysqlCfg.Apply(mysql.BeforeConnect(func(ctx context.Context, c *mysql.Config) error {
+			user, password, err := provider(ctx)
+			if err != nil {
+				return fmt.Errorf("获取数据库凭据失败: %w", err)
+			}
+			if user != "" {
+				c.User = user
+			}
+			c.Passwd = password
+			return nil
+		}))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mysqlCfg, nil
+}
+
+// dsnParams 构建配置中的附加 DSN 参数，以 & 开头
+func dsnParams(cfg *Config) string {
+	var b strings.Builder
+	if cfg.Collation != "" {
+		b.WriteString("&collation=" + cfg.Collation)
+	}
+	b.WriteString(sqlModeDSNParam(cfg))
+	if cfg.TLSConfig != "" {
+		b.WriteString("&tls=" + url.QueryEscape(cfg.TLSConfig))
+	}
+	if cfg.InterpolateParams {
+		b.WriteString("&interpolateParams=true")
+	}
+	keys := make([]string, 0, len(cfg.Params))
+	for key := range cfg.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b.WriteString("&" + key + "=" + url.QueryEscape(cfg.Params[key]))
+	}
+	return b.String()
+}
+
+// validateDSNParams 校验附加的 DSN 参数
+func validateDSNParams(params map[string]string) error {
+	for key := range params {
+		if key == "" || strings.ContainsAny(key, "&=?/ ") {
+			return fmt.Errorf("无效的DSN参数名: %q", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,439 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultDualWriteQueueSize      = 10000                 // 默认异步双写队列长度
+	defaultDualWriteMaxDivergences = 100                   // 默认保留的不一致记录数
+	dualWriteTimeout               = 30 * time.Second      // 副库写入超时时间
+	dualWriteFlushInterval         = 10 * time.Millisecond // 等待异步队列写完的轮询间隔
+)
+
+// DualWriteMode 双写模式
+type DualWriteMode int
+
+const (
+	DualWriteSync  DualWriteMode = iota // 同步双写，主库写入成功后在同一协程中写入副库
+	DualWriteAsync                      // 异步双写，主库写入成功后按顺序在后台写入副库
+)
+
+// DualWriteOptions 双写选项
+type DualWriteOptions struct {
+	Mode           DualWriteMode             // 双写模式（默认同步）
+	QueueSize      int                       // 异步模式的队列长度，队列满时丢弃并记为不一致（默认10000）
+	MaxDivergences int                       // 保留的最近不一致记录数（默认100）
+	OnDivergence   func(DualWriteDivergence) // 发现不一致时的回调，异步模式下在后台协程中调用
+}
+
+// DualWriteDivergence 一次双写的不一致记录
+type DualWriteDivergence struct {
+	Time          time.Time     // 发现时间
+	Query         string        // SQL语句
+	Args          []interface{} // SQL参数
+	PrimaryRows   int64         // 主库影响的行数
+	SecondaryRows int64         // 副库影响的行数
+	Err           error         // 副库写入错误，队列满被丢弃时为 ErrDualWriteDropped
+}
+
+// DualWriteStats 双写统计
+type DualWriteStats struct {
+	Writes      uint64 // 已写入副库的语句数
+	Divergences uint64 // 不一致数（含写入失败和丢弃）
+	Errors      uint64 // 副库写入失败数
+	Dropped     uint64 // 异步队列满时丢弃数
+	Pending     int64  // 异步队列中等待写入的语句数
+	CutOver     bool   // 是否已切换主库
+	ReadFrom    string // 读流量所在的库，primary 或 secondary
+}
+
+// ErrDualWriteDropped 异步双写队列已满，写入被丢弃
+var ErrDualWriteDropped = errors.New("双写队列已满，副库写入被丢弃")
+
+// DualWriter 双写器，用于在线迁移数据库：写入当前主库后同步或异步地将同一语句写入副库，记录两边的不一致，
+// 并支持切换读流量和主库。通过 Writer/Reader 获取当前应使用的库，例如：
+//
+//	dw, err := xlorm.NewDualWriter(oldDB, newDB, xlorm.DualWriteOptions{Mode: xlorm.DualWriteAsync})
+//	dw.Writer().M("users").Insert(user) // 写入旧库，并复制到新库
+//	dw.SwitchReads(true)                 // 读流量切换到新库
+//	dw.Cutover(ctx)                      // 新库成为主库，写入复制回旧库以便回退
+//
+// 复制的是主库执行成功的SQL语句而不是数据：依赖 NOW()、UUID()、自增ID 等的语句在两边写入的值可能不同，
+// 只有影响行数不同或副库写入失败时才会记为不一致，迁移完成后仍需校验数据。
+// 覆盖通过 Table 操作、Exec、RunBatch 及 Instrument 驱动执行的写语句；事务中的语句在提交后于副库的同一事务中执行，
+// 回滚时丢弃；级联删除（DeleteCascade）及通过 *sql.Tx 直接执行的语句不会被复制
+type DualWriter struct {
+	mu          sync.RWMutex
+	dbs         [2]*DB // 初始的主库和副库
+	cutOver     bool   // 是否已切换主库
+	readSecond  bool   // 读流量是否在副库
+	opts        DualWriteOptions
+	queue       chan dualWriteJob
+	divergences []DualWriteDivergence
+
+	writes         atomic.Uint64
+	divergenceSeen atomic.Uint64
+	errors         atomic.Uint64
+	dropped        atomic.Uint64
+	pending        atomic.Int64
+}
+
+// dualWriteJob 待写入副库的语句
+type dualWriteJob struct {
+	target *DB
+	query  string
+	args   []interface{}
+	rows   int64
+	tx     []dualWriteJob // 事务提交后待复制的语句，不为空时在副库的同一事务中执行
+}
+
+// dualWriteTx 事务中执行成功的写语句，提交后复制到副库，回滚时丢弃
+type dualWriteTx struct {
+	mu         sync.Mutex
+	jobs       []dualWriteJob
+	savepoints map[string]int // 保存点创建时已缓存的语句数
+}
+
+// add 缓存事务中的语句
+func (b *dualWriteTx) add(job dualWriteJob) {
+	b.mu.Lock()
+	b.jobs = append(b.jobs, job)
+	b.mu.Unlock()
+}
+
+// savepoint 记录保存点的位置
+func (b *dualWriteTx) savepoint(name string) {
+	b.mu.Lock()
+	if b.savepoints == nil {
+		b.savepoints = make(map[string]int)
+	}
+	b.savepoints[name] = len(b.jobs)
+	b.mu.Unlock()
+}
+
+// rollbackTo 丢弃保存点之后缓存的语句
+func (b *dualWriteTx) rollbackTo(name string) {
+	b.mu.Lock()
+	if n, ok := b.savepoints[name]; ok && n <= len(b.jobs) {
+		clear(b.jobs[n:])
+		b.jobs = b.jobs[:n]
+	}
+	b.mu.Unlock()
+}
+
+// take 取出全部缓存的语句并清空
+func (b *dualWriteTx) take() []dualWriteJob {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	jobs := b.jobs
+	b.jobs, b.savepoints = nil, nil
+	return jobs
+}
+
+// NewDualWriter 创建双写器，primary 为当前主库，secondary 为迁移目标库
+// 一个库同一时间只能属于一个双写器，调用 Stop 后解除
+func NewDualWriter(primary, secondary *DB, opts DualWriteOptions) (*DualWriter, error) {
+	if primary == nil || secondary == nil {
+		return nil, errors.New("主库和副库不能为空")
+	}
+	if primary == secondary {
+		return nil, errors.New("主库和副库不能相同")
+	}
+	if opts.Mode != DualWriteSync && opts.Mode != DualWriteAsync {
+		return nil, errors.New("未知的双写模式")
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultDualWriteQueueSize
+	}
+	if opts.MaxDivergences <= 0 {
+		opts.MaxDivergences = defaultDualWriteMaxDivergences
+	}
+	dw := &DualWriter{dbs: [2]*DB{primary, secondary}, opts: opts}
+	if !primary.dualWrite.CompareAndSwap(nil, dw) {
+		return nil, errors.New("主库已开启双写")
+	}
+	if !secondary.dualWrite.CompareAndSwap(nil, dw) {
+		primary.dualWrite.Store(nil)
+		return nil, errors.New("副库已开启双写")
+	}
+	if opts.Mode == DualWriteAsync {
+		dw.queue = make(chan dualWriteJob, opts.QueueSize)
+		if err := primary.RegisterTask(taskDualWrite, dw.run); err != nil {
+			primary.dualWrite.Store(nil)
+			secondary.dualWrite.Store(nil)
+			return nil, err
+		}
+	}
+	return dw, nil
+}
+
+// Writer 获取当前主库，写操作应通过该库执行
+func (dw *DualWriter) Writer() *DB {
+	dw.mu.RLock()
+	defer dw.mu.RUnlock()
+	return dw.writer()
+}
+
+// Reader 获取读流量所在的库
+func (dw *DualWriter) Reader() *DB {
+	dw.mu.RLock()
+	defer dw.mu.RUnlock()
+	if dw.readSecond {
+		return dw.dbs[1]
+	}
+	return dw.dbs[0]
+}
+
+// SwitchReads 切换读流量，secondary 为 true 时从副库读取，为 false 时从初始主库读取
+func (dw *DualWriter) SwitchReads(secondary bool) {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	dw.readSecond = secondary
+}
+
+// Cutover 切换主库：等待异步队列写完后交换主库和副库，之后写入先执行在原副库再复制回原主库，便于回退
+// 再次调用切换回原主库
+func (dw *DualWriter) Cutover(ctx context.Context) error {
+	if err := dw.Flush(ctx); err != nil {
+		return err
+	}
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	dw.cutOver = !dw.cutOver
+	dw.dbs[0].logger.Info("双写切换主库", "cut_over", dw.cutOver)
+	return nil
+}
+
+// Flush 等待异步队列中的语句全部写入副库
+func (dw *DualWriter) Flush(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ticker := time.NewTicker(dualWriteFlushInterval)
+	defer ticker.Stop()
+	for dw.pending.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Stop 停止双写，异步模式下未写入副库的语句会被丢弃
+func (dw *DualWriter) Stop() {
+	for _, db := range dw.dbs {
+		db.dualWrite.CompareAndSwap(dw, nil)
+	}
+	if dw.queue != nil {
+		_ = dw.dbs[0].StopTask(taskDualWrite)
+		dw.pending.Store(0)
+	}
+}
+
+// Divergences 获取最近的不一致记录，按发现时间排列
+func (dw *DualWriter) Divergences() []DualWriteDivergence {
+	dw.mu.RLock()
+	defer dw.mu.RUnlock()
+	return append([]DualWriteDivergence(nil), dw.divergences...)
+}
+
+// Stats 获取双写统计
+func (dw *DualWriter) Stats() DualWriteStats {
+	dw.mu.RLock()
+	cutOver, readSecond := dw.cutOver, dw.readSecond
+	dw.mu.RUnlock()
+	stats := DualWriteStats{
+		Writes:      dw.writes.Load(),
+		Divergences: dw.divergenceSeen.Load(),
+		Errors:      dw.errors.Load(),
+		Dropped:     dw.dropped.Load(),
+		Pending:     dw.pending.Load(),
+		CutOver:     cutOver,
+		ReadFrom:    "primary",
+	}
+	if readSecond {
+		stats.ReadFrom = "secondary"
+	}
+	return stats
+}
+
+// writer 当前主库，调用方需持有锁
+func (dw *DualWriter) writer() *DB {
+	if dw.cutOver {
+		return dw.dbs[1]
+	}
+	return dw.dbs[0]
+}
+
+// dualWriteQuery 当前库为双写主库时将执行成功的写语句复制到副库
+func (db *DB) dualWriteQuery(qi *queryInfo) {
+	dw := db.dualWrite.Load()
+	if dw == nil || qi.err != nil || isReadOnlyQuery(qi.query) {
+		return
+	}
+	dw.mu.RLock()
+	writer := dw.writer()
+	target := dw.dbs[0]
+	if writer == target {
+		target = dw.dbs[1]
+	}
+	dw.mu.RUnlock()
+	if writer != db {
+		return
+	}
+
+	job := dualWriteJob{target: target, query: qi.query, args: qi.args, rows: qi.rows}
+	if qi.dualWriteTx != nil {
+		// 事务中的语句等提交后再复制，避免回滚的写入留在副库
+		job.args = append([]interface{}(nil), qi.args...)
+		qi.dualWriteTx.add(job)
+		return
+	}
+	dw.dispatch(qi.ctx, job)
+}
+
+// commitDualWrites 事务提交后将缓存的语句复制到副库
+func (db *DB) commitDualWrites(ctx context.Context, jobs []dualWriteJob) {
+	dw := db.dualWrite.Load()
+	if dw == nil || len(jobs) == 0 {
+		return
+	}
+	target := dw.dbs[0]
+	if target == db {
+		target = dw.dbs[1]
+	}
+	dw.dispatch(ctx, dualWriteJob{target: target, tx: jobs})
+}
+
+// dispatch 同步模式下直接写入副库，异步模式下加入队列
+func (dw *DualWriter) dispatch(ctx context.Context, job dualWriteJob) {
+	if dw.queue == nil {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		// 主库已写入成功，调用方取消上下文时仍需完成副库写入
+		dw.apply(context.WithoutCancel(ctx), job)
+		return
+	}
+	if job.tx == nil {
+		job.args = append([]interface{}(nil), job.args...)
+	}
+	dw.pending.Add(1)
+	select {
+	case dw.queue <- job:
+	default:
+		dw.pending.Add(-1)
+		for _, stmt := range job.statements() {
+			dw.dropped.Add(1)
+			dw.diverge(DualWriteDivergence{Query: stmt.query, Args: stmt.args, PrimaryRows: stmt.rows, Err: ErrDualWriteDropped})
+		}
+	}
+}
+
+// statements 获取任务包含的语句
+func (job dualWriteJob) statements() []dualWriteJob {
+	if job.tx != nil {
+		return job.tx
+	}
+	return []dualWriteJob{job}
+}
+
+// run 异步双写任务，单协程按主库的执行顺序写入副库
+func (dw *DualWriter) run(ctx context.Context) error {
+	for {
+		select {
+		case job := <-dw.queue:
+			dw.apply(ctx, job)
+			dw.pending.Add(-1)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// apply 将语句写入副库并对比影响的行数
+func (dw *DualWriter) apply(ctx context.Context, job dualWriteJob) {
+	ctx, cancel := context.WithTimeout(ctx, dualWriteTimeout)
+	defer cancel()
+	if job.tx != nil {
+		dw.applyTx(ctx, job)
+		return
+	}
+	rows, err := job.target.execShadow(ctx, job.query, job.args, true)
+	dw.writes.Add(1)
+	if err != nil {
+		dw.errors.Add(1)
+	} else if rows == job.rows {
+		return
+	}
+	dw.diverge(DualWriteDivergence{Query: job.query, Args: job.args, PrimaryRows: job.rows, SecondaryRows: rows, Err: err})
+}
+
+// applyTx 在副库的同一事务中执行主库事务提交的语句，任一语句失败时回滚并将全部语句记为不一致
+func (dw *DualWriter) applyTx(ctx context.Context, job dualWriteJob) {
+	fail := func(err error) {
+		for _, stmt := range job.tx {
+			dw.errors.Add(1)
+			dw.diverge(DualWriteDivergence{Query: stmt.query, Args: stmt.args, PrimaryRows: stmt.rows, Err: err})
+		}
+	}
+	tx, err := job.target.DB.BeginTx(ctx, nil)
+	if err != nil {
+		fail(fmt.Errorf("副库开始事务失败: %w", err))
+		return
+	}
+	var mismatched []DualWriteDivergence
+	for i, stmt := range job.tx {
+		dw.writes.Add(1)
+		result, err := tx.ExecContext(ctx, stmt.query, stmt.args...)
+		var rows int64
+		if err == nil {
+			rows, err = result.RowsAffected()
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			fail(fmt.Errorf("副库事务第 %d 条语句执行失败，已回滚: %w", i+1, err))
+			return
+		}
+		if rows != stmt.rows {
+			mismatched = append(mismatched, DualWriteDivergence{Query: stmt.query, Args: stmt.args, PrimaryRows: stmt.rows, SecondaryRows: rows})
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		fail(fmt.Errorf("副库提交事务失败: %w", err))
+		return
+	}
+	for _, d := range mismatched {
+		dw.diverge(d)
+	}
+}
+
+// diverge 记录不一致
+func (dw *DualWriter) diverge(d DualWriteDivergence) {
+	d.Time = time.Now()
+	dw.divergenceSeen.Add(1)
+	dw.mu.Lock()
+	if len(dw.divergences) >= dw.opts.MaxDivergences {
+		dw.divergences = append(dw.divergences[:0], dw.divergences[1:]...)
+	}
+	dw.divergences = append(dw.divergences, d)
+	logger := dw.writer().logger
+	dw.mu.Unlock()
+	logger.Warn("双写不一致",
+		"query", d.Query,
+		"primary_rows", d.PrimaryRows,
+		"secondary_rows", d.SecondaryRows,
+		"error", d.Err,
+	)
+	if dw.opts.OnDivergence != nil {
+		dw.opts.OnDivergence(d)
+	}
+}
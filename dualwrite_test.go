@@ -0,0 +1,78 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// failingDelete 删除语句执行失败的查询处理函数
+func failingDelete(query string, _ []driver.Value) ([]string, [][]driver.Value, error) {
+	if strings.HasPrefix(query, "DELETE") {
+		return nil, nil, errors.New("删除失败")
+	}
+	return nil, nil, nil
+}
+
+// writeStatements 过滤出写语句及事务语句
+func writeStatements(statements []string) []string {
+	var writes []string
+	for _, statement := range statements {
+		switch {
+		case strings.HasPrefix(statement, "INSERT"), strings.HasPrefix(statement, "UPDATE"), strings.HasPrefix(statement, "DELETE"),
+			statement == "BEGIN", statement == "COMMIT", statement == "ROLLBACK":
+			writes = append(writes, statement[:strings.IndexByte(statement+" ", ' ')])
+		}
+	}
+	return writes
+}
+
+func TestDualWriteSkipsRolledBackTransaction(t *testing.T) {
+	primary, primaryConn := newFakeDB(t, failingDelete)
+	secondary, secondaryConn := newFakeDB(t, nil)
+	dw, err := NewDualWriter(primary, secondary, DualWriteOptions{})
+	if err != nil {
+		t.Fatalf("NewDualWriter() error = %v", err)
+	}
+	defer dw.Stop()
+
+	var b Batch
+	b.Insert("orders", map[string]interface{}{"id": 1}).Delete("carts", "user_id = ?", 1)
+	if _, err := primary.RunBatch(context.Background(), &b); err == nil {
+		t.Fatal("RunBatch() error = nil, want 删除失败")
+	}
+	if got, want := writeStatements(primaryConn.statements()), []string{"BEGIN", "INSERT", "DELETE", "ROLLBACK"}; !slices.Equal(got, want) {
+		t.Fatalf("主库执行 %v, want %v", got, want)
+	}
+	if got := writeStatements(secondaryConn.statements()); len(got) > 0 {
+		t.Fatalf("主库回滚的事务被复制到副库: %v", got)
+	}
+}
+
+func TestDualWriteReplaysCommittedTransaction(t *testing.T) {
+	primary, _ := newFakeDB(t, nil)
+	secondary, secondaryConn := newFakeDB(t, nil)
+	dw, err := NewDualWriter(primary, secondary, DualWriteOptions{Mode: DualWriteAsync})
+	if err != nil {
+		t.Fatalf("NewDualWriter() error = %v", err)
+	}
+	defer dw.Stop()
+
+	var b Batch
+	b.Insert("orders", map[string]interface{}{"id": 1}).Delete("carts", "user_id = ?", 1)
+	if _, err := primary.RunBatch(context.Background(), &b); err != nil {
+		t.Fatalf("RunBatch() error = %v", err)
+	}
+	if err := dw.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got, want := writeStatements(secondaryConn.statements()), []string{"BEGIN", "INSERT", "DELETE", "COMMIT"}; !slices.Equal(got, want) {
+		t.Fatalf("副库执行 %v, want %v", got, want)
+	}
+	if stats := dw.Stats(); stats.Writes != 2 || stats.Divergences != 0 {
+		t.Fatalf("Stats() = %+v, want 2 writes and no divergences", stats)
+	}
+}
@@ -0,0 +1,227 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// explainSlowQueryTimeout 自动 EXPLAIN 慢查询的超时时间
+const explainSlowQueryTimeout = 5 * time.Second
+
+// ExplainRow EXPLAIN 输出的一行
+type ExplainRow struct {
+	ID           int64    // 查询序号
+	SelectType   string   // 查询类型，例如 SIMPLE、PRIMARY、SUBQUERY
+	Table        string   // 表名
+	Partitions   string   // 匹配的分区
+	Type         string   // 访问类型，例如 const、ref、range、index、ALL
+	PossibleKeys []string // 可能使用的索引
+	Key          string   // 实际使用的索引，为空表示未使用索引
+	KeyLen       string   // 使用的索引长度
+	Ref          string   // 与索引比较的列或常量
+	Rows         int64    // 预估扫描行数
+	Filtered     float64  // 按条件过滤后剩余行数的百分比
+	Extra        string   // 附加信息，例如 Using filesort、Using temporary
+}
+
+// ExplainResult 解析后的执行计划
+type ExplainResult struct {
+	Query    string        // SQL语句
+	Args     []interface{} // SQL参数
+	Rows     []ExplainRow  // EXPLAIN 输出
+	Plan     string        // EXPLAIN 原始输出，每行一条记录
+	Analyzed string        // EXPLAIN ANALYZE 的树形输出，仅 ExplainAnalyze 时设置
+}
+
+// EstimatedRows 各步骤预估扫描行数之和
+func (r *ExplainResult) EstimatedRows() int64 {
+	var total int64
+	for _, row := range r.Rows {
+		total += row.Rows
+	}
+	return total
+}
+
+// FullScans 获取预估行数不低于 minRows 且未使用索引的全表扫描的表名
+func (r *ExplainResult) FullScans(minRows int64) []string {
+	var tables []string
+	for _, row := range r.Rows {
+		if strings.EqualFold(row.Type, "ALL") && row.Key == "" && row.Rows >= minRows {
+			tables = append(tables, row.Table)
+		}
+	}
+	return tables
+}
+
+// UsesFilesort 是否使用了文件排序
+func (r *ExplainResult) UsesFilesort() bool {
+	return r.extraContains("Using filesort")
+}
+
+// UsesTemporary 是否使用了临时表
+func (r *ExplainResult) UsesTemporary() bool {
+	return r.extraContains("Using temporary")
+}
+
+// extraContains 判断任一步骤的 Extra 是否包含 s
+func (r *ExplainResult) extraContains(s string) bool {
+	for _, row := range r.Rows {
+		if strings.Contains(row.Extra, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Explain 执行 EXPLAIN 并解析执行计划，不实际执行查询
+func (db *DB) Explain(ctx context.Context, query string, args ...interface{}) (*ExplainResult, error) {
+	if db == nil || db.DB == nil {
+		return nil, errors.New("数据库连接为空")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rows, err := db.DB.QueryContext(ctx, "EXPLAIN "+query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("获取执行计划失败: %v", err)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("获取执行计划失败: %v", err)
+	}
+	values := make([]sql.NullString, len(columns))
+	scanArgs := make([]interface{}, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	result := &ExplainResult{Query: query, Args: args}
+	var plan strings.Builder
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, fmt.Errorf("获取执行计划失败: %v", err)
+		}
+		step := make(map[string]string, len(columns))
+		for i, col := range columns {
+			step[strings.ToLower(col)] = values[i].String
+			if i > 0 {
+				plan.WriteByte(' ')
+			}
+			value := "NULL"
+			if values[i].Valid {
+				value = values[i].String
+			}
+			plan.WriteString(col + "=" + value)
+		}
+		plan.WriteByte('\n')
+		result.Rows = append(result.Rows, parseExplainRow(step))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取执行计划失败: %v", err)
+	}
+	result.Plan = strings.TrimSpace(plan.String())
+	return result, nil
+}
+
+// ExplainAnalyze 执行 EXPLAIN 和 EXPLAIN ANALYZE（MySQL 8.0.18+），后者会实际执行查询并输出各步骤的耗时
+// 仅支持只读查询，且在只读事务中执行后回滚
+func (db *DB) ExplainAnalyze(ctx context.Context, query string, args ...interface{}) (*ExplainResult, error) {
+	if !isReadOnlyQuery(query) {
+		return nil, errors.New("EXPLAIN ANALYZE 会实际执行SQL，仅支持只读查询")
+	}
+	result, err := db.Explain(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("开始只读事务失败: %v", err)
+	}
+	defer tx.Rollback()
+	if result.Analyzed, err = queryPlan(ctx, tx, "EXPLAIN ANALYZE "+query, args); err != nil {
+		return nil, fmt.Errorf("执行 EXPLAIN ANALYZE 失败（需要 MySQL 8.0.18+）: %v", err)
+	}
+	return result, nil
+}
+
+// Explain 获取当前查询条件的执行计划，不实际执行查询
+func (t *Table) Explain() (*ExplainResult, error) {
+	return t.ExplainWithContext(t.context())
+}
+
+// ExplainWithContext 带上下文的Explain
+func (t *Table) ExplainWithContext(ctx context.Context) (*ExplainResult, error) {
+	ctx = t.resolveContext(ctx)
+	defer t.Release()
+	t.resolvePrefix(ctx)
+	query, args := t.buildQuery("SELECT")
+	return t.db.Explain(ctx, query, args...)
+}
+
+// ExplainAnalyze 获取当前查询条件的执行计划及 EXPLAIN ANALYZE 的输出
+func (t *Table) ExplainAnalyze(ctx context.Context) (*ExplainResult, error) {
+	ctx = t.resolveContext(ctx)
+	defer t.Release()
+	t.resolvePrefix(ctx)
+	query, args := t.buildQuery("SELECT")
+	return t.db.ExplainAnalyze(ctx, query, args...)
+}
+
+// explainSlowQuery 异步 EXPLAIN 慢查询并写入日志，同一时间只执行一个，忙碌时跳过
+func (db *DB) explainSlowQuery(qi *queryInfo, duration time.Duration) {
+	if db.closed.Load() || !isReadOnlyQuery(qi.query) || !db.explainBusy.CompareAndSwap(false, true) {
+		return
+	}
+	query, args := qi.query, append([]interface{}(nil), qi.args...)
+	traceID := TraceIDFromContext(qi.ctx)
+	go func() {
+		defer db.explainBusy.Store(false)
+		ctx, cancel := context.WithTimeout(db.ctx, explainSlowQueryTimeout)
+		defer cancel()
+		result, err := db.Explain(ctx, query, args...)
+		if err != nil {
+			if ctx.Err() == nil {
+				db.logger.Warn("慢查询执行计划获取失败", "query", query, "error", err)
+			}
+			return
+		}
+		db.logger.Warn("慢查询执行计划",
+			"query", query,
+			"args", db.redactArgs(query, args),
+			"trace_id", traceID,
+			"duration", duration.Seconds(),
+			"estimated_rows", result.EstimatedRows(),
+			"full_scans", result.FullScans(costCheckFullScanMinRows),
+			"filesort", result.UsesFilesort(),
+			"temporary", result.UsesTemporary(),
+			"plan", result.Plan,
+		)
+	}()
+}
+
+// parseExplainRow 解析 EXPLAIN 的一行，列名已转为小写
+func parseExplainRow(step map[string]string) ExplainRow {
+	row := ExplainRow{
+		SelectType: step["select_type"],
+		Table:      step["table"],
+		Partitions: step["partitions"],
+		Type:       step["type"],
+		Key:        step["key"],
+		KeyLen:     step["key_len"],
+		Ref:        step["ref"],
+		Extra:      step["extra"],
+	}
+	row.ID, _ = strconv.ParseInt(step["id"], 10, 64)
+	row.Rows, _ = strconv.ParseInt(step["rows"], 10, 64)
+	row.Filtered, _ = strconv.ParseFloat(step["filtered"], 64)
+	if keys := step["possible_keys"]; keys != "" {
+		row.PossibleKeys = strings.Split(keys, ",")
+	}
+	return row
+}
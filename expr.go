@@ -0,0 +1,126 @@
+package xlorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 表达式类型
+const (
+	exprRaw      = iota // 原始SQL表达式
+	exprInc             // 字段自增
+	exprDec             // 字段自减
+	exprCoalesce        // COALESCE(字段, 值...)
+)
+
+// expression SQL表达式，可作为更新数据的值使用
+// 例如：map[string]interface{}{"views": xlorm.Inc(1), "updated_at": xlorm.Expr("NOW()")}
+type expression struct {
+	kind int           // 表达式类型
+	sql  string        // 原始SQL片段
+	args []interface{} // 表达式参数
+}
+
+// Expr 创建原始SQL表达式，sql 中的 ? 占位符与 args 一一对应
+func Expr(sql string, args ...interface{}) *expression {
+	return &expression{kind: exprRaw, sql: sql, args: args}
+}
+
+// Expr 创建原始SQL表达式，等同于 xlorm.Expr，可在更新数据中引用字段当前值
+// 例如：db.M("posts").Where("id = ?", id).Update(map[string]interface{}{"views": db.Expr("views + ?", 1)})
+func (db *DB) Expr(sql string, args ...interface{}) *expression {
+	return Expr(sql, args...)
+}
+
+// Inc 字段自增表达式，生成 `field` = `field` + ?
+func Inc(n interface{}) *expression {
+	return &expression{kind: exprInc, args: []interface{}{n}}
+}
+
+// Dec 字段自减表达式，生成 `field` = `field` - ?
+func Dec(n interface{}) *expression {
+	return &expression{kind: exprDec, args: []interface{}{n}}
+}
+
+// Coalesce 字段为NULL时使用候选值，生成 `field` = COALESCE(`field`, ?, ...)
+// 候选值可以是普通值，也可以是 Expr 表达式
+func Coalesce(values ...interface{}) *expression {
+	return &expression{kind: exprCoalesce, args: values}
+}
+
+// build 根据字段名展开表达式，返回SQL片段和参数
+func (e *expression) build(field string) (string, []interface{}) {
+	switch e.kind {
+	case exprInc:
+		return quoteColumn(field) + " + ?", e.args
+	case exprDec:
+		return quoteColumn(field) + " - ?", e.args
+	case exprCoalesce:
+		var sql strings.Builder
+		var args []interface{}
+		sql.WriteString("COALESCE(")
+		sql.WriteString(quoteColumn(field))
+		for _, v := range e.args {
+			sql.WriteString(", ")
+			if sub, ok := v.(*expression); ok {
+				subSQL, subArgs := sub.build(field)
+				sql.WriteString(subSQL)
+				args = append(args, subArgs...)
+				continue
+			}
+			sql.WriteByte('?')
+			args = append(args, v)
+		}
+		sql.WriteByte(')')
+		return sql.String(), args
+	default:
+		return e.sql, e.args
+	}
+}
+
+// validate 校验表达式
+func (e *expression) validate() bool {
+	if e.kind != exprRaw {
+		return true
+	}
+	if e.sql == "" || strings.ContainsAny(e.sql, ";\x00") {
+		return false
+	}
+	return strings.Count(e.sql, "?") == len(e.args)
+}
+
+// buildSetClause 构建UPDATE语句的SET子句
+// 值为 Expr/Inc/Dec/Coalesce 表达式时展开为对应的SQL，其余值使用 ? 占位符
+func buildSetClause(fields []string, values []interface{}) (string, []interface{}, error) {
+	var clause strings.Builder
+	args := make([]interface{}, 0, len(values))
+	for i, field := range fields {
+		if i > 0 {
+			clause.WriteString(", ")
+		}
+		clause.WriteString(quoteColumn(field))
+		clause.WriteString(" = ")
+		if e, ok := values[i].(*expression); ok {
+			if !e.validate() {
+				return "", nil, fmt.Errorf("字段 %s 的表达式非法: %s", field, e.sql)
+			}
+			exprSQL, exprArgs := e.build(field)
+			clause.WriteString(exprSQL)
+			args = append(args, exprArgs...)
+			continue
+		}
+		clause.WriteByte('?')
+		args = append(args, values[i])
+	}
+	return clause.String(), args, nil
+}
+
+// quoteColumn 使用反引号包裹字段名，支持 table.column 和 database.table.column 形式，
+// 无法解析为限定名时整体作为一个标识符引用
+func quoteColumn(name string) string {
+	parts, err := splitQualifiedName(name)
+	if err != nil {
+		return QuoteIdentifier(name)
+	}
+	return joinQualifiedName(parts)
+}
@@ -0,0 +1,179 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// fakeHandler 根据SQL和参数返回查询结果的列名和数据
+type fakeHandler func(query string, args []driver.Value) ([]string, [][]driver.Value, error)
+
+// fakeConnector 测试用的内存驱动，按 handler 返回查询结果并记录执行的语句
+type fakeConnector struct {
+	handler fakeHandler
+	mu      sync.Mutex
+	log     []string
+}
+
+// Connect 实现 driver.Connector
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{c: c}, nil
+}
+
+// Driver 实现 driver.Connector
+func (c *fakeConnector) Driver() driver.Driver {
+	return fakeDriver{c: c}
+}
+
+// record 记录执行的语句
+func (c *fakeConnector) record(query string) {
+	c.mu.Lock()
+	c.log = append(c.log, query)
+	c.mu.Unlock()
+}
+
+// statements 获取已执行的语句
+func (c *fakeConnector) statements() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.log...)
+}
+
+// fakeDriver 实现 driver.Driver
+type fakeDriver struct {
+	c *fakeConnector
+}
+
+// Open 实现 driver.Driver
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{c: d.c}, nil
+}
+
+// fakeConn 测试用的驱动连接
+type fakeConn struct {
+	c *fakeConnector
+}
+
+// Prepare 实现 driver.Conn，不支持预编译
+func (conn *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("不支持预编译")
+}
+
+// Close 实现 driver.Conn
+func (conn *fakeConn) Close() error {
+	return nil
+}
+
+// Begin 实现 driver.Conn
+func (conn *fakeConn) Begin() (driver.Tx, error) {
+	return conn.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx 实现 driver.ConnBeginTx
+func (conn *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	conn.c.record("BEGIN")
+	return fakeTx{c: conn.c}, nil
+}
+
+// Ping 实现 driver.Pinger
+func (conn *fakeConn) Ping(context.Context) error {
+	return nil
+}
+
+// QueryContext 实现 driver.QueryerContext
+func (conn *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	conn.c.record(query)
+	if conn.c.handler == nil {
+		return &fakeRows{}, nil
+	}
+	columns, rows, err := conn.c.handler(query, fakeValues(args))
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: columns, rows: rows}, nil
+}
+
+// ExecContext 实现 driver.ExecerContext，每条语句影响1行，handler 返回错误时执行失败
+func (conn *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	conn.c.record(query)
+	if conn.c.handler != nil {
+		if _, _, err := conn.c.handler(query, fakeValues(args)); err != nil {
+			return nil, err
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+// fakeTx 测试用的事务
+type fakeTx struct {
+	c *fakeConnector
+}
+
+// Commit 实现 driver.Tx
+func (tx fakeTx) Commit() error {
+	tx.c.record("COMMIT")
+	return nil
+}
+
+// Rollback 实现 driver.Tx
+func (tx fakeTx) Rollback() error {
+	tx.c.record("ROLLBACK")
+	return nil
+}
+
+// fakeRows 测试用的结果集
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+// Columns 实现 driver.Rows
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+// Close 实现 driver.Rows
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+// Next 实现 driver.Rows
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// fakeValues 提取命名参数的值
+func fakeValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+// newFakeDB 创建使用内存驱动的数据库实例，测试结束时关闭
+func newFakeDB(t *testing.T, handler fakeHandler) (*DB, *fakeConnector) {
+	t.Helper()
+	connector := &fakeConnector{handler: handler}
+	sqlDB := sql.OpenDB(connector)
+	db, err := NewFromDB(sqlDB, &Config{LogOutput: io.Discard})
+	if err != nil {
+		t.Fatalf("创建数据库实例失败: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		sqlDB.Close()
+	})
+	return db, connector
+}
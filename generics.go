@@ -0,0 +1,177 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Tabler 泛型查询中自定义结构体对应的表名（不含表前缀）
+// 未实现时使用结构体名称的蛇形命名，例如 UserProfile 对应 user_profile
+type Tabler interface {
+	TableName() string
+}
+
+// Query 泛型查询的条件，支持 Table 的全部链式方法
+type Query struct {
+	*Table
+}
+
+// Find 泛型查询，结果按 db 标签映射为 T，例如：
+//
+//	users, err := xlorm.Find[User](db, func(q *xlorm.Query) {
+//		q.Where("status = ?", 1).OrderBy("id DESC").Limit(10)
+//	})
+func Find[T any](db *DB, fn func(q *Query)) ([]T, error) {
+	return FindWithContext[T](db.GetContext(), db, fn)
+}
+
+// FindWithContext 带上下文的Find
+func FindWithContext[T any](ctx context.Context, db *DB, fn func(q *Query)) ([]T, error) {
+	t, err := typedTable[T](db, fn)
+	if err != nil {
+		return nil, err
+	}
+	table := t.rawTableName()
+	rows, err := t.findAllWithContext(ctx, "find")
+	if err != nil {
+		return nil, err
+	}
+	return mapRows[T](db, table, rows)
+}
+
+// First 泛型查询第一条记录，没有记录时返回 sql.ErrNoRows
+func First[T any](db *DB, fn func(q *Query)) (T, error) {
+	return FirstWithContext[T](db.GetContext(), db, fn)
+}
+
+// FirstWithContext 带上下文的First
+func FirstWithContext[T any](ctx context.Context, db *DB, fn func(q *Query)) (T, error) {
+	var zero T
+	t, err := typedTable[T](db, fn)
+	if err != nil {
+		return zero, err
+	}
+	t.limit = 1
+	t.hasTotal = false
+	table := t.rawTableName()
+	rows, err := t.findAllWithContext(ctx, "find")
+	if err != nil {
+		return zero, err
+	}
+	if len(rows) == 0 {
+		return zero, sql.ErrNoRows
+	}
+	items, err := mapRows[T](db, table, rows)
+	if err != nil {
+		return zero, err
+	}
+	return items[0], nil
+}
+
+// Insert 泛型插入，主键为单个整型字段且值为零时，插入后回填自增ID
+func Insert[T any](db *DB, row *T) (int64, error) {
+	return InsertWithContext(db.GetContext(), db, row)
+}
+
+// InsertWithContext 带上下文的Insert
+func InsertWithContext[T any](ctx context.Context, db *DB, row *T) (int64, error) {
+	if row == nil {
+		return 0, fmt.Errorf("插入的数据不能为空")
+	}
+	table, err := tableNameOf[T]()
+	if err != nil {
+		return 0, err
+	}
+	lastInsertId, err := db.Table(table).InsertWithContext(ctx, row)
+	if err != nil {
+		return 0, err
+	}
+	setAutoIncrementPK(db.StructMapper, reflect.ValueOf(row).Elem(), lastInsertId)
+	return lastInsertId, nil
+}
+
+// typedTable 根据类型参数创建表操作对象并应用查询条件
+func typedTable[T any](db *DB, fn func(q *Query)) (*Table, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库连接为空")
+	}
+	table, err := tableNameOf[T]()
+	if err != nil {
+		return nil, err
+	}
+	t := db.Table(table)
+	if fn != nil {
+		fn(&Query{Table: t})
+	}
+	return t, nil
+}
+
+// mapRows 将查询结果映射为结构体切片，映射失败时返回带表名和行号的 ScanError
+func mapRows[T any](db *DB, table string, rows []map[string]interface{}) ([]T, error) {
+	items := make([]T, len(rows))
+	for i, row := range rows {
+		if err := db.StructMapper.MapToStruct(row, &items[i]); err != nil {
+			return nil, wrapScanError(err, table, i+1)
+		}
+	}
+	return items, nil
+}
+
+// tableNameOf 获取类型参数对应的表名
+func tableNameOf[T any]() (string, error) {
+	var zero T
+	if tabler, ok := any(zero).(Tabler); ok {
+		return tabler.TableName(), nil
+	}
+	if tabler, ok := any(&zero).(Tabler); ok {
+		return tabler.TableName(), nil
+	}
+	typ := reflect.TypeOf(zero)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return "", fmt.Errorf("泛型查询的类型参数必须为结构体: %v", typ)
+	}
+	return toSnakeCase(typ.Name()), nil
+}
+
+// setAutoIncrementPK 回填自增主键
+func setAutoIncrementPK(sm *StructMapper, val reflect.Value, id int64) {
+	if id <= 0 {
+		return
+	}
+	meta := sm.getStructMeta(val.Type())
+	if len(meta.pkFields) != 1 {
+		return
+	}
+	field := val.FieldByName(meta.pkFields[0])
+	if !field.CanSet() || !field.IsZero() {
+		return
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(id))
+	}
+}
+
+// toSnakeCase 将驼峰命名转换为蛇形命名，例如 HTTPLog 转换为 http_log
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,164 @@
+package xlorm
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GraphQLType GraphQL 对象类型与数据表的映射
+type GraphQLType struct {
+	Table     string                      // 表名
+	Key       string                      // 主键字段，总是查询（默认 id）
+	Fields    map[string]string           // GraphQL 字段名到字段名的映射，为空时按蛇形命名转换，例如 createdAt 对应 created_at
+	Relations map[string]*GraphQLRelation // GraphQL 字段名到关联关系的映射
+}
+
+// GraphQLRelation GraphQL 嵌套字段对应的表关联
+type GraphQLRelation struct {
+	Type       *GraphQLType // 关联的类型
+	LocalKey   string       // 当前表的关联字段（默认为当前类型的 Key）
+	ForeignKey string       // 关联表的关联字段
+	Many       bool         // 是否为一对多
+}
+
+// GraphQLSelection 根据 GraphQL 选择集解析出的查询字段
+type GraphQLSelection struct {
+	Type      *GraphQLType                 // 对应的类型
+	Columns   []string                     // 需要查询的字段，已包含主键和关联字段
+	Relations map[string]*GraphQLSelection // 选择的嵌套字段
+}
+
+// Select 将 GraphQL 选择集转换为经过校验的查询字段，嵌套字段使用点号分隔，例如：
+//
+//	sel, err := userType.Select("id", "name", "posts.title", "posts.author.name")
+//	rows, err := sel.Apply(db.M("users")).Where("status = ?", 1).FindAll()
+//
+// 未定义的字段返回错误，__typename 等内省字段会被忽略
+func (gt *GraphQLType) Select(selection ...string) (*GraphQLSelection, error) {
+	if gt == nil || gt.Table == "" {
+		return nil, errors.New("GraphQL类型未指定表名")
+	}
+	sel := &GraphQLSelection{Type: gt, Relations: make(map[string]*GraphQLSelection)}
+	sel.Columns = appendField(sel.Columns, gt.key())
+
+	nested := make(map[string][]string)
+	for _, path := range selection {
+		name, rest, isNested := strings.Cut(path, ".")
+		if name == "" || strings.HasPrefix(name, "__") {
+			continue
+		}
+		if rel, ok := gt.Relations[name]; ok {
+			if rel == nil || rel.Type == nil || !isValidFieldName(rel.ForeignKey) {
+				return nil, fmt.Errorf("GraphQL关联 %s.%s 配置不完整", gt.Table, name)
+			}
+			localKey := rel.localKey(gt)
+			if !isValidFieldName(localKey) {
+				return nil, fmt.Errorf("GraphQL关联 %s.%s 的关联字段非法: %s", gt.Table, name, localKey)
+			}
+			sel.Columns = appendField(sel.Columns, localKey)
+			if _, ok := nested[name]; !ok {
+				nested[name] = []string{}
+			}
+			if isNested {
+				nested[name] = append(nested[name], rest)
+			}
+			continue
+		}
+		if isNested {
+			return nil, fmt.Errorf("GraphQL类型 %s 不存在关联字段: %s", gt.Table, name)
+		}
+		column, err := gt.column(name)
+		if err != nil {
+			return nil, err
+		}
+		sel.Columns = appendField(sel.Columns, column)
+	}
+
+	for name, paths := range nested {
+		rel := gt.Relations[name]
+		child, err := rel.Type.Select(paths...)
+		if err != nil {
+			return nil, err
+		}
+		child.Columns = appendField(child.Columns, rel.ForeignKey)
+		sel.Relations[name] = child
+	}
+	return sel, nil
+}
+
+// Apply 将查询字段应用到表操作对象
+func (s *GraphQLSelection) Apply(t *Table) *Table {
+	return t.Fields(s.Columns...)
+}
+
+// Loaders 为选择的嵌套字段创建批量加载器，键为字段路径，例如 posts、posts.author
+// 解析嵌套字段时使用父记录的关联字段值调用 Load（一对多）或 LoadOne（一对一）
+// 加载器会缓存结果，应在每个请求中创建
+func (s *GraphQLSelection) Loaders(db *DB, opts LoaderOptions) (map[string]*Loader, error) {
+	loaders := make(map[string]*Loader)
+	if err := s.addLoaders(db, opts, "", loaders); err != nil {
+		return nil, err
+	}
+	return loaders, nil
+}
+
+// addLoaders 递归创建嵌套字段的加载器
+func (s *GraphQLSelection) addLoaders(db *DB, opts LoaderOptions, prefix string, loaders map[string]*Loader) error {
+	names := make([]string, 0, len(s.Relations))
+	for name := range s.Relations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		child := s.Relations[name]
+		rel := s.Type.Relations[name]
+		opts.Fields = child.Columns
+		loader, err := db.NewLoader(child.Type.Table, rel.ForeignKey, opts)
+		if err != nil {
+			return err
+		}
+		path := prefix + name
+		loaders[path] = loader
+		if err := child.addLoaders(db, opts, path+".", loaders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// key 获取主键字段
+func (gt *GraphQLType) key() string {
+	if gt.Key == "" {
+		return defaultChunkField
+	}
+	return gt.Key
+}
+
+// column 获取 GraphQL 字段对应的字段名
+func (gt *GraphQLType) column(name string) (string, error) {
+	if gt.Fields != nil {
+		column, ok := gt.Fields[name]
+		if !ok {
+			return "", fmt.Errorf("GraphQL类型 %s 不存在字段: %s", gt.Table, name)
+		}
+		if !isValidFieldName(column) {
+			return "", fmt.Errorf("GraphQL字段 %s 对应的字段名非法: %s", name, column)
+		}
+		return column, nil
+	}
+	column := toSnakeCase(name)
+	if !isValidFieldName(column) {
+		return "", fmt.Errorf("GraphQL字段名非法: %s", name)
+	}
+	return column, nil
+}
+
+// localKey 获取当前表的关联字段
+func (rel *GraphQLRelation) localKey(parent *GraphQLType) string {
+	if rel.LocalKey == "" {
+		return parent.key()
+	}
+	return rel.LocalKey
+}
@@ -0,0 +1,157 @@
+package xlorm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// HookType 钩子类型
+type HookType int
+
+const (
+	BeforeInsert HookType = iota // 插入前
+	AfterInsert                  // 插入后
+	BeforeUpdate                 // 更新前
+	AfterUpdate                  // 更新后
+	BeforeDelete                 // 删除前（含软删除）
+	AfterDelete                  // 删除后（含软删除）
+	BeforeQuery                  // 查询前（Find/FindAll/Count）
+	AfterQuery                   // 查询后
+)
+
+// String 返回钩子类型名称
+func (h HookType) String() string {
+	switch h {
+	case BeforeInsert:
+		return "BeforeInsert"
+	case AfterInsert:
+		return "AfterInsert"
+	case BeforeUpdate:
+		return "BeforeUpdate"
+	case AfterUpdate:
+		return "AfterUpdate"
+	case BeforeDelete:
+		return "BeforeDelete"
+	case AfterDelete:
+		return "AfterDelete"
+	case BeforeQuery:
+		return "BeforeQuery"
+	case AfterQuery:
+		return "AfterQuery"
+	default:
+		return fmt.Sprintf("HookType(%d)", int(h))
+	}
+}
+
+// HookContext 钩子上下文
+type HookContext struct {
+	Context      context.Context          // 调用上下文
+	Type         HookType                 // 钩子类型
+	Table        string                   // 表名（含表前缀）
+	Data         interface{}              // 插入/更新的数据，Before 钩子中可修改或替换
+	Query        string                   // SQL语句，插入/更新的 Before 钩子中为空（SQL 在钩子执行后根据 Data 生成）
+	Args         []interface{}            // SQL参数
+	Rows         []map[string]interface{} // 查询结果，仅 FindAll/Find 的 AfterQuery 钩子中有效
+	RowsAffected int64                    // 影响行数，After 钩子中有效
+	LastInsertId int64                    // 插入记录的ID，仅 AfterInsert 钩子中有效
+}
+
+// Hook 钩子函数
+// Before 钩子返回错误时中止操作；After 钩子仅在操作成功后执行，返回的错误会作为操作的错误返回
+type Hook func(hc *HookContext) error
+
+// hookRegistry 钩子注册表
+type hookRegistry struct {
+	mu     sync.RWMutex
+	global map[HookType][]Hook            // 全局钩子
+	tables map[string]map[HookType][]Hook // 表级钩子，键为完整表名
+}
+
+// newHookRegistry 创建钩子注册表
+func newHookRegistry() *hookRegistry {
+	return &hookRegistry{
+		global: make(map[HookType][]Hook),
+		tables: make(map[string]map[HookType][]Hook),
+	}
+}
+
+// AddHook 注册全局钩子，对所有表生效
+func (db *DB) AddHook(typ HookType, hook Hook) *DB {
+	if hook == nil {
+		return db
+	}
+	db.hooks.mu.Lock()
+	defer db.hooks.mu.Unlock()
+	db.hooks.global[typ] = append(db.hooks.global[typ], hook)
+	return db
+}
+
+// AddTableHook 注册表级钩子，tableName 为不含表前缀的表名
+func (db *DB) AddTableHook(tableName string, typ HookType, hook Hook) *DB {
+	if tableName == "" || hook == nil {
+		return db
+	}
+	key := db.GetTableName(tableName)
+	db.hooks.mu.Lock()
+	defer db.hooks.mu.Unlock()
+	if db.hooks.tables[key] == nil {
+		db.hooks.tables[key] = make(map[HookType][]Hook)
+	}
+	db.hooks.tables[key][typ] = append(db.hooks.tables[key][typ], hook)
+	return db
+}
+
+// ClearHooks 清除所有全局和表级钩子
+func (db *DB) ClearHooks() {
+	db.hooks.mu.Lock()
+	defer db.hooks.mu.Unlock()
+	db.hooks.global = make(map[HookType][]Hook)
+	db.hooks.tables = make(map[string]map[HookType][]Hook)
+}
+
+// AddHook 注册仅对本次操作生效的钩子
+func (t *Table) AddHook(typ HookType, hook Hook) *Table {
+	if t.misused("AddHook") {
+		return t
+	}
+	if hook == nil {
+		return t
+	}
+	if t.hooks == nil {
+		t.hooks = make(map[HookType][]Hook)
+	}
+	t.hooks[typ] = append(t.hooks[typ], hook)
+	return t
+}
+
+// newHookContext 创建钩子上下文
+func (t *Table) newHookContext(ctx context.Context, data interface{}) *HookContext {
+	return &HookContext{
+		Context: ctx,
+		Table:   t.rawTableName(),
+		Data:    data,
+	}
+}
+
+// runHooks 依次执行全局、表级和本次操作注册的钩子，遇到错误立即返回
+func (t *Table) runHooks(typ HookType, hc *HookContext) error {
+	var hooks []Hook
+	t.db.hooks.mu.RLock()
+	hooks = append(hooks, t.db.hooks.global[typ]...)
+	hooks = append(hooks, t.db.hooks.tables[t.db.GetTableName(t.name)][typ]...)
+	t.db.hooks.mu.RUnlock()
+	hooks = append(hooks, t.hooks[typ]...)
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	hc.Type = typ
+	for _, hook := range hooks {
+		if err := hook(hc); err != nil {
+			t.db.logger.Warn("钩子返回错误", "hook", typ.String(), "table", hc.Table, "error", err)
+			return fmt.Errorf("%s 钩子返回错误: %w", typ, err)
+		}
+	}
+	return nil
+}
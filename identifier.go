@@ -0,0 +1,182 @@
+package xlorm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxQualifiedParts 限定名的最多部分数：database.table.column
+const maxQualifiedParts = 3
+
+// QuoteIdentifier 使用反引号引用单个标识符（库名、表名、字段名、索引名等），标识符中的反引号转义为两个反引号，
+// 引用后保留字和任意字符都可以安全地作为标识符使用，例如：
+//
+//	QuoteIdentifier("order") // `order`
+//	QuoteIdentifier("a`b")   // `a``b`
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// QuoteQualifiedName 引用限定名，支持 column、table.column、database.table.column 形式，
+// 已用反引号引用的部分按引用规则解析（可包含点号和转义的反引号），最后一部分可以为 *，例如：
+// db.users.id 生成 `db`.`users`.`id`，`my.db`.users 生成 `my.db`.`users`，u.* 生成 `u`.*
+func QuoteQualifiedName(name string) (string, error) {
+	parts, err := splitQualifiedName(name)
+	if err != nil {
+		return "", err
+	}
+	return joinQualifiedName(parts), nil
+}
+
+// splitQualifiedName 按点号拆分限定名，反引号内的点号不拆分，返回去掉引用后的各部分
+func splitQualifiedName(name string) ([]string, error) {
+	if name == "" {
+		return nil, errors.New("标识符不能为空")
+	}
+	if strings.ContainsRune(name, 0) {
+		return nil, fmt.Errorf("标识符包含非法字符: %q", name)
+	}
+	var parts []string
+	for i := 0; ; {
+		var part strings.Builder
+		quoted := i < len(name) && name[i] == '`'
+		if quoted {
+			// 反引号内两个连续的反引号表示一个反引号
+			i++
+			closed := false
+			for i < len(name) {
+				if name[i] == '`' {
+					if i+1 < len(name) && name[i+1] == '`' {
+						part.WriteByte('`')
+						i += 2
+						continue
+					}
+					i++
+					closed = true
+					break
+				}
+				part.WriteByte(name[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("标识符的反引号未闭合: %s", name)
+			}
+		} else {
+			for i < len(name) && name[i] != '.' {
+				part.WriteByte(name[i])
+				i++
+			}
+		}
+		if part.Len() == 0 {
+			return nil, fmt.Errorf("标识符非法: %s", name)
+		}
+		parts = append(parts, part.String())
+		if i == len(name) {
+			break
+		}
+		if name[i] != '.' {
+			return nil, fmt.Errorf("标识符非法: %s", name)
+		}
+		i++
+	}
+	if len(parts) > maxQualifiedParts {
+		return nil, fmt.Errorf("标识符最多包含 %d 部分: %s", maxQualifiedParts, name)
+	}
+	for _, part := range parts[:len(parts)-1] {
+		if part == "*" {
+			return nil, fmt.Errorf("标识符非法: %s", name)
+		}
+	}
+	return parts, nil
+}
+
+// joinQualifiedName 引用各部分后以点号连接，最后一部分为 * 时不引用
+func joinQualifiedName(parts []string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		if part == "*" && i == len(parts)-1 {
+			quoted[i] = part
+			continue
+		}
+		quoted[i] = QuoteIdentifier(part)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// IsReservedWord 是否为服务端的保留字，保留字作为未引用的标识符使用会导致语法错误
+// MySQL 按版本区分 5.7 和 8.0 的保留字，TiDB 按 MySQL 8.0 判断，未识别的服务端按所有服务端保留字的并集判断
+func (s ServerInfo) IsReservedWord(word string) bool {
+	word = strings.ToUpper(word)
+	switch s.Flavor {
+	case FlavorMySQL:
+		if s.AtLeast(8, 0, 0) {
+			return mysqlReservedWords[word] || mysql80ReservedWords[word]
+		}
+		return mysqlReservedWords[word]
+	case FlavorMariaDB:
+		return mysqlReservedWords[word] && !mariadbNotReservedWords[word] || mariadbReservedWords[word]
+	case FlavorTiDB:
+		return mysqlReservedWords[word] || mysql80ReservedWords[word]
+	default:
+		return mysqlReservedWords[word] || mysql80ReservedWords[word] || mariadbReservedWords[word]
+	}
+}
+
+// IsReservedWord 是否为当前服务端的保留字
+func (db *DB) IsReservedWord(word string) bool {
+	return db.ServerInfo().IsReservedWord(word)
+}
+
+// reservedWordSet 创建保留字集合
+func reservedWordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+// mysqlReservedWords MySQL 5.7 和 8.0 共同的保留字
+var mysqlReservedWords = reservedWordSet(`
+ACCESSIBLE ADD ALL ALTER ANALYZE AND AS ASC ASENSITIVE BEFORE BETWEEN BIGINT BINARY BLOB BOTH BY
+CALL CASCADE CASE CHANGE CHAR CHARACTER CHECK COLLATE COLUMN CONDITION CONSTRAINT CONTINUE CONVERT
+CREATE CROSS CURRENT_DATE CURRENT_TIME CURRENT_TIMESTAMP CURRENT_USER CURSOR DATABASE DATABASES
+DAY_HOUR DAY_MICROSECOND DAY_MINUTE DAY_SECOND DEC DECIMAL DECLARE DEFAULT DELAYED DELETE DESC
+DESCRIBE DETERMINISTIC DISTINCT DISTINCTROW DIV DOUBLE DROP DUAL EACH ELSE ELSEIF ENCLOSED ESCAPED
+EXISTS EXIT EXPLAIN FALSE FETCH FLOAT FLOAT4 FLOAT8 FOR FORCE FOREIGN FROM FULLTEXT GENERATED GET
+GRANT GROUP HAVING HIGH_PRIORITY HOUR_MICROSECOND HOUR_MINUTE HOUR_SECOND IF IGNORE IN INDEX INFILE
+INNER INOUT INSENSITIVE INSERT INT INT1 INT2 INT3 INT4 INT8 INTEGER INTERVAL INTO IO_AFTER_GTIDS
+IO_BEFORE_GTIDS IS ITERATE JOIN KEY KEYS KILL LEADING LEAVE LEFT LIKE LIMIT LINEAR LINES LOAD
+LOCALTIME LOCALTIMESTAMP LOCK LONG LONGBLOB LONGTEXT LOOP LOW_PRIORITY MASTER_BIND
+MASTER_SSL_VERIFY_SERVER_CERT MATCH MAXVALUE MEDIUMBLOB MEDIUMINT MEDIUMTEXT MIDDLEINT
+MINUTE_MICROSECOND MINUTE_SECOND MOD MODIFIES NATURAL NOT NO_WRITE_TO_BINLOG NULL NUMERIC ON
+OPTIMIZE OPTIMIZER_COSTS OPTION OPTIONALLY OR ORDER OUT OUTER OUTFILE PARTITION PRECISION PRIMARY
+PROCEDURE PURGE RANGE READ READS READ_WRITE REAL REFERENCES REGEXP RELEASE RENAME REPEAT REPLACE
+REQUIRE RESIGNAL RESTRICT RETURN REVOKE RIGHT RLIKE SCHEMA SCHEMAS SECOND_MICROSECOND SELECT
+SENSITIVE SEPARATOR SET SHOW SIGNAL SMALLINT SPATIAL SPECIFIC SQL SQLEXCEPTION SQLSTATE SQLWARNING
+SQL_BIG_RESULT SQL_CALC_FOUND_ROWS SQL_SMALL_RESULT SSL STARTING STORED STRAIGHT_JOIN TABLE
+TERMINATED THEN TINYBLOB TINYINT TINYTEXT TO TRAILING TRIGGER TRUE UNDO UNION UNIQUE UNLOCK UNSIGNED
+UPDATE USAGE USE USING UTC_DATE UTC_TIME UTC_TIMESTAMP VALUES VARBINARY VARCHAR VARCHARACTER VARYING
+VIRTUAL WHEN WHERE WHILE WITH WRITE XOR YEAR_MONTH ZEROFILL
+`)
+
+// mysql80ReservedWords MySQL 8.0 新增的保留字（窗口函数、CTE 等）
+var mysql80ReservedWords = reservedWordSet(`
+CUBE CUME_DIST DENSE_RANK EMPTY EXCEPT FIRST_VALUE FUNCTION GROUPING GROUPS INTERSECT JSON_TABLE LAG
+LAST_VALUE LATERAL LEAD NTH_VALUE NTILE OF OVER PERCENT_RANK RANK RECURSIVE ROW ROWS ROW_NUMBER
+SYSTEM WINDOW
+`)
+
+// mariadbReservedWords MariaDB 特有的保留字
+var mariadbReservedWords = reservedWordSet(`
+CURRENT_ROLE DELETE_DOMAIN_ID DO_DOMAIN_IDS EXCEPT GENERAL IGNORE_DOMAIN_IDS IGNORE_SERVER_IDS
+INTERSECT MASTER_HEARTBEAT_PERIOD OFFSET OVER PAGE_CHECKSUM PARSE_VCOL_EXPR POSITION RECURSIVE
+REF_SYSTEM_ID RETURNING ROWS ROW_NUMBER SLOW STATS_AUTO_RECALC STATS_PERSISTENT STATS_SAMPLE_PAGES
+WINDOW
+`)
+
+// mariadbNotReservedWords MySQL 中保留但 MariaDB 中不保留的词
+var mariadbNotReservedWords = reservedWordSet(`
+GENERATED GET IO_AFTER_GTIDS IO_BEFORE_GTIDS MASTER_BIND OPTIMIZER_COSTS STORED VIRTUAL
+`)
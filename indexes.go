@@ -0,0 +1,274 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// maxIndexNameLength MySQL 索引名的最大长度
+const maxIndexNameLength = 64
+
+// Indexer 模型声明所需的索引，由 EnsureIndexes 在启动时创建缺失的索引，例如：
+//
+//	func (User) Indexes() []xlorm.Index {
+//		return []xlorm.Index{
+//			{Columns: []string{"email"}, Unique: true},
+//			{Columns: []string{"status", "created_at"}},
+//			{Name: "idx_title", Columns: []string{"title(32)"}},
+//		}
+//	}
+type Indexer interface {
+	Indexes() []Index
+}
+
+// Index 索引定义
+type Index struct {
+	Name    string   // 索引名，为空时按字段生成，普通索引为 idx_字段，唯一索引为 uk_字段
+	Columns []string // 索引字段，按顺序组成联合索引，前缀索引写作 "title(32)"
+	Unique  bool     // 是否为唯一索引
+}
+
+// EnsureIndexOptions 创建索引的选项
+type EnsureIndexOptions struct {
+	Online bool // 使用 ALGORITHM=INPLACE, LOCK=NONE 在线创建，创建期间不阻塞读写；服务端无法在线创建时返回错误
+}
+
+// indexColumn 解析后的索引字段
+type indexColumn struct {
+	name   string
+	length int // 前缀长度，0 表示整个字段
+}
+
+// String 返回字段定义，如 title(32)
+func (c indexColumn) String() string {
+	if c.length > 0 {
+		return c.name + "(" + strconv.Itoa(c.length) + ")"
+	}
+	return c.name
+}
+
+// EnsureIndexes 为模型创建缺失的索引，返回创建的索引（形如 table.index）
+// 模型需实现 Indexer，表名规则与泛型查询相同（实现 Tabler 或使用结构体名称的蛇形命名）
+// 同名索引已存在但定义不同时只记录告警，不会删除或修改已有索引
+func (db *DB) EnsureIndexes(models ...interface{}) ([]string, error) {
+	return db.EnsureIndexesWithContext(db.GetContext(), EnsureIndexOptions{}, models...)
+}
+
+// EnsureIndexesWithContext 带上下文和选项的EnsureIndexes
+func (db *DB) EnsureIndexesWithContext(ctx context.Context, opts EnsureIndexOptions, models ...interface{}) ([]string, error) {
+	var created []string
+	for _, model := range models {
+		indexer, ok := model.(Indexer)
+		if !ok {
+			return created, fmt.Errorf("模型 %T 未实现 Indexer 接口", model)
+		}
+		name, err := modelTableName(model)
+		if err != nil {
+			return created, err
+		}
+		names, err := db.ensureTableIndexes(ctx, name, indexer.Indexes(), opts)
+		created = append(created, names...)
+		if err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// ensureTableIndexes 为一张表创建缺失的索引
+func (db *DB) ensureTableIndexes(ctx context.Context, name string, indexes []Index, opts EnsureIndexOptions) ([]string, error) {
+	t := db.Table(name)
+	defer t.Release()
+	t.resolvePrefix(ctx)
+	if t.tableName == "" {
+		return nil, fmt.Errorf("表名非法: %s", name)
+	}
+	table := t.rawTableName()
+
+	existing, err := db.loadIndexes(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []string
+	for _, index := range indexes {
+		columns, err := parseIndexColumns(index.Columns)
+		if err != nil {
+			return created, fmt.Errorf("表 %s 的索引定义非法: %v", table, err)
+		}
+		indexName := index.Name
+		if indexName == "" {
+			indexName = defaultIndexName(columns, index.Unique)
+		}
+		if !isValidFieldName(indexName) || strings.Contains(indexName, ".") || len(indexName) > maxIndexNameLength {
+			return created, fmt.Errorf("表 %s 的索引名非法: %s", table, indexName)
+		}
+
+		definition := indexDefinition(columns, index.Unique)
+		if current, ok := existing[strings.ToLower(indexName)]; ok {
+			if current != definition {
+				db.logger.Warn("索引已存在但定义不一致", "table", table, "index", indexName, "current", current, "expected", definition)
+			}
+			continue
+		}
+
+		query := buildCreateIndex(t.tableName, indexName, columns, index.Unique, opts.Online)
+		if t.dryRun(ctx, "create_index", query, nil) {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, db.tagQuery(ctx, query)); err != nil {
+			db.logger.Error("创建索引失败", "table", table, "index", indexName, "sql", query, "error", err)
+			return created, fmt.Errorf("创建索引 %s.%s 失败: %v", table, indexName, err)
+		}
+		db.logger.Info("已创建索引", "table", table, "index", indexName, "columns", definition, "online", opts.Online)
+		existing[strings.ToLower(indexName)] = definition
+		created = append(created, table+"."+indexName)
+	}
+	return created, nil
+}
+
+// loadIndexes 读取表的已有索引，返回小写索引名到定义（形如 UNIQUE(a,b(32))）的映射
+func (db *DB) loadIndexes(ctx context.Context, table string) (map[string]string, error) {
+	query := "SELECT INDEX_NAME, COLUMN_NAME, SUB_PART, NON_UNIQUE FROM information_schema.STATISTICS " +
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY INDEX_NAME, SEQ_IN_INDEX"
+	args := []interface{}{table}
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		query = strings.Replace(query, "DATABASE()", "?", 1)
+		args = []interface{}{schema, name}
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("读取索引信息失败: %v", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string][]indexColumn)
+	unique := make(map[string]bool)
+	for rows.Next() {
+		var name, column string
+		var subPart *int
+		var nonUnique int
+		if err := rows.Scan(&name, &column, &subPart, &nonUnique); err != nil {
+			return nil, fmt.Errorf("读取索引信息失败: %v", err)
+		}
+		name = strings.ToLower(name)
+		c := indexColumn{name: column}
+		if subPart != nil {
+			c.length = *subPart
+		}
+		columns[name] = append(columns[name], c)
+		unique[name] = nonUnique == 0
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取索引信息失败: %v", err)
+	}
+
+	existing := make(map[string]string, len(columns))
+	for name, cols := range columns {
+		existing[name] = indexDefinition(cols, unique[name])
+	}
+	return existing, nil
+}
+
+// parseIndexColumns 解析并校验索引字段
+func parseIndexColumns(columns []string) ([]indexColumn, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("索引字段不能为空")
+	}
+	result := make([]indexColumn, 0, len(columns))
+	for _, column := range columns {
+		c := indexColumn{name: strings.TrimSpace(column)}
+		if name, length, ok := strings.Cut(c.name, "("); ok {
+			n, err := strconv.Atoi(strings.TrimSuffix(length, ")"))
+			if err != nil || n <= 0 || !strings.HasSuffix(length, ")") {
+				return nil, fmt.Errorf("前缀索引长度非法: %s", column)
+			}
+			c.name, c.length = strings.TrimSpace(name), n
+		}
+		if !isValidFieldName(c.name) || strings.Contains(c.name, ".") {
+			return nil, fmt.Errorf("索引字段包含非法字符: %s", column)
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// defaultIndexName 按字段生成索引名，超出长度限制时截断
+func defaultIndexName(columns []indexColumn, unique bool) string {
+	prefix := "idx"
+	if unique {
+		prefix = "uk"
+	}
+	parts := make([]string, 0, len(columns)+1)
+	parts = append(parts, prefix)
+	for _, c := range columns {
+		parts = append(parts, c.name)
+	}
+	name := strings.ToLower(strings.Join(parts, "_"))
+	if len(name) > maxIndexNameLength {
+		name = name[:maxIndexNameLength]
+	}
+	return name
+}
+
+// indexDefinition 索引定义的规范形式，用于比较已有索引
+func indexDefinition(columns []indexColumn, unique bool) string {
+	parts := make([]string, len(columns))
+	for i, c := range columns {
+		parts[i] = strings.ToLower(c.String())
+	}
+	definition := "(" + strings.Join(parts, ",") + ")"
+	if unique {
+		return "UNIQUE" + definition
+	}
+	return "INDEX" + definition
+}
+
+// buildCreateIndex 构建 CREATE INDEX 语句，tableName 为已引用的完整表名
+func buildCreateIndex(tableName, indexName string, columns []indexColumn, unique, online bool) string {
+	var query strings.Builder
+	query.WriteString("CREATE ")
+	if unique {
+		query.WriteString("UNIQUE ")
+	}
+	query.WriteString("INDEX ")
+	query.WriteString(quoteColumn(indexName))
+	query.WriteString(" ON ")
+	query.WriteString(tableName)
+	query.WriteString(" (")
+	for i, c := range columns {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString(quoteColumn(c.name))
+		if c.length > 0 {
+			query.WriteByte('(')
+			query.WriteString(strconv.Itoa(c.length))
+			query.WriteByte(')')
+		}
+	}
+	query.WriteByte(')')
+	if online {
+		query.WriteString(" ALGORITHM=INPLACE LOCK=NONE")
+	}
+	return query.String()
+}
+
+// modelTableName 获取模型对应的表名（不含表前缀），规则与泛型查询相同
+func modelTableName(model interface{}) (string, error) {
+	if tabler, ok := model.(Tabler); ok {
+		return tabler.TableName(), nil
+	}
+	typ := reflect.TypeOf(model)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return "", fmt.Errorf("模型必须为结构体: %T", model)
+	}
+	return toSnakeCase(typ.Name()), nil
+}
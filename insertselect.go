@@ -0,0 +1,131 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BuildInsertSelect 以当前查询作为数据来源构建 INSERT INTO target (columns) SELECT ... 语句，数据在服务端直接复制，
+// 适用于归档、复制数据等任务；columns 为空时省略字段列表，此时 SELECT 字段需与目标表字段顺序一致，例如：
+//
+//	db.NewBuilder("orders").Fields("id", "user_id", "amount").Where("created_at < ?", cutoff).
+//		BuildInsertSelect("orders_archive", "id", "user_id", "amount")
+//
+// 生成：INSERT INTO `orders_archive` (`id`, `user_id`, `amount`) SELECT `id`, `user_id`, `amount` FROM orders WHERE created_at < ?
+func (b *builder) BuildInsertSelect(target string, columns ...string) (string, []interface{}, error) {
+	quoted, err := quoteJoinTable(target)
+	if err != nil || strings.ContainsRune(strings.TrimSpace(target), ' ') {
+		b.ReleaseBuilder()
+		return "", nil, fmt.Errorf("目标表名非法: %s", target)
+	}
+	return buildInsertSelect(quoted, columns, b)
+}
+
+// InsertSelect 将 source 查询的结果插入当前表，返回插入的行数，columns 为当前表的字段（与 source 的查询字段一一对应）
+// source 在执行后释放，不能再次使用
+//
+//	src := db.NewBuilder("orders").Fields("id", "user_id", "amount").Where("created_at < ?", cutoff)
+//	n, err := db.M("orders_archive").InsertSelect(src, "id", "user_id", "amount")
+func (t *Table) InsertSelect(source *builder, columns ...string) (int64, error) {
+	return t.InsertSelectWithContext(t.context(), source, columns...)
+}
+
+// InsertSelectWithContext 带上下文的InsertSelect
+func (t *Table) InsertSelectWithContext(ctx context.Context, source *builder, columns ...string) (_ int64, err error) {
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, "insert_select", t.rawTableName(), &err)
+	if err = t.checkUse("insert_select"); err != nil {
+		return
+	}
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	t.resolvePrefix(ctx)
+	defer t.Release()
+	startTime := time.Now()
+
+	if t.tableName == "" {
+		if source != nil {
+			source.ReleaseBuilder()
+		}
+		return 0, errors.New("表名不能为空")
+	}
+	query, args, err := buildInsertSelect(t.tableName, columns, source)
+	if err != nil {
+		return 0, err
+	}
+
+	hc := t.newHookContext(ctx, nil)
+	hc.Query, hc.Args = query, args
+	if err := t.runHooks(BeforeInsert, hc); err != nil {
+		return 0, err
+	}
+	if t.dryRun(ctx, "insert_select", query, args) {
+		return 0, nil
+	}
+	t.db.logQuery(ctx, "执行SQL", "insert_select", query, args)
+	qi := t.newQueryInfo(ctx, "insert_select", query, args)
+	t.db.beforeQuery(qi)
+	result, err := t.db.ExecContext(ctx, t.db.tagQuery(ctx, query), args...)
+	qi.err = err
+	if err == nil {
+		qi.rows, _ = result.RowsAffected()
+	}
+	t.db.afterQuery(qi)
+	if err != nil {
+		t.db.asyncDBMetrics.RecordError()
+		t.db.logger.ErrorContext(ctx, "执行SQL失败", "insert_select", query, "args", t.db.redactArgs(query, args), "error", err)
+		return 0, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	t.db.asyncDBMetrics.RecordQueryDuration("insert_select", time.Since(startTime))
+	t.db.asyncDBMetrics.RecordAffectedRows(rowsAffected)
+	t.invalidateCache()
+
+	hc.RowsAffected = rowsAffected
+	if err := t.runHooks(AfterInsert, hc); err != nil {
+		return rowsAffected, err
+	}
+	return rowsAffected, nil
+}
+
+// buildInsertSelect 构建 INSERT ... SELECT 语句，target 为已引用的目标表名，构建后释放 source
+func buildInsertSelect(target string, columns []string, source *builder) (string, []interface{}, error) {
+	if source == nil {
+		return "", nil, errors.New("数据来源查询不能为空")
+	}
+	for _, column := range columns {
+		if !isValidFieldName(column) || strings.Contains(column, ".") {
+			source.ReleaseBuilder()
+			return "", nil, fmt.Errorf("插入字段包含非法字符: %s", column)
+		}
+	}
+	if n := len(source.fields); len(columns) > 0 && n > 0 && n != len(columns) {
+		source.ReleaseBuilder()
+		return "", nil, fmt.Errorf("插入字段数量（%d）与查询字段数量（%d）不一致", len(columns), n)
+	}
+	selectQuery, args, err := source.Build()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var query strings.Builder
+	query.WriteString("INSERT INTO ")
+	query.WriteString(target)
+	if len(columns) > 0 {
+		query.WriteString(" (")
+		for i, column := range columns {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString(quoteColumn(column))
+		}
+		query.WriteByte(')')
+	}
+	query.WriteByte(' ')
+	query.WriteString(selectQuery)
+	return query.String(), args, nil
+}
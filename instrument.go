@@ -0,0 +1,300 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Instrument 返回包装了已注册驱动 driverName 的 database/sql 驱动，经该驱动执行的SQL与 Table API 一样记录耗时、
+// 慢查询、指标、调试日志，并投递查询事件和插件回调，便于已有 *sql.DB 代码在迁移到 Table API 之前先接入可观测性：
+//
+//	drv, err := db.Instrument("mysql")
+//	sql.Register("xlorm-mysql", drv)
+//	legacy, err := sql.Open("xlorm-mysql", dsn)
+//
+// 包装驱动只做观测，不启用重试、缓存、查询标签等 Table API 功能；db 关闭后不应再使用
+// 驱动返回 driver.ErrSkip 时 database/sql 会改用预编译语句执行，该次调用只投递开始事件，结束事件由预编译语句的执行记录
+func (db *DB) Instrument(driverName string) (driver.Driver, error) {
+	sqlDB, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, fmt.Errorf("获取驱动失败: %w", err)
+	}
+	d := sqlDB.Driver()
+	sqlDB.Close()
+	return &instrumentedDriver{Driver: d, db: db}, nil
+}
+
+// instrumentedDriver 记录SQL执行情况的驱动
+type instrumentedDriver struct {
+	driver.Driver
+	db *DB
+}
+
+// Open 实现 driver.Driver
+func (d *instrumentedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn, db: d.db}, nil
+}
+
+// OpenConnector 实现 driver.DriverContext，驱动不支持时按 DSN 调用 Open
+func (d *instrumentedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.Driver.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &instrumentedConnector{Connector: connector, driver: d}, nil
+	}
+	return &instrumentedConnector{driver: d, dsn: name}, nil
+}
+
+// instrumentedConnector 记录SQL执行情况的连接器
+type instrumentedConnector struct {
+	driver.Connector // 驱动不支持 DriverContext 时为空
+	driver           *instrumentedDriver
+	dsn              string
+}
+
+// Connect 实现 driver.Connector
+func (c *instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if c.Connector == nil {
+		return c.driver.Open(c.dsn)
+	}
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn, db: c.driver.db}, nil
+}
+
+// Driver 实现 driver.Connector
+func (c *instrumentedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// instrumentedConn 记录SQL执行情况的连接，其余操作转发给驱动连接
+type instrumentedConn struct {
+	driver.Conn
+	db *DB
+	tx *dualWriteTx // 进行中的事务待复制到双写副库的语句
+}
+
+// PrepareContext 实现 driver.ConnPrepareContext
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{Stmt: stmt, conn: c, query: query}, nil
+}
+
+// Prepare 实现 driver.Conn
+func (c *instrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// BeginTx 实现 driver.ConnBeginTx
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var tx driver.Tx
+	var err error
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = b.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.Conn.Begin()
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.tx = &dualWriteTx{}
+	return &instrumentedTx{Tx: tx, conn: c}, nil
+}
+
+// instrumentedTx 记录事务结束的驱动事务，提交后将事务中的写语句复制到双写副库
+type instrumentedTx struct {
+	driver.Tx
+	conn *instrumentedConn
+}
+
+// Commit 实现 driver.Tx
+func (tx *instrumentedTx) Commit() error {
+	jobs := tx.conn.tx.take()
+	tx.conn.tx = nil
+	if err := tx.Tx.Commit(); err != nil {
+		return err
+	}
+	tx.conn.db.commitDualWrites(context.Background(), jobs)
+	return nil
+}
+
+// Rollback 实现 driver.Tx
+func (tx *instrumentedTx) Rollback() error {
+	tx.conn.tx = nil
+	return tx.Tx.Rollback()
+}
+
+// ExecContext 实现 driver.ExecerContext
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	var result driver.Result
+	err := c.db.observeDriverCall(ctx, "exec", query, args, func(qi *queryInfo) error {
+		qi.dualWriteTx = c.tx
+		var err error
+		if result, err = e.ExecContext(ctx, query, args); err == nil {
+			qi.rows, _ = result.RowsAffected()
+		}
+		return err
+	})
+	return result, err
+}
+
+// QueryContext 实现 driver.QueryerContext，耗时为收到结果集首包的时间
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	var rows driver.Rows
+	err := c.db.observeDriverCall(ctx, "query", query, args, func(qi *queryInfo) error {
+		qi.dualWriteTx = c.tx
+		var err error
+		rows, err = q.QueryContext(ctx, query, args)
+		return err
+	})
+	return rows, err
+}
+
+// Ping 实现 driver.Pinger
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession 实现 driver.SessionResetter
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid 实现 driver.Validator
+func (c *instrumentedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue 实现 driver.NamedValueChecker，保留驱动自定义的参数转换
+func (c *instrumentedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// instrumentedStmt 记录执行情况的预编译语句
+type instrumentedStmt struct {
+	driver.Stmt
+	conn  *instrumentedConn
+	query string
+}
+
+// ExecContext 实现 driver.StmtExecContext
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	var result driver.Result
+	err := s.conn.db.observeDriverCall(ctx, "exec", s.query, args, func(qi *queryInfo) error {
+		qi.dualWriteTx = s.conn.tx
+		var err error
+		if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+			result, err = e.ExecContext(ctx, args)
+		} else {
+			var values []driver.Value
+			if values, err = namedValuesToValues(args); err == nil {
+				result, err = s.Stmt.Exec(values)
+			}
+		}
+		if err == nil {
+			qi.rows, _ = result.RowsAffected()
+		}
+		return err
+	})
+	return result, err
+}
+
+// QueryContext 实现 driver.StmtQueryContext
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var rows driver.Rows
+	err := s.conn.db.observeDriverCall(ctx, "query", s.query, args, func(qi *queryInfo) error {
+		qi.dualWriteTx = s.conn.tx
+		var err error
+		if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+			rows, err = q.QueryContext(ctx, args)
+		} else {
+			var values []driver.Value
+			if values, err = namedValuesToValues(args); err == nil {
+				rows, err = s.Stmt.Query(values)
+			}
+		}
+		return err
+	})
+	return rows, err
+}
+
+// CheckNamedValue 实现 driver.NamedValueChecker，语句未实现时使用连接的参数转换
+func (s *instrumentedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return s.conn.CheckNamedValue(nv)
+}
+
+// observeDriverCall 记录一次驱动调用的耗时、慢查询、指标和日志，驱动返回 driver.ErrSkip 时不记录
+func (db *DB) observeDriverCall(ctx context.Context, operation, query string, args []driver.NamedValue, call func(qi *queryInfo) error) error {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	qi := &queryInfo{ctx: ctx, operation: operation, query: query, args: values, rowsUnknown: operation == "query"}
+	db.beforeQuery(qi)
+	err := call(qi)
+	if errors.Is(err, driver.ErrSkip) {
+		if db.profileSlowQueries {
+			db.restoreProfileLabels(qi)
+		}
+		return err
+	}
+	duration := time.Since(qi.start)
+	qi.err = err
+	db.afterQuery(qi)
+	db.logQuery(ctx, "执行SQL", operation, query, values, "duration", duration)
+	if err != nil {
+		db.asyncDBMetrics.RecordError()
+		db.logger.ErrorContext(ctx, "执行SQL失败", operation, query, "args", db.redactArgs(query, values), "error", err, "duration", duration)
+		return err
+	}
+	db.asyncDBMetrics.RecordQueryDuration(operation, duration)
+	if duration > db.slowQueryThreshold {
+		db.asyncDBMetrics.RecordSlowQuery()
+		db.logger.WarnContext(ctx, "慢查询", operation, query, "args", db.redactArgs(query, values), "duration", duration.Seconds())
+	}
+	return nil
+}
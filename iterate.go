@@ -0,0 +1,189 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultChunkField = "id" // 默认分块字段
+	defaultChunkSize  = 1000 // 默认每块记录数
+)
+
+// ErrStopIteration 回调返回该错误时停止遍历，Iterate 等方法返回 nil
+var ErrStopIteration = errors.New("停止遍历")
+
+// ChunkBy 设置分块读取使用的字段和每块记录数，默认按 id 每次读取1000条
+// field 必须唯一且有序（通常为自增主键）
+func (t *Table) ChunkBy(field string, size int64) *Table {
+	if !isValidFieldName(field) {
+		t.db.logger.Error("分块字段非法", "field", field)
+		return t
+	}
+	if size <= 0 {
+		t.db.logger.Error("分块大小必须为正数", "size", size)
+		return t
+	}
+	t.chunkField = field
+	t.chunkSize = size
+	return t
+}
+
+// Iterate 逐行遍历查询结果，按分块字段分块读取（keyset），不会将全部结果加载到内存，适用于大表导出
+// fn 返回错误时中止遍历并返回该错误，返回 ErrStopIteration 时正常结束
+// 结果按分块字段升序返回，已设置的 OrderBy、Limit、Offset 不生效
+func (t *Table) Iterate(ctx context.Context, fn func(row map[string]interface{}) error) error {
+	return t.eachChunk(ctx, "iterate", func(rows []map[string]interface{}) error {
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// IterateInto 逐行遍历查询结果并写入 dest（结构体指针）后调用 fn，dest 在每行之间复用
+// 例如：
+//
+//	var u User
+//	err := db.M("users").IterateInto(ctx, &u, func() error { return w.Write(u) })
+func (t *Table) IterateInto(ctx context.Context, dest interface{}, fn func() error) error {
+	val := reflect.ValueOf(dest)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		t.Release()
+		return errors.New("dest必须为结构体指针")
+	}
+	elem := val.Elem()
+	mapper := t.db.StructMapper
+	table := t.rawTableName()
+	var n int
+	return t.eachChunk(ctx, "iterateInto", func(rows []map[string]interface{}) error {
+		for _, row := range rows {
+			n++
+			elem.SetZero()
+			if err := mapper.MapToStruct(row, dest); err != nil {
+				return wrapScanError(err, table, n)
+			}
+			if err := fn(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// eachChunk 按分块字段分块读取查询结果，每块调用一次 fn
+func (t *Table) eachChunk(ctx context.Context, findType string, fn func(rows []map[string]interface{}) error) (err error) {
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, findType, t.rawTableName(), &err)
+	if err = t.checkUse(findType); err != nil {
+		return
+	}
+	defer t.Release()
+	field, size := t.chunkField, t.chunkSize
+	if field == "" {
+		field = t.primaryKey()
+	}
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+
+	// 结果集中的列名不含表名前缀
+	column := field[strings.LastIndexByte(field, '.')+1:]
+	fields := t.fields
+	if len(fields) > 0 && !slices.Contains(fields, column) && !slices.Contains(fields, field) {
+		fields = append(slices.Clone(fields), field)
+	}
+
+	var after interface{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		chunk := t.cloneTable()
+		chunk.fields = fields
+		chunk.hooks = t.hooks
+		if after != nil {
+			chunk.addScope(quoteColumn(field)+" > ?", after)
+		}
+		chunk.orderBy = quoteColumn(field) + " ASC"
+		chunk.limit = size
+
+		rows, err := chunk.findAllWithContext(ctx, findType)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := fn(rows); err != nil {
+			if errors.Is(err, ErrStopIteration) {
+				return nil
+			}
+			return err
+		}
+		if int64(len(rows)) < size {
+			return nil
+		}
+		if after = rows[len(rows)-1][column]; after == nil {
+			return fmt.Errorf("分块字段 %s 的值为空", field)
+		}
+	}
+}
+
+// FindInBatches 按分块字段范围分批读取查询结果，每批调用一次 fn，适用于批量处理大表数据
+// 分块字段默认为 id，可通过 ChunkBy 指定；fn 返回 ErrStopIteration 时正常结束
+// 例如：
+//
+//	err := db.M("orders").Where("status = ?", 0).FindInBatches(500, func(batch []map[string]interface{}) error {
+//		return process(batch)
+//	})
+func (t *Table) FindInBatches(batchSize int64, fn func(batch []map[string]interface{}) error) error {
+	return t.FindInBatchesWithContext(t.context(), batchSize, fn)
+}
+
+// FindInBatchesWithContext 带上下文的FindInBatches
+func (t *Table) FindInBatchesWithContext(ctx context.Context, batchSize int64, fn func(batch []map[string]interface{}) error) error {
+	if batchSize <= 0 {
+		t.Release()
+		return errors.New("batchSize必须为正数")
+	}
+	t.chunkSize = batchSize
+	return t.eachChunk(ctx, "findInBatches", fn)
+}
+
+// FindInBatchesTx 分批读取查询结果，每批在独立的事务中调用 fn
+// fn 返回错误时仅回滚当前批次，之前批次已提交；返回 ErrStopIteration 时提交当前批次并正常结束
+func (t *Table) FindInBatchesTx(ctx context.Context, batchSize int64, fn func(tx *Transaction, batch []map[string]interface{}) error) error {
+	if batchSize <= 0 {
+		t.Release()
+		return errors.New("batchSize必须为正数")
+	}
+	db := t.db
+	t.chunkSize = batchSize
+	return t.eachChunk(ctx, "findInBatchesTx", func(rows []map[string]interface{}) error {
+		stop := false
+		err := db.ExecTx(func(tx *Transaction) error {
+			if err := fn(tx, rows); err != nil {
+				if errors.Is(err, ErrStopIteration) {
+					stop = true
+					return nil
+				}
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if stop {
+			return ErrStopIteration
+		}
+		return nil
+	})
+}
@@ -0,0 +1,90 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// iterateUsers 分块读取测试的数据，按 id 升序
+var iterateUsers = []struct {
+	id   int64
+	name string
+}{
+	{1, "a"}, {2, "b"}, {3, "c"}, {4, "a"}, {5, "b"}, {6, "c"}, {7, "a"},
+}
+
+// iterateHandler 按 MySQL 的运算符优先级执行 `name = ? OR name = ?` 条件的分块查询，每块2条
+func iterateHandler(t *testing.T) fakeHandler {
+	return func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if !strings.Contains(query, "FROM `users`") {
+			return nil, nil, nil
+		}
+		var match func(id int64, name string) bool
+		switch {
+		case len(args) == 2:
+			match = func(_ int64, name string) bool { return name == args[0] || name == args[1] }
+		case strings.Contains(query, "(name = ? OR name = ?) AND `id` > ?"):
+			match = func(id int64, name string) bool {
+				return (name == args[0] || name == args[1]) && id > args[2].(int64)
+			}
+		case strings.Contains(query, "name = ? OR name = ? AND `id` > ?"):
+			// AND 优先于 OR，第一个分支不受分块条件限制
+			match = func(id int64, name string) bool {
+				return name == args[0] || (name == args[1] && id > args[2].(int64))
+			}
+		default:
+			t.Errorf("无法识别的分块查询: %s", query)
+			return nil, nil, errors.New("无法识别的分块查询")
+		}
+		var rows [][]driver.Value
+		for _, user := range iterateUsers {
+			if match(user.id, user.name) && len(rows) < 2 {
+				rows = append(rows, []driver.Value{user.id, user.name})
+			}
+		}
+		return []string{"id", "name"}, rows, nil
+	}
+}
+
+func TestIterateTerminatesWithRawOrCondition(t *testing.T) {
+	db, _ := newFakeDB(t, iterateHandler(t))
+
+	var ids []int64
+	err := db.M("users").Where("name = ? OR name = ?", "a", "b").ChunkBy("id", 2).
+		Iterate(context.Background(), func(row map[string]interface{}) error {
+			ids = append(ids, row["id"].(int64))
+			if len(ids) > len(iterateUsers) {
+				return errors.New("分块读取未结束，重复返回已读取的记录")
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("Iterate() error = %v, ids = %v", err, ids)
+	}
+	if want := []int64{1, 2, 4, 5, 7}; !slices.Equal(ids, want) {
+		t.Fatalf("ids = %v, want %v", ids, want)
+	}
+}
+
+func TestFindInBatchesTerminatesWithRawOrCondition(t *testing.T) {
+	db, _ := newFakeDB(t, iterateHandler(t))
+
+	batches := 0
+	err := db.M("users").Where("name = ? OR name = ?", "a", "b").
+		FindInBatches(2, func(batch []map[string]interface{}) error {
+			if batches++; batches > len(iterateUsers) {
+				return errors.New("分批读取未结束，重复返回已读取的记录")
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("FindInBatches() error = %v", err)
+	}
+	if batches != 3 {
+		t.Fatalf("batches = %d, want 3", batches)
+	}
+}
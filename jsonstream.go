@@ -0,0 +1,267 @@
+package xlorm
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// jsonColumnKind 列值的 JSON 编码方式
+type jsonColumnKind int
+
+const (
+	jsonColumnString jsonColumnKind = iota // 字符串
+	jsonColumnNumber                       // 数值，原样输出
+	jsonColumnRaw                          // JSON 类型字段，原样输出
+)
+
+// jsonHex JSON 转义使用的十六进制字符
+const jsonHex = "0123456789abcdef"
+
+// FindAllJSON 将查询结果以 JSON 数组的形式直接写入 w，不构建 map，适用于高吞吐的读接口
+// 数值类型字段输出为 JSON 数字，JSON 类型字段原样输出，NULL 输出为 null，时间输出为 RFC3339 字符串，其余输出为字符串
+func (t *Table) FindAllJSON(w io.Writer) error {
+	return t.FindAllJSONWithContext(t.context(), w)
+}
+
+// FindAllJSONWithContext 带上下文的FindAllJSON
+func (t *Table) FindAllJSONWithContext(ctx context.Context, w io.Writer) (err error) {
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, "findAllJSON", t.rawTableName(), &err)
+	if err = t.checkUse("findAllJSON"); err != nil {
+		return
+	}
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	defer t.Release()
+	t.resolvePrefix(ctx)
+	startTime := time.Now()
+
+	query, args := t.buildQuery("SELECT")
+	hc := t.newHookContext(ctx, nil)
+	hc.Query, hc.Args = query, args
+	if err := t.runHooks(BeforeQuery, hc); err != nil {
+		return err
+	}
+	if t.dryRun(ctx, "findAllJSON", query, args) {
+		_, err := io.WriteString(w, "[]")
+		return err
+	}
+
+	t.db.logQuery(ctx, "执行SQL", "findAllJSON", query, args)
+
+	if err := t.db.checkQueryCost(ctx, query, args); err != nil {
+		return err
+	}
+	qi := t.newQueryInfo(ctx, "findAllJSON", query, args)
+	t.db.beforeQuery(qi)
+	defer t.db.afterQuery(qi)
+	rows, err := t.db.QueryContext(ctx, t.db.tagQuery(ctx, query), args...)
+	if err != nil {
+		qi.err = err
+		t.db.asyncDBMetrics.RecordError()
+		t.db.logger.Error("执行查询失败", "findAllJSON", query, "args", t.db.redactArgs(query, args), "error", err)
+		return fmt.Errorf("执行查询失败: %v", err)
+	}
+	defer rows.Close()
+
+	if err := writeRowsJSON(rows, w, &qi.rows); err != nil {
+		qi.err = err
+		t.db.asyncDBMetrics.RecordError()
+		t.db.logger.Error("输出JSON失败", "findAllJSON", query, "args", t.db.redactArgs(query, args), "error", err)
+		return err
+	}
+
+	duration := time.Since(startTime)
+	t.db.asyncDBMetrics.RecordQueryDuration("findAllJSON", duration)
+	if duration >= t.db.slowQueryThreshold {
+		t.db.asyncDBMetrics.RecordSlowQuery()
+		t.db.logger.WarnContext(ctx, "慢查询",
+			"query", query,
+			"args", t.db.redactArgs(query, args),
+			"duration", duration.Seconds(),
+			"threshold", t.db.slowQueryThreshold,
+		)
+	}
+
+	hc.RowsAffected = qi.rows
+	return t.runHooks(AfterQuery, hc)
+}
+
+// writeRowsJSON 将结果集编码为 JSON 数组写入 w，count 记录已写入的行数
+func writeRowsJSON(rows *sql.Rows, w io.Writer, count *int64) error {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return fmt.Errorf("获取列信息失败: %v", err)
+	}
+	columnsLen := len(columnTypes)
+
+	// 预先编码字段名和编码方式
+	names := make([]string, columnsLen)
+	keys := make([][]byte, columnsLen)
+	kinds := make([]jsonColumnKind, columnsLen)
+	for i, ct := range columnTypes {
+		names[i] = ct.Name()
+		key := appendJSONString(nil, ct.Name())
+		keys[i] = append(key, ':')
+		kinds[i] = jsonColumnKindOf(ct.DatabaseTypeName())
+	}
+
+	values := make([]interface{}, columnsLen)
+	scanArgs := make([]interface{}, columnsLen)
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	bw := bufio.NewWriterSize(w, 32*1024)
+	buf := make([]byte, 0, 1024)
+	buf = append(buf, '[')
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return newRowsScanError(err, "", names, scanArgs, int(*count)+1)
+		}
+		if *count > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, '{')
+		for i, value := range values {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, keys[i]...)
+			if buf, err = appendJSONValue(buf, value, kinds[i]); err != nil {
+				return fmt.Errorf("字段 %s 编码失败: %v", columnTypes[i].Name(), err)
+			}
+		}
+		buf = append(buf, '}')
+		*count++
+
+		if _, err := bw.Write(buf); err != nil {
+			return fmt.Errorf("写入JSON失败: %v", err)
+		}
+		buf = buf[:0]
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("遍历结果集失败: %v", err)
+	}
+	buf = append(buf, ']')
+	if _, err := bw.Write(buf); err != nil {
+		return fmt.Errorf("写入JSON失败: %v", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("写入JSON失败: %v", err)
+	}
+	return nil
+}
+
+// jsonColumnKindOf 根据数据库字段类型确定 JSON 编码方式
+func jsonColumnKindOf(typeName string) jsonColumnKind {
+	typeName = strings.TrimPrefix(strings.ToUpper(typeName), "UNSIGNED ")
+	switch typeName {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "DECIMAL", "FLOAT", "DOUBLE", "YEAR":
+		return jsonColumnNumber
+	case "JSON":
+		return jsonColumnRaw
+	default:
+		return jsonColumnString
+	}
+}
+
+// appendJSONValue 按编码方式追加单个字段值
+func appendJSONValue(buf []byte, value interface{}, kind jsonColumnKind) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case int64:
+		return strconv.AppendInt(buf, v, 10), nil
+	case uint64:
+		return strconv.AppendUint(buf, v, 10), nil
+	case float64:
+		return strconv.AppendFloat(buf, v, 'g', -1, 64), nil
+	case float32:
+		return strconv.AppendFloat(buf, float64(v), 'g', -1, 32), nil
+	case bool:
+		return strconv.AppendBool(buf, v), nil
+	case time.Time:
+		buf = append(buf, '"')
+		buf = v.AppendFormat(buf, time.RFC3339Nano)
+		return append(buf, '"'), nil
+	case []byte:
+		switch kind {
+		case jsonColumnNumber:
+			// 文本协议下数值以字符串形式返回，内容即为合法的 JSON 数字
+			return append(buf, v...), nil
+		case jsonColumnRaw:
+			if !json.Valid(v) {
+				return buf, fmt.Errorf("非法的JSON: %s", v)
+			}
+			return append(buf, v...), nil
+		}
+		return appendJSONString(buf, string(v)), nil
+	case string:
+		return appendJSONString(buf, v), nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return buf, err
+		}
+		return append(buf, b...), nil
+	}
+}
+
+// appendJSONString 追加转义后的 JSON 字符串，非法的 UTF-8 字节替换为 \ufffd
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', jsonHex[c>>4], jsonHex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028、U+2029 在部分 JavaScript 环境中是换行符
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', jsonHex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
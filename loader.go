@@ -0,0 +1,175 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLoaderWait     = time.Millisecond // 默认批量收集等待时间
+	defaultLoaderMaxBatch = 100              // 默认每批最大键数量
+)
+
+// LoaderOptions 批量加载器选项
+type LoaderOptions struct {
+	Fields   []string      // 查询字段，为空时查询全部字段（会自动包含关联字段）
+	Wait     time.Duration // 收集键的等待时间（默认1ms）
+	MaxBatch int           // 每批最大键数量，达到后立即查询（默认100）
+}
+
+// Loader 批量加载器（dataloader），将同一时间窗口内的多次 Load 合并为一条 WHERE key IN (...) 查询，
+// 并缓存已加载的键，用于消除 GraphQL 等嵌套解析中的 N+1 查询
+// Loader 会缓存结果，应按请求创建，不要在请求之间共享
+type Loader struct {
+	db       *DB
+	table    string
+	key      string
+	fields   []string
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	cache   map[string]*loaderBatch
+	pending *loaderBatch
+}
+
+// loaderBatch 一批待加载的键
+type loaderBatch struct {
+	keys    []interface{}
+	done    chan struct{}
+	results map[string][]map[string]interface{}
+	err     error
+}
+
+// NewLoader 创建按 key 字段批量加载 table 表记录的加载器
+func (db *DB) NewLoader(table, key string, opts LoaderOptions) (*Loader, error) {
+	if table == "" {
+		return nil, errors.New("表名不能为空")
+	}
+	if !isValidFieldName(key) {
+		return nil, fmt.Errorf("关联字段非法: %s", key)
+	}
+	fields := opts.Fields
+	if len(fields) > 0 {
+		fields = appendField(fields, key)
+		for _, field := range fields {
+			if !isValidFieldName(field) {
+				return nil, fmt.Errorf("查询字段非法: %s", field)
+			}
+		}
+	}
+	if opts.Wait <= 0 {
+		opts.Wait = defaultLoaderWait
+	}
+	if opts.MaxBatch <= 0 {
+		opts.MaxBatch = defaultLoaderMaxBatch
+	}
+	return &Loader{
+		db:       db,
+		table:    table,
+		key:      key,
+		fields:   fields,
+		wait:     opts.Wait,
+		maxBatch: opts.MaxBatch,
+		cache:    make(map[string]*loaderBatch),
+	}, nil
+}
+
+// Load 加载 key 字段等于 key 的全部记录（一对多），没有记录时返回空切片
+func (l *Loader) Load(ctx context.Context, key interface{}) ([]map[string]interface{}, error) {
+	k := loaderKey(key)
+	l.mu.Lock()
+	batch, ok := l.cache[k]
+	if !ok {
+		if l.pending == nil {
+			batch = &loaderBatch{done: make(chan struct{})}
+			l.pending = batch
+			time.AfterFunc(l.wait, func() { l.dispatch(batch) })
+		}
+		batch = l.pending
+		batch.keys = append(batch.keys, key)
+		l.cache[k] = batch
+		if len(batch.keys) >= l.maxBatch {
+			l.pending = nil
+			go l.dispatch(batch)
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if batch.err != nil {
+		return nil, batch.err
+	}
+	return batch.results[k], nil
+}
+
+// LoadOne 加载 key 字段等于 key 的第一条记录（一对一），没有记录时返回 nil
+func (l *Loader) LoadOne(ctx context.Context, key interface{}) (map[string]interface{}, error) {
+	rows, err := l.Load(ctx, key)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+// Clear 清除已缓存的结果，数据更新后调用
+func (l *Loader) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for k, batch := range l.cache {
+		if batch != l.pending {
+			delete(l.cache, k)
+		}
+	}
+}
+
+// dispatch 执行一批键的查询，批次可能因达到 MaxBatch 已提前执行
+func (l *Loader) dispatch(batch *loaderBatch) {
+	l.mu.Lock()
+	if l.pending == batch {
+		l.pending = nil
+	}
+	if batch.results != nil || batch.err != nil {
+		l.mu.Unlock()
+		return
+	}
+	batch.results = make(map[string][]map[string]interface{}, len(batch.keys))
+	keys := batch.keys
+	l.mu.Unlock()
+
+	// 批次由多个调用方共享，不使用单个调用方的上下文
+	// 字段由开发者定义，不受字段白名单限制
+	t := l.db.Table(l.table)
+	t.fields = append(t.fields, l.fields...)
+	rows, err := t.whereIn(l.key, keys, false).FindAllWithContext(l.db.GetContext())
+	if err != nil {
+		batch.err = err
+	}
+	for _, row := range rows {
+		k := loaderKey(row[l.key])
+		batch.results[k] = append(batch.results[k], row)
+	}
+	close(batch.done)
+}
+
+// loaderKey 统一键的格式，查询结果中的字段值与传入的键类型可能不同
+func loaderKey(key interface{}) string {
+	return fmt.Sprint(key)
+}
+
+// appendField 追加字段，已存在时不重复追加
+func appendField(fields []string, field string) []string {
+	for _, f := range fields {
+		if f == field {
+			return fields
+		}
+	}
+	return append(fields[:len(fields):len(fields)], field)
+}
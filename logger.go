@@ -32,6 +32,39 @@ type asyncLogger struct {
 	total       atomic.Uint64      // 总处理日志数
 	errCh       chan error         // 错误通道
 	closed      atomic.Bool        // 是否已关闭
+	overflow    *logOverflow       // 通道满时的处理策略，与派生的处理器共享
+	spill       slog.Handler       // 溢出时同步写入的备用处理器，为空时写入 baseHandler
+}
+
+// LogOverflowPolicy 异步日志缓冲通道满时的处理策略
+type LogOverflowPolicy int
+
+const (
+	LogOverflowDrop  LogOverflowPolicy = iota // 丢弃日志（默认）
+	LogOverflowBlock                          // 阻塞等待通道空闲，超时后丢弃
+	LogOverflowSpill                          // 同步写入备用处理器
+)
+
+// String 返回策略名称
+func (p LogOverflowPolicy) String() string {
+	switch p {
+	case LogOverflowDrop:
+		return "drop"
+	case LogOverflowBlock:
+		return "block"
+	case LogOverflowSpill:
+		return "spill"
+	default:
+		return fmt.Sprintf("LogOverflowPolicy(%d)", int(p))
+	}
+}
+
+// logOverflow 异步日志溢出处理配置
+type logOverflow struct {
+	policy  LogOverflowPolicy
+	timeout time.Duration     // 阻塞策略的最长等待时间
+	onDrop  func(slog.Record) // 日志被丢弃时的回调
+	spilled atomic.Uint64     // 同步写入备用处理器的日志数
 }
 
 // rotatingFileHandler 日志文件旋转处理器
@@ -57,6 +90,7 @@ func NewAsyncLogger(h slog.Handler, bufferSize int) *asyncLogger {
 		ctx:         ctx,
 		cancel:      cancel,
 		errCh:       make(chan error, 100), // 增加错误通道
+		overflow:    &logOverflow{},
 	}
 
 	// 启动处理协程
@@ -84,15 +118,63 @@ func (al *asyncLogger) Handle(ctx context.Context, r slog.Record) error {
 	case <-al.ctx.Done():
 		return al.ctx.Err() // 已关闭
 	default:
-		al.dropped.Add(1)
-		// 通道满时记录警告
+	}
+
+	switch al.overflow.policy {
+	case LogOverflowBlock:
+		timer := time.NewTimer(al.overflow.timeout)
+		defer timer.Stop()
 		select {
-		case al.errCh <- fmt.Errorf("日志通道已满，丢弃日志记录"):
-		default:
-			// 错误通道也满了，直接忽略
+		case al.ch <- r:
+			al.total.Add(1)
+			return nil
+		case <-al.ctx.Done():
+			return al.ctx.Err()
+		case <-timer.C:
+		}
+	case LogOverflowSpill:
+		h := al.spill
+		if h == nil {
+			h = al.baseHandler
 		}
+		if !h.Enabled(ctx, r.Level) {
+			return nil
+		}
+		al.overflow.spilled.Add(1)
+		return h.Handle(ctx, r)
+	}
+	al.drop(r)
+	return nil
+}
+
+// drop 丢弃日志记录，计数并通知 OnLogDrop 回调
+func (al *asyncLogger) drop(r slog.Record) {
+	al.dropped.Add(1)
+	// 通道满时记录警告
+	select {
+	case al.errCh <- fmt.Errorf("日志通道已满，丢弃日志记录"):
+	default:
+		// 错误通道也满了，直接忽略
+	}
+	if al.overflow.onDrop != nil {
+		al.overflow.onDrop(r)
+	}
+}
+
+// setOverflow 设置通道满时的处理策略，须在日志处理器使用前调用
+func (al *asyncLogger) setOverflow(policy LogOverflowPolicy, timeout time.Duration, spill slog.Handler, onDrop func(slog.Record)) {
+	al.overflow.policy = policy
+	al.overflow.timeout = timeout
+	al.overflow.onDrop = onDrop
+	al.spill = spill
+}
+
+// deriveSpill 为派生的处理器生成备用处理器
+func (al *asyncLogger) deriveSpill(derive func(slog.Handler) slog.Handler) slog.Handler {
+	if al.spill == nil {
 		return nil
 	}
+	return derive(al.spill)
 }
 
 // WithAttrs 实现 slog.Handler 接口
@@ -103,6 +185,8 @@ func (al *asyncLogger) WithAttrs(attrs []slog.Attr) slog.Handler {
 		wg:          al.wg,
 		ctx:         al.ctx,
 		cancel:      al.cancel,
+		overflow:    al.overflow,
+		spill:       al.deriveSpill(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) }),
 	}
 }
 
@@ -114,6 +198,8 @@ func (al *asyncLogger) WithGroup(name string) slog.Handler {
 		wg:          al.wg,
 		ctx:         al.ctx,
 		cancel:      al.cancel,
+		overflow:    al.overflow,
+		spill:       al.deriveSpill(func(h slog.Handler) slog.Handler { return h.WithGroup(name) }),
 	}
 }
 
@@ -125,35 +211,13 @@ func (al *asyncLogger) Close() error {
 		return errors.New("日志处理器已关闭")
 	}
 
-	close(al.ch) // 关闭通道
-	al.cancel()  // 关闭上下文，触发 process() 退出
+	// 不关闭通道：阻塞等待中的写入方通过上下文退出，避免向已关闭的通道发送；剩余日志由 process 退出前处理
+	al.cancel()
 
-	// 创建带超时的等待通道
-	done := make(chan struct{}, 1)
+	done := make(chan struct{})
 	go func() {
-		// 设置处理剩余日志的总超时时间
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer cancel()
-
-		defer func() {
-			al.wg.Wait()
-			done <- struct{}{}
-			close(done)
-		}()
-
-		// 非阻塞地处理剩余日志
-		for {
-			select {
-			case r, ok := <-al.ch:
-				if !ok {
-					return
-				}
-				// 尝试处理剩余日志，但要受超时控制
-				_ = al.baseHandler.Handle(ctx, r)
-			default:
-				return
-			}
-		}
+		al.wg.Wait()
+		close(done)
 	}()
 
 	// 等待处理或超时
@@ -171,6 +235,11 @@ func (al *asyncLogger) GetDroppedLogsCount() uint64 {
 	return al.dropped.Load()
 }
 
+// GetSpilledLogsCount 获取通道满时同步写入备用处理器的日志数量
+func (al *asyncLogger) GetSpilledLogsCount() uint64 {
+	return al.overflow.spilled.Load()
+}
+
 // GetTotalLogsCount 获取总处理日志数量
 func (al *asyncLogger) GetTotalLogsCount() uint64 {
 	return al.total.Load()
@@ -181,6 +250,7 @@ func (al *asyncLogger) GetLogMetrics() map[string]uint64 {
 	return map[string]uint64{
 		"total_logs":    al.total.Load(),
 		"dropped_logs":  al.dropped.Load(),
+		"spilled_logs":  al.overflow.spilled.Load(),
 		"channel_depth": uint64(len(al.ch)),
 	}
 }
@@ -222,10 +292,7 @@ func (al *asyncLogger) process() {
 
 	for {
 		select {
-		case r, ok := <-al.ch:
-			if !ok {
-				return
-			}
+		case r := <-al.ch:
 			// 调试：打印完整的日志记录信息
 			// log.Printf("接收到日志记录: Message='%s', Level=%v", r.Message, r.Level)
 			// 统一处理日志和超时
@@ -240,7 +307,22 @@ func (al *asyncLogger) process() {
 			cancel()
 
 		case <-al.ctx.Done():
-			// 上下文取消，退出
+			// 上下文取消，处理剩余日志后退出
+			al.drain()
+			return
+		}
+	}
+}
+
+// drain 非阻塞地处理通道中剩余的日志，总耗时不超过3秒
+func (al *asyncLogger) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	for ctx.Err() == nil {
+		select {
+		case r := <-al.ch:
+			_ = al.baseHandler.Handle(ctx, r)
+		default:
 			return
 		}
 	}
@@ -0,0 +1,102 @@
+package xlorm
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+)
+
+// sampledLogLevels 支持采样的日志级别，错误级别始终记录
+var sampledLogLevels = []string{"debug", "info", "warn"}
+
+// logSampler 按级别采样日志，每 N 条记录保留 1 条
+type logSampler struct {
+	rates   [3]atomic.Int64  // debug、info、warn 的采样间隔，不大于1时不采样
+	seq     [3]atomic.Uint64 // 各级别的记录序号
+	sampled atomic.Uint64    // 因采样未记录的日志数
+}
+
+// newLogSampler 按配置创建日志采样器，配置须已校验
+func newLogSampler(rates map[string]int) *logSampler {
+	s := &logSampler{}
+	for level, n := range rates {
+		l, _ := parseLogLevel(level)
+		if i := sampleIndex(l); i >= 0 {
+			s.rates[i].Store(int64(n))
+		}
+	}
+	return s
+}
+
+// sampleIndex 返回级别对应的采样槽位，错误及以上级别返回 -1
+func sampleIndex(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return 0
+	case level < slog.LevelWarn:
+		return 1
+	case level < slog.LevelError:
+		return 2
+	}
+	return -1
+}
+
+// keep 判断该级别的记录是否保留
+func (s *logSampler) keep(level slog.Level) bool {
+	i := sampleIndex(level)
+	if i < 0 {
+		return true
+	}
+	n := s.rates[i].Load()
+	if n <= 1 {
+		return true
+	}
+	if (s.seq[i].Add(1)-1)%uint64(n) == 0 {
+		return true
+	}
+	s.sampled.Add(1)
+	return false
+}
+
+// validateLogSampling 校验日志采样配置
+func validateLogSampling(rates map[string]int) error {
+	for level, n := range rates {
+		l, err := parseLogLevel(level)
+		if err != nil {
+			return err
+		}
+		if sampleIndex(l) < 0 {
+			return errors.New("错误级别日志不支持采样")
+		}
+		if n < 0 {
+			return fmt.Errorf("日志采样间隔不能为负数: %s=%d", level, n)
+		}
+	}
+	return nil
+}
+
+// SetLogSampling 动态调整日志采样，该级别每 n 条日志只记录1条，n 不大于1时关闭采样；
+// 错误级别始终记录，上下文启用了查询日志（WithQueryLogging）的记录不采样
+func (db *DB) SetLogSampling(level string, n int) error {
+	if err := validateLogSampling(map[string]int{level: n}); err != nil {
+		return err
+	}
+	l, _ := parseLogLevel(level)
+	db.logSampler.rates[sampleIndex(l)].Store(int64(n))
+	return nil
+}
+
+// GetLogSampling 获取各级别当前的日志采样间隔
+func (db *DB) GetLogSampling() map[string]int {
+	rates := make(map[string]int, len(sampledLogLevels))
+	for i, level := range sampledLogLevels {
+		rates[level] = max(int(db.logSampler.rates[i].Load()), 1)
+	}
+	return rates
+}
+
+// GetSampledLogsCount 获取因采样未记录的日志数量
+func (db *DB) GetSampledLogsCount() uint64 {
+	return db.logSampler.sampled.Load()
+}
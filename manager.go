@@ -0,0 +1,170 @@
+package xlorm
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+)
+
+// Manager 多数据库管理器，按名称注册和获取数据库实例，统一关闭并汇总指标，例如：
+//
+//	m := xlorm.NewManager()
+//	m.Register("orders", ordersCfg)
+//	m.Register("users", usersCfg)
+//	defer m.CloseAll()
+//	items, err := m.Use("orders").M("items").Where("order_id = ?", id).FindAll()
+type Manager struct {
+	mu     sync.RWMutex
+	dbs    map[string]*DB
+	logger *slog.Logger // 共享的日志实例
+}
+
+// NewManager 创建多数据库管理器
+func NewManager() *Manager {
+	return &Manager{dbs: make(map[string]*DB)}
+}
+
+// SetLogger 设置共享的日志实例，之后通过 Register 创建且未设置 Config.Logger 的数据库使用该日志，
+// 日志中带有 db 属性区分数据库；为 nil 时各数据库按自己的日志配置创建日志
+func (m *Manager) SetLogger(logger *slog.Logger) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logger = logger
+	return m
+}
+
+// Register 按配置创建数据库连接并以 name 注册，Config.DBName 为空时使用 name
+func (m *Manager) Register(name string, cfg *Config) (*DB, error) {
+	if name == "" {
+		return nil, errors.New("数据库名称不能为空")
+	}
+	if cfg == nil {
+		return nil, errors.New("配置不能为空")
+	}
+	m.mu.RLock()
+	_, exists := m.dbs[name]
+	logger := m.logger
+	m.mu.RUnlock()
+	if exists {
+		return nil, fmt.Errorf("数据库 %s 已注册", name)
+	}
+
+	c := *cfg
+	if c.DBName == "" {
+		c.DBName = name
+	}
+	if c.Logger == nil && logger != nil {
+		c.Logger = logger.With("db", name)
+	}
+	db, err := New(&c)
+	if err != nil {
+		return nil, fmt.Errorf("创建数据库 %s 失败: %v", name, err)
+	}
+	if err := m.Add(name, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Add 以 name 注册已创建的数据库实例，之后由 Manager 负责关闭
+func (m *Manager) Add(name string, db *DB) error {
+	if name == "" {
+		return errors.New("数据库名称不能为空")
+	}
+	if db == nil {
+		return errors.New("数据库连接为空")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.dbs[name]; ok {
+		return fmt.Errorf("数据库 %s 已注册", name)
+	}
+	m.dbs[name] = db
+	return nil
+}
+
+// Get 获取已注册的数据库实例
+func (m *Manager) Get(name string) (*DB, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	db, ok := m.dbs[name]
+	if !ok {
+		return nil, fmt.Errorf("数据库 %s 未注册", name)
+	}
+	return db, nil
+}
+
+// Use 获取已注册的数据库实例，用于链式调用，未注册时 panic；名称来自外部输入时应使用 Get
+func (m *Manager) Use(name string) *DB {
+	db, err := m.Get(name)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// Names 获取已注册的数据库名称，按名称排序
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.dbs))
+	for name := range m.dbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Remove 注销并关闭数据库实例
+func (m *Manager) Remove(name string) error {
+	m.mu.Lock()
+	db, ok := m.dbs[name]
+	delete(m.dbs, name)
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("数据库 %s 未注册", name)
+	}
+	return db.Close()
+}
+
+// CloseAll 关闭并注销全部数据库实例，返回所有关闭失败的错误
+func (m *Manager) CloseAll() error {
+	m.mu.Lock()
+	dbs := m.dbs
+	m.dbs = make(map[string]*DB)
+	m.mu.Unlock()
+
+	var errs []error
+	for name, db := range dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭数据库 %s 失败: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Metrics 汇总全部数据库的性能指标：databases 为各数据库的指标，其余数值指标为各数据库之和
+func (m *Manager) Metrics() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	databases := make(map[string]interface{}, len(m.dbs))
+	metrics := map[string]interface{}{"databases": databases}
+	for name, db := range m.dbs {
+		dbMetrics := db.DBMetrics()
+		if dbMetrics == nil {
+			continue
+		}
+		stats := dbMetrics.GetDBMetrics()
+		databases[name] = stats
+		for key, value := range stats {
+			if n, ok := value.(int64); ok {
+				total, _ := metrics[key].(int64)
+				metrics[key] = total + n
+			}
+		}
+	}
+	return metrics
+}
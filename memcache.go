@@ -0,0 +1,279 @@
+package xlorm
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMemoryCacheMaxEntries = 10000 // 默认最大缓存条目数
+)
+
+// lruEntry LRU 缓存条目
+type lruEntry[K comparable, V any] struct {
+	key      K
+	value    V
+	size     int64
+	expireAt int64 // 过期时间（UnixNano），0 表示不过期
+}
+
+// lruCache 支持 TTL、最大条目数和容量限制的泛型 LRU 缓存，并发安全
+type lruCache[K comparable, V any] struct {
+	mu         sync.Mutex
+	ll         *list.List
+	items      map[K]*list.Element
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	sizeFn     func(V) int64
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+	expired   atomic.Uint64
+}
+
+// newLRUCache 创建 LRU 缓存，maxEntries、maxBytes 为0表示不限制，sizeFn 为 nil 时不统计容量
+func newLRUCache[K comparable, V any](maxEntries int, maxBytes int64, sizeFn func(V) int64) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		ll:         list.New(),
+		items:      make(map[K]*list.Element),
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		sizeFn:     sizeFn,
+	}
+}
+
+// get 获取缓存，过期条目会被删除
+func (c *lruCache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var zero V
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		return zero, false
+	}
+	entry := elem.Value.(*lruEntry[K, V])
+	if entry.expireAt > 0 && time.Now().UnixNano() > entry.expireAt {
+		c.removeElement(elem)
+		c.expired.Add(1)
+		c.misses.Add(1)
+		return zero, false
+	}
+	c.ll.MoveToFront(elem)
+	c.hits.Add(1)
+	return entry.value, true
+}
+
+// set 设置缓存，ttl 为0时不过期；超出限制时淘汰最久未使用的条目
+func (c *lruCache[K, V]) set(key K, value V, ttl time.Duration) {
+	var size int64
+	if c.sizeFn != nil {
+		size = c.sizeFn(value)
+	}
+	var expireAt int64
+	if ttl > 0 {
+		expireAt = time.Now().Add(ttl).UnixNano()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*lruEntry[K, V])
+		c.bytes += size - entry.size
+		entry.value, entry.size, entry.expireAt = value, size, expireAt
+		c.ll.MoveToFront(elem)
+	} else {
+		c.items[key] = c.ll.PushFront(&lruEntry[K, V]{key: key, value: value, size: size, expireAt: expireAt})
+		c.bytes += size
+	}
+
+	for c.ll.Len() > 1 && ((c.maxEntries > 0 && c.ll.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes)) {
+		c.removeElement(c.ll.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// remove 删除缓存
+func (c *lruCache[K, V]) remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// purgeExpired 删除所有过期条目，返回删除数量
+func (c *lruCache[K, V]) purgeExpired() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now().UnixNano()
+	removed := 0
+	for elem := c.ll.Back(); elem != nil; {
+		prev := elem.Prev()
+		if entry := elem.Value.(*lruEntry[K, V]); entry.expireAt > 0 && now > entry.expireAt {
+			c.removeElement(elem)
+			removed++
+		}
+		elem = prev
+	}
+	c.expired.Add(uint64(removed))
+	return removed
+}
+
+// clear 清空缓存
+func (c *lruCache[K, V]) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = make(map[K]*list.Element)
+	c.bytes = 0
+}
+
+// removeElement 删除条目，调用方需持有锁
+func (c *lruCache[K, V]) removeElement(elem *list.Element) {
+	entry := c.ll.Remove(elem).(*lruEntry[K, V])
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+}
+
+// MemoryCacheOptions 内存缓存选项
+type MemoryCacheOptions struct {
+	MaxEntries int                           // 最大条目数，默认10000，小于0表示不限制
+	MaxBytes   int64                         // 最大占用字节数（估算），0表示不限制
+	SizeFunc   func(value interface{}) int64 // 自定义值大小估算函数，默认按值的类型递归估算
+}
+
+// memoryCache 进程内 LRU + TTL 缓存，实现 Cache 接口
+type memoryCache struct {
+	lru *lruCache[string, interface{}]
+}
+
+// NewMemoryCache 创建进程内缓存，无需外部依赖即可配合 WithCache 使用
+// 例如：
+//
+//	cache := xlorm.NewMemoryCache(xlorm.MemoryCacheOptions{MaxEntries: 5000, MaxBytes: 64 << 20})
+//	users, err := db.WithCache(cache, "users:active", time.Minute, func() (interface{}, error) { ... })
+func NewMemoryCache(opts MemoryCacheOptions) *memoryCache {
+	if opts.MaxEntries == 0 {
+		opts.MaxEntries = defaultMemoryCacheMaxEntries
+	} else if opts.MaxEntries < 0 {
+		opts.MaxEntries = 0
+	}
+	sizeFn := opts.SizeFunc
+	if sizeFn == nil && opts.MaxBytes > 0 {
+		sizeFn = estimateSize
+	}
+	return &memoryCache{lru: newLRUCache[string, interface{}](opts.MaxEntries, opts.MaxBytes, sizeFn)}
+}
+
+// Get 获取缓存
+func (c *memoryCache) Get(key string) (interface{}, bool) {
+	return c.lru.get(key)
+}
+
+// Set 设置缓存，expiration 为0时不过期
+func (c *memoryCache) Set(key string, value interface{}, expiration time.Duration) error {
+	c.lru.set(key, value, expiration)
+	return nil
+}
+
+// Delete 删除缓存
+func (c *memoryCache) Delete(key string) error {
+	c.lru.remove(key)
+	return nil
+}
+
+// DeleteMulti 批量删除缓存
+func (c *memoryCache) DeleteMulti(keys ...string) error {
+	for _, key := range keys {
+		c.lru.remove(key)
+	}
+	return nil
+}
+
+// PurgeExpired 删除所有过期条目，返回删除数量；过期条目在读取时也会被删除
+func (c *memoryCache) PurgeExpired() int {
+	return c.lru.purgeExpired()
+}
+
+// Clear 清空缓存
+func (c *memoryCache) Clear() {
+	c.lru.clear()
+}
+
+// Stats 获取缓存统计信息
+func (c *memoryCache) Stats() map[string]uint64 {
+	c.lru.mu.Lock()
+	entries, bytes := c.lru.ll.Len(), c.lru.bytes
+	c.lru.mu.Unlock()
+	return map[string]uint64{
+		"entries":   uint64(entries),
+		"bytes":     uint64(bytes),
+		"hits":      c.lru.hits.Load(),
+		"misses":    c.lru.misses.Load(),
+		"evictions": c.lru.evictions.Load(),
+		"expired":   c.lru.expired.Load(),
+	}
+}
+
+// estimateSize 估算值占用的字节数，用于容量限制，非精确值
+func estimateSize(value interface{}) int64 {
+	return estimateValueSize(reflect.ValueOf(value), 0)
+}
+
+// estimateValueSize 递归估算值大小，限制递归深度避免循环引用
+func estimateValueSize(v reflect.Value, depth int) int64 {
+	if !v.IsValid() {
+		return 0
+	}
+	if depth > 8 {
+		return int64(v.Type().Size())
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Type().Size()) + int64(v.Len())
+	case reflect.Slice:
+		size := int64(v.Type().Size())
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return size + int64(v.Len())
+		}
+		for i := 0; i < v.Len(); i++ {
+			size += estimateValueSize(v.Index(i), depth+1)
+		}
+		return size
+	case reflect.Array:
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += estimateValueSize(v.Index(i), depth+1)
+		}
+		return size
+	case reflect.Map:
+		size := int64(v.Type().Size())
+		iter := v.MapRange()
+		for iter.Next() {
+			size += estimateValueSize(iter.Key(), depth+1) + estimateValueSize(iter.Value(), depth+1)
+		}
+		return size
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return int64(v.Type().Size())
+		}
+		return int64(v.Type().Size()) + estimateValueSize(v.Elem(), depth+1)
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += estimateValueSize(v.Field(i), depth+1)
+		}
+		if size == 0 {
+			size = int64(v.Type().Size())
+		}
+		return size
+	default:
+		return int64(v.Type().Size())
+	}
+}
@@ -8,12 +8,37 @@ import (
 
 // dbMetrics 性能指标结构体
 type dbMetrics struct {
-	dbname         string
-	queryDurations sync.Map
-	affectedRows   atomic.Int64
-	totalQueries   atomic.Int64
-	slowQueries    atomic.Int64
-	errors         atomic.Int64
+	dbname          string
+	queryDurations  sync.Map
+	affectedRows    atomic.Int64
+	totalQueries    atomic.Int64
+	slowQueries     atomic.Int64
+	errors          atomic.Int64
+	autoIncWarns    atomic.Int64      // 自增ID告警次数
+	killedQueries   atomic.Int64      // 被终止的长查询数量
+	anomalies       atomic.Int64      // 查询异常告警次数
+	cacheHits       atomic.Int64      // 查询缓存命中次数
+	cacheMisses     atomic.Int64      // 查询缓存未命中次数
+	panics          atomic.Int64      // 已恢复的 panic 次数
+	connAgeLatency  *latencyHistogram // 按连接存活时长分组的语句延迟
+	connIdleLatency *latencyHistogram // 按连接执行前空闲时长分组的语句延迟
+	poolEvents      [4]atomic.Int64   // 各类连接池事件次数，下标为 PoolEventKind
+	tableStats      sync.Map          // 按表和操作类型分组的统计，键为 tableOpKey，值为 *tableOpStats
+}
+
+// tableOpKey 按表和操作类型分组的统计键
+type tableOpKey struct {
+	table     string
+	operation string
+}
+
+// tableOpStats 单个表单个操作类型的统计
+type tableOpStats struct {
+	count     atomic.Int64
+	errors    atomic.Int64
+	slow      atomic.Int64
+	totalTime atomic.Int64 // 累计耗时（纳秒）
+	maxTime   atomic.Int64 // 最大耗时（纳秒）
 }
 
 // asyncDBMetrics 异步性能指标结构体
@@ -22,7 +47,9 @@ type asyncDBMetrics struct {
 	stopChan chan struct{}
 	wg       sync.WaitGroup
 	*dbMetrics
-	droppedMetrics atomic.Uint64 //丢弃的指标数量
+	droppedMetrics atomic.Uint64                 //丢弃的指标数量
+	sinks          atomic.Pointer[[]MetricsSink] // 指标输出，写时复制
+	sinksMu        sync.Mutex
 }
 
 // ringBuffer 线程安全的环形缓冲区
@@ -79,7 +106,11 @@ func (rb *ringBuffer) Dequeue() (func(*dbMetrics), bool) {
 
 // newMetrics 创建新的性能指标实例
 func newDBMetrics(dbname string) *dbMetrics {
-	return &dbMetrics{dbname: dbname}
+	return &dbMetrics{
+		dbname:          dbname,
+		connAgeLatency:  newLatencyHistogram(connAgeBounds, connAgeLabels),
+		connIdleLatency: newLatencyHistogram(connIdleBounds, connIdleLabels),
+	}
 }
 
 // newAsyncMetrics 创建新的异步性能指标实例
@@ -122,10 +153,53 @@ func (m *dbMetrics) GetDBMetrics() map[string]interface{} {
 	metrics["total_queries"] = m.totalQueries.Load()
 	metrics["slow_queries"] = m.slowQueries.Load()
 	metrics["total_errors"] = m.errors.Load()
+	metrics["auto_increment_warnings"] = m.autoIncWarns.Load()
+	metrics["killed_queries"] = m.killedQueries.Load()
+	metrics["anomalies"] = m.anomalies.Load()
+	metrics["cache_hits"] = m.cacheHits.Load()
+	metrics["cache_misses"] = m.cacheMisses.Load()
+	metrics["recovered_panics"] = m.panics.Load()
+	metrics["conn_age_latency"] = m.connAgeLatency.snapshot()
+	metrics["conn_idle_latency"] = m.connIdleLatency.snapshot()
+	poolEvents := make(map[string]int64, len(m.poolEvents))
+	for kind := range m.poolEvents {
+		poolEvents[PoolEventKind(kind).String()] = m.poolEvents[kind].Load()
+	}
+	metrics["pool_events"] = poolEvents
 
 	return metrics
 }
 
+// GetDBMetricsByTable 获取按表和操作类型分组的统计，结构为 表名 -> 操作类型 -> 统计项，
+// 用于定位慢查询和错误集中的表；直接执行的SQL表名为空字符串
+func (m *dbMetrics) GetDBMetricsByTable() map[string]interface{} {
+	tables := make(map[string]interface{})
+	m.tableStats.Range(func(key, value interface{}) bool {
+		k, stats := key.(tableOpKey), value.(*tableOpStats)
+		ops, ok := tables[k.table].(map[string]interface{})
+		if !ok {
+			ops = make(map[string]interface{})
+			tables[k.table] = ops
+		}
+		count, errs, total := stats.count.Load(), stats.errors.Load(), time.Duration(stats.totalTime.Load())
+		var average time.Duration
+		if count > 0 {
+			average = total / time.Duration(count)
+		}
+		ops[k.operation] = map[string]interface{}{
+			"count":        count,
+			"errors":       errs,
+			"success":      count - errs,
+			"slow_queries": stats.slow.Load(),
+			"total_time":   total,
+			"average_time": average,
+			"max_time":     time.Duration(stats.maxTime.Load()),
+		}
+		return true
+	})
+	return tables
+}
+
 // ResetDBMetrics 重置性能指标
 func (m *dbMetrics) ResetDBMetrics() {
 	m.queryDurations = sync.Map{}
@@ -133,6 +207,18 @@ func (m *dbMetrics) ResetDBMetrics() {
 	m.totalQueries.Store(0)
 	m.slowQueries.Store(0)
 	m.errors.Store(0)
+	m.autoIncWarns.Store(0)
+	m.killedQueries.Store(0)
+	m.anomalies.Store(0)
+	m.cacheHits.Store(0)
+	m.cacheMisses.Store(0)
+	m.panics.Store(0)
+	m.connAgeLatency.reset()
+	m.connIdleLatency.reset()
+	for kind := range m.poolEvents {
+		m.poolEvents[kind].Store(0)
+	}
+	m.tableStats.Clear()
 }
 
 // RecordQueryDuration 记录查询耗时
@@ -150,6 +236,33 @@ func (m *dbMetrics) RecordQueryDuration(queryType string, duration time.Duration
 	}
 }
 
+// RecordTableQuery 按表和操作类型记录一次SQL执行
+func (m *dbMetrics) RecordTableQuery(table, operation string, duration time.Duration, failed, slow bool) {
+	if operation == "" {
+		operation = "unknown"
+	}
+	key := tableOpKey{table: table, operation: operation}
+	value, ok := m.tableStats.Load(key)
+	if !ok {
+		value, _ = m.tableStats.LoadOrStore(key, &tableOpStats{})
+	}
+	stats := value.(*tableOpStats)
+	stats.count.Add(1)
+	if failed {
+		stats.errors.Add(1)
+	}
+	if slow {
+		stats.slow.Add(1)
+	}
+	stats.totalTime.Add(int64(duration))
+	for {
+		cur := stats.maxTime.Load()
+		if int64(duration) <= cur || stats.maxTime.CompareAndSwap(cur, int64(duration)) {
+			break
+		}
+	}
+}
+
 // RecordAffectedRows 记录影响的行数
 func (m *dbMetrics) RecordAffectedRows(rows int64) {
 	m.affectedRows.Add(rows)
@@ -165,6 +278,49 @@ func (m *dbMetrics) RecordSlowQuery() {
 	m.slowQueries.Add(1)
 }
 
+// RecordAutoIncrementWarning 记录自增ID告警
+func (m *dbMetrics) RecordAutoIncrementWarning() {
+	m.autoIncWarns.Add(1)
+}
+
+// RecordQueryKilled 记录被终止的长查询
+func (m *dbMetrics) RecordQueryKilled() {
+	m.killedQueries.Add(1)
+}
+
+// RecordAnomaly 记录查询异常告警
+func (m *dbMetrics) RecordAnomaly() {
+	m.anomalies.Add(1)
+}
+
+// RecordCacheHit 记录查询缓存命中
+func (m *dbMetrics) RecordCacheHit() {
+	m.cacheHits.Add(1)
+}
+
+// RecordCacheMiss 记录查询缓存未命中
+func (m *dbMetrics) RecordCacheMiss() {
+	m.cacheMisses.Add(1)
+}
+
+// RecordPanic 记录已恢复的 panic
+func (m *dbMetrics) RecordPanic() {
+	m.panics.Add(1)
+}
+
+// RecordConnLatency 记录语句延迟及执行时连接的存活时长和空闲时长
+func (m *dbMetrics) RecordConnLatency(age, idle, latency time.Duration) {
+	m.connAgeLatency.record(age, latency)
+	m.connIdleLatency.record(idle, latency)
+}
+
+// RecordPoolEvent 记录连接池事件
+func (m *dbMetrics) RecordPoolEvent(kind PoolEventKind) {
+	if int(kind) >= 0 && int(kind) < len(m.poolEvents) {
+		m.poolEvents[kind].Add(1)
+	}
+}
+
 func (am *asyncDBMetrics) start() {
 	am.wg.Add(1)
 	go func() {
@@ -204,6 +360,15 @@ func (am *asyncDBMetrics) recordMetric(metricFunc func(*dbMetrics)) {
 func (am *asyncDBMetrics) RecordQueryDuration(queryType string, duration time.Duration) {
 	am.recordMetric(func(m *dbMetrics) {
 		m.RecordQueryDuration(queryType, duration)
+		am.emitTiming("query.duration", duration, "query_type", queryType)
+	})
+}
+
+// RecordTableQuery 按表和操作类型记录一次SQL执行
+func (am *asyncDBMetrics) RecordTableQuery(table, operation string, duration time.Duration, failed, slow bool) {
+	am.recordMetric(func(m *dbMetrics) {
+		m.RecordTableQuery(table, operation, duration, failed, slow)
+		am.emitTiming("table.query.duration", duration, "table", table, "operation", operation, "status", queryStatus(failed, slow))
 	})
 }
 
@@ -211,6 +376,7 @@ func (am *asyncDBMetrics) RecordQueryDuration(queryType string, duration time.Du
 func (am *asyncDBMetrics) RecordError() {
 	am.recordMetric(func(m *dbMetrics) {
 		m.RecordError()
+		am.emitCount("query.errors", 1)
 	})
 }
 
@@ -218,6 +384,71 @@ func (am *asyncDBMetrics) RecordError() {
 func (am *asyncDBMetrics) RecordSlowQuery() {
 	am.recordMetric(func(m *dbMetrics) {
 		m.RecordSlowQuery()
+		am.emitCount("query.slow", 1)
+	})
+}
+
+// RecordAutoIncrementWarning 记录自增ID告警
+func (am *asyncDBMetrics) RecordAutoIncrementWarning() {
+	am.recordMetric(func(m *dbMetrics) {
+		m.RecordAutoIncrementWarning()
+		am.emitCount("auto_increment.warnings", 1)
+	})
+}
+
+// RecordQueryKilled 记录被终止的长查询
+func (am *asyncDBMetrics) RecordQueryKilled() {
+	am.recordMetric(func(m *dbMetrics) {
+		m.RecordQueryKilled()
+		am.emitCount("query.killed", 1)
+	})
+}
+
+// RecordAnomaly 记录查询异常告警
+func (am *asyncDBMetrics) RecordAnomaly() {
+	am.recordMetric(func(m *dbMetrics) {
+		m.RecordAnomaly()
+		am.emitCount("anomalies", 1)
+	})
+}
+
+// RecordCacheHit 记录查询缓存命中
+func (am *asyncDBMetrics) RecordCacheHit() {
+	am.recordMetric(func(m *dbMetrics) {
+		m.RecordCacheHit()
+		am.emitCount("cache.hits", 1)
+	})
+}
+
+// RecordCacheMiss 记录查询缓存未命中
+func (am *asyncDBMetrics) RecordCacheMiss() {
+	am.recordMetric(func(m *dbMetrics) {
+		m.RecordCacheMiss()
+		am.emitCount("cache.misses", 1)
+	})
+}
+
+// RecordPanic 记录已恢复的 panic
+func (am *asyncDBMetrics) RecordPanic() {
+	am.recordMetric(func(m *dbMetrics) {
+		m.RecordPanic()
+		am.emitCount("panics", 1)
+	})
+}
+
+// RecordConnLatency 记录语句延迟及执行时连接的存活时长和空闲时长
+func (am *asyncDBMetrics) RecordConnLatency(age, idle, latency time.Duration) {
+	am.recordMetric(func(m *dbMetrics) {
+		m.RecordConnLatency(age, idle, latency)
+		am.emitTiming("conn.latency", latency)
+	})
+}
+
+// RecordPoolEvent 记录连接池事件
+func (am *asyncDBMetrics) RecordPoolEvent(kind PoolEventKind) {
+	am.recordMetric(func(m *dbMetrics) {
+		m.RecordPoolEvent(kind)
+		am.emitCount("pool.events", 1, "kind", kind.String())
 	})
 }
 
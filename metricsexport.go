@@ -0,0 +1,51 @@
+package xlorm
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+)
+
+// MarshalJSON 将性能指标及按表分组的统计序列化为JSON，耗时字段单位为纳秒
+func (m *dbMetrics) MarshalJSON() ([]byte, error) {
+	metrics := m.GetDBMetrics()
+	metrics["tables"] = m.GetDBMetricsByTable()
+	return json.Marshal(metrics)
+}
+
+// metricsSnapshot 获取性能指标快照，包含按表分组的统计、丢弃的指标和事件数量、采样未记录的日志数量及插件统计
+func (db *DB) metricsSnapshot() map[string]interface{} {
+	metrics := map[string]interface{}{}
+	if m := db.DBMetrics(); m != nil {
+		metrics = m.GetDBMetrics()
+		metrics["tables"] = m.GetDBMetricsByTable()
+		metrics["dropped_metrics"] = db.asyncDBMetrics.GetDroppedMetricsCount()
+	}
+	metrics["dropped_events"] = db.DroppedEvents()
+	metrics["sampled_logs"] = db.GetSampledLogsCount()
+	metrics["plugins"] = db.PluginStats()
+	return metrics
+}
+
+// PublishExpvar 将性能指标以 name 发布到 expvar，通过 /debug/vars 读取时实时生成快照
+// expvar 不支持取消发布，name 已存在时返回错误；多个数据库实例需使用不同的 name
+func (db *DB) PublishExpvar(name string) error {
+	if name == "" {
+		return errors.New("expvar 名称不能为空")
+	}
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar %s 已存在", name)
+	}
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return db.metricsSnapshot()
+	}))
+	return nil
+}
+
+// MetricsHandler 返回以JSON输出性能指标的 http.Handler，只接受 GET 请求，
+// 内容与 AdminHandler 的 /metrics 相同，适合单独挂载供简单的采集工具抓取
+func (db *DB) MetricsHandler() http.Handler {
+	return http.HandlerFunc(db.adminMetrics)
+}
@@ -0,0 +1,166 @@
+package xlorm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsSink 指标输出，内存指标之外的每条指标同时转发给已注册的输出，用于接入 StatsD、Datadog 等监控系统
+// 方法在指标处理协程中调用，不阻塞查询；tags 包含 db（数据库名）及指标相关的维度，输出不应修改
+type MetricsSink interface {
+	Timing(name string, value time.Duration, tags map[string]string) // 耗时
+	Count(name string, value int64, tags map[string]string)          // 计数增量
+}
+
+// AddMetricsSink 注册指标输出，注册后记录的指标同时转发给该输出
+func (db *DB) AddMetricsSink(sink MetricsSink) {
+	if sink == nil || db.asyncDBMetrics == nil {
+		return
+	}
+	am := db.asyncDBMetrics
+	am.sinksMu.Lock()
+	defer am.sinksMu.Unlock()
+	var sinks []MetricsSink
+	if current := am.sinks.Load(); current != nil {
+		sinks = append(sinks, *current...)
+	}
+	sinks = append(sinks, sink)
+	am.sinks.Store(&sinks)
+}
+
+// loadSinks 获取已注册的指标输出
+func (am *asyncDBMetrics) loadSinks() []MetricsSink {
+	if sinks := am.sinks.Load(); sinks != nil {
+		return *sinks
+	}
+	return nil
+}
+
+// sinkTags 构建指标标签，kv 为键值对
+func (am *asyncDBMetrics) sinkTags(kv ...string) map[string]string {
+	tags := make(map[string]string, len(kv)/2+1)
+	tags["db"] = am.dbname
+	for i := 0; i+1 < len(kv); i += 2 {
+		tags[kv[i]] = kv[i+1]
+	}
+	return tags
+}
+
+// emitTiming 将耗时转发给指标输出
+func (am *asyncDBMetrics) emitTiming(name string, value time.Duration, kv ...string) {
+	sinks := am.loadSinks()
+	if len(sinks) == 0 {
+		return
+	}
+	tags := am.sinkTags(kv...)
+	for _, sink := range sinks {
+		callSink(func() { sink.Timing(name, value, tags) })
+	}
+}
+
+// emitCount 将计数转发给指标输出
+func (am *asyncDBMetrics) emitCount(name string, value int64, kv ...string) {
+	sinks := am.loadSinks()
+	if len(sinks) == 0 {
+		return
+	}
+	tags := am.sinkTags(kv...)
+	for _, sink := range sinks {
+		callSink(func() { sink.Count(name, value, tags) })
+	}
+}
+
+// queryStatus SQL执行结果，用于指标标签
+func queryStatus(failed, slow bool) string {
+	switch {
+	case failed:
+		return "error"
+	case slow:
+		return "slow"
+	default:
+		return "success"
+	}
+}
+
+// callSink 调用指标输出，panic 不影响其他指标的处理
+func callSink(fn func()) {
+	defer func() {
+		_ = recover()
+	}()
+	fn()
+}
+
+// StatsDSink 通过 UDP 发送指标的 StatsD 输出，发送失败时丢弃指标
+type StatsDSink struct {
+	conn      net.Conn
+	prefix    string
+	dogStatsD bool
+	mu        sync.Mutex
+}
+
+// NewStatsDSink 创建 StatsD 指标输出，addr 为 agent 的 UDP 地址（如 127.0.0.1:8125），prefix 为指标名前缀（如 xlorm.）
+// dogStatsD 为 true 时按 DogStatsD 格式附加标签（|#key:value），用于 Datadog agent；为 false 时忽略标签
+func NewStatsDSink(addr, prefix string, dogStatsD bool) (*StatsDSink, error) {
+	if addr == "" {
+		return nil, errors.New("StatsD 地址不能为空")
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("连接 StatsD 失败: %w", err)
+	}
+	return &StatsDSink{conn: conn, prefix: prefix, dogStatsD: dogStatsD}, nil
+}
+
+// Timing 实现 MetricsSink，以毫秒发送
+func (s *StatsDSink) Timing(name string, value time.Duration, tags map[string]string) {
+	s.send(name, strconv.FormatFloat(float64(value)/float64(time.Millisecond), 'f', -1, 64), "ms", tags)
+}
+
+// Count 实现 MetricsSink
+func (s *StatsDSink) Count(name string, value int64, tags map[string]string) {
+	s.send(name, strconv.FormatInt(value, 10), "c", tags)
+}
+
+// Close 关闭连接
+func (s *StatsDSink) Close() error {
+	return s.conn.Close()
+}
+
+// send 发送一条指标
+func (s *StatsDSink) send(name, value, kind string, tags map[string]string) {
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	b.WriteString(name)
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(kind)
+	if s.dogStatsD && len(tags) > 0 {
+		keys := make([]string, 0, len(tags))
+		for k := range tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString("|#")
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(statsdTagEscaper.Replace(k))
+			b.WriteByte(':')
+			b.WriteString(statsdTagEscaper.Replace(tags[k]))
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.conn.Write([]byte(b.String()))
+}
+
+// statsdTagEscaper 替换标签中与 DogStatsD 格式冲突的字符
+var statsdTagEscaper = strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", "_")
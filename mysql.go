@@ -3,35 +3,34 @@ package xlorm
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
 )
 
 // newMySQL 创建新的MySQL数据库连接
 func newMySQL(cfg *Config) (*DB, error) {
-	// 构建 DSN
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local&timeout=%s&readTimeout=%s&writeTimeout=%s",
-		cfg.Username,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.Database,
-		cfg.Charset,
-		safeTimeout(cfg.ConnTimeout),  // 带最小值的超时
-		safeTimeout(cfg.ReadTimeout),  // 带最小值的读超时
-		safeTimeout(cfg.WriteTimeout), // 带最小值的写超时
-	)
-
-	// 连接数据库
-	db, err := sql.Open("mysql", dsn)
+	// 实例标识，通过连接属性识别本实例发起的连接
+	instanceID := uuid.New().String()
+
+	// 构建驱动配置
+	mysqlCfg, err := buildMySQLConfig(cfg, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %v", err)
+	}
+	// 连接数据库，新连接建立时预编译注册的语句，并按连接存活时长记录语句延迟
+	mysqlConnector, err := mysql.NewConnector(mysqlCfg)
 	if err != nil {
 		return nil, fmt.Errorf("连接数据库失败: %v", err)
 	}
+	connector := &poolConnector{Connector: mysqlConnector, warmer: newStmtWarmer(cfg.WarmStatements)}
+	db := sql.OpenDB(connector)
 
 	// 设置连接池
 	db.SetMaxOpenConns(cfg.MaxOpenConns)
@@ -39,14 +38,46 @@ func newMySQL(cfg *Config) (*DB, error) {
 	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
+	return newDB(cfg, db, connector, instanceID)
+}
+
+// NewFromDB 使用应用已有的 *sql.DB 创建数据库实例，适用于由 ProxySQL、RDS IAM 令牌轮换等方式自行管理连接的场景，
+// 查询构建、指标、日志等功能与 New 创建的实例相同；cfg 中的连接参数（Host、DSN、TLS、连接池大小等）不生效，为 nil 时使用默认配置
+// 连接不经过 xlorm 的连接器，WarmStatements、连接延迟指标、连接池事件及按连接属性识别本实例的长查询终止不可用；
+// Close 不会关闭传入的 *sql.DB，由调用方管理
+func NewFromDB(existing *sql.DB, cfg *Config) (*DB, error) {
+	if existing == nil {
+		return nil, errors.New("数据库连接为空")
+	}
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	if err := cfg.validateOptions(); err != nil {
+		return nil, fmt.Errorf("数据库参数配置有误: %v", err)
+	}
+	cfg.setDefaults()
+	if cfg.Driver != "mysql" {
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", cfg.Driver)
+	}
+	return newDB(cfg, existing, nil, uuid.New().String())
+}
+
+// newDB 基于已打开的连接池创建数据库实例，connector 为 nil 表示连接池由调用方创建和管理
+func newDB(cfg *Config, db *sql.DB, connector *poolConnector, instanceID string) (*DB, error) {
 	// 测试连接
-	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnTimeout)
-	defer cancel()
+	pingCtx, pingCancel := context.WithTimeout(context.Background(), cfg.ConnTimeout)
+	defer pingCancel()
 
-	if err := db.PingContext(ctx); err != nil {
+	if err := db.PingContext(pingCtx); err != nil {
 		return nil, fmt.Errorf("测试数据库连接失败: %v", err)
 	}
 
+	// 外部连接池不经过 xlorm 的连接器，无法预热语句
+	var warmer *stmtWarmer
+	if connector != nil {
+		warmer = connector.warmer
+	}
+
 	logLevelVar := new(slog.LevelVar)
 	logLevel, err := parseLogLevel(cfg.LogLevel)
 	if err != nil {
@@ -54,14 +85,37 @@ func newMySQL(cfg *Config) (*DB, error) {
 	}
 	logLevelVar.Set(logLevel)
 
-	// 创建异步处理器
-	asyncHandler := NewAsyncLogger(NewRotatingFileHandler(
-		cfg.LogDir,
-		"db",
-		time.Duration(cfg.LogRotationMaxAge)*24*time.Hour,
-		logLevelVar,
-		cfg.LogRotationEnabled,
-	).handler, cfg.LogBufferSize)
+	// 创建异步处理器，使用外部日志实例时不创建；设置了日志处理器或输出时不创建日志文件
+	logger := cfg.Logger
+	if logger == nil {
+		var handler slog.Handler
+		switch {
+		case cfg.LogHandler != nil:
+			handler = cfg.LogHandler
+		case cfg.LogOutput != nil:
+			handler = slog.NewJSONHandler(cfg.LogOutput, &slog.HandlerOptions{Level: logLevelVar})
+		default:
+			handler = NewRotatingFileHandler(
+				cfg.LogDir,
+				"db",
+				time.Duration(cfg.LogRotationMaxAge)*24*time.Hour,
+				logLevelVar,
+				cfg.LogRotationEnabled,
+			).handler
+		}
+		al := NewAsyncLogger(handler, cfg.LogBufferSize)
+		al.setOverflow(cfg.LogOverflowPolicy, cfg.LogBlockTimeout, cfg.LogSpillHandler, cfg.OnLogDrop)
+		logger = slog.New(al)
+	}
+	// 带上下文的日志附加追踪ID及请求关联属性，并按级别采样
+	sampler := newLogSampler(cfg.LogSampling)
+	logger = slog.New(newContextLogHandler(logger.Handler(), cfg.LogAttrsExtractor, sampler))
+	if warmer != nil {
+		warmer.logger.Store(logger)
+	}
+
+	// 后台任务使用的上下文，Close 时取消
+	ctx, cancel := context.WithCancel(context.Background())
 
 	// 创建 DB 实例
 	xdb := &DB{
@@ -71,29 +125,157 @@ func newMySQL(cfg *Config) (*DB, error) {
 		dbName:             cfg.DBName,
 		DB:                 db,
 		tablePre:           cfg.TablePrefix,
+		tableSchema:        cfg.TableSchema,
+		tablePreResolver:   cfg.TablePrefixResolver,
 		asyncDBMetrics:     newAsyncDBMetrics(cfg.DBName, cfg.DBMetricsBufferSize),
 		structFieldsCache:  newShardedCache(),
 		placeholderCache:   newShardedCache(),
 		StructMapper:       NewStructMapper(),
-		logger:             slog.New(asyncHandler),
+		logger:             logger,
 		logLevelVar:        logLevelVar,
+		logSampler:         sampler,
 		startTime:          time.Now(),
-		poolStatsStop:      make(chan struct{}),
 		poolStatsInterval:  cfg.PoolStatsInterval,
 		poolStatsMutex:     new(sync.Mutex), // 互斥锁保护
-		poolStatsTicker:    nil,             // 统计定时器
 		slowQueryThreshold: cfg.SlowQueryTime,
-		debug:              cfg.Debug,
+
+		autoIncrementWarnRatio: cfg.AutoIncrementWarnRatio,
+		onAutoIncrementWarning: cfg.OnAutoIncrementWarning,
+		instanceID:             instanceID,
+		queryKillerAllowlist:   cfg.QueryKillerAllowlist,
+		hooks:                  newHookRegistry(),
+		scopes:                 newScopeRegistry(),
+		events:                 make(chan QueryEvent, cfg.EventBufferSize),
+		eventsMu:               new(sync.RWMutex),
+		onAnomaly:              cfg.OnAnomaly,
+		profileSlowQueries:     cfg.ProfileSlowQueries,
+		profileDir:             cfg.ProfileDir,
+		profileMinInterval:     cfg.ProfileMinInterval,
+		queryCache:             newQueryCacheState(cfg.QueryCache),
+		namedQueries:           newNamedQueryRegistry(),
+		plugins:                newPluginRegistry(),
+		config:                 *cfg,
+		slowLog:                newSlowLog(cfg.SlowLogSize),
+		costGuard:              newCostGuard(cfg),
+		tasks:                  newTaskRegistry(),
+		dryRunCapture:          &SQLCapture{},
+		stmtWarmer:             warmer,
+		poolHistory:            newPoolStatsHistory(cfg.PoolStatsHistorySize),
+		poolAlerts:             newPoolAlertState(),
+		externalDB:             connector == nil,
+	}
+	xdb.debug.Store(cfg.Debug)
+	xdb.dryRun.Store(cfg.DryRun)
+	xdb.StructMapper.SetStrict(cfg.StrictScan)
+	if connector != nil {
+		connector.db.Store(xdb)
+	}
+	if warmer == nil && len(cfg.WarmStatements) > 0 {
+		xdb.logger.Warn("外部连接池不支持语句预热，已忽略 WarmStatements 配置")
+	}
+
+	// 检测服务端版本，用于判断功能支持情况
+	if info, err := xdb.detectServerInfo(pingCtx); err != nil {
+		xdb.logger.Warn("检测数据库版本失败，不限制依赖版本的功能", "error", err)
+	} else {
+		xdb.logger.Info("数据库版本", "flavor", info.Flavor.String(), "version", info.Version)
+	}
+
+	// 加载并校验命名查询
+	for _, pattern := range cfg.NamedQueryFiles {
+		if err := xdb.LoadNamedQueries(pattern); err != nil {
+			xdb.Close()
+			return nil, err
+		}
+	}
+	if len(cfg.NamedQueryFiles) > 0 {
+		if err := xdb.ValidateNamedQueries(pingCtx); err != nil {
+			xdb.Close()
+			return nil, fmt.Errorf("命名查询校验失败: %v", err)
+		}
+	}
+
+	// 校验追加的 sql_mode 是否生效
+	if len(cfg.SQLModeFlags) > 0 {
+		missing, err := xdb.MissingSQLModes(pingCtx, cfg.SQLModeFlags...)
+		if err == nil && len(missing) > 0 {
+			err = fmt.Errorf("未生效: %s", strings.Join(missing, ", "))
+		}
+		if err != nil {
+			xdb.Close()
+			return nil, fmt.Errorf("sql_mode校验失败: %v", err)
+		}
+	}
+
+	// 校验数据库结构版本
+	if cfg.RequiredSchemaVersion > 0 {
+		if err := xdb.RequireSchemaVersionWithContext(pingCtx, cfg.RequiredSchemaVersion); err != nil {
+			if cfg.SchemaVersionMode != "warn" {
+				xdb.Close()
+				return nil, err
+			}
+			xdb.logger.Warn("数据库结构版本校验失败", "error", err)
+		}
+	}
+
+	// 启动自检
+	if cfg.SelfCheckOnStart {
+		checkCtx, checkCancel := context.WithTimeout(ctx, 10*time.Second)
+		warnings, err := xdb.SelfCheck(checkCtx)
+		checkCancel()
+		if err != nil {
+			xdb.logger.Error("启动自检失败", "error", err)
+		}
+		for _, warning := range warnings {
+			xdb.logger.Warn("启动自检告警", "check", warning.Check, "message", warning.Message, "suggestion", warning.Suggestion)
+		}
+	}
+
+	// 启用查询异常检测
+	if cfg.AnomalyFactor > 0 {
+		xdb.anomalyDetector = newAnomalyDetector(cfg.AnomalyFactor, cfg.AnomalyMinSamples)
 	}
 
 	// 启动连接池统计信息收集
 	if cfg.EnablePoolStats {
-		xdb.poolStatsEnabled.Store(true)
-		go xdb.collectPoolStats(cfg.PoolStatsInterval)
+		xdb.SetDBMetricsEnable(true)
 	}
 
 	// 启动连接探活
-	go xdb.startKeepAlive()
+	tasks := map[string]func(ctx context.Context) error{
+		taskKeepAlive: xdb.keepAlive,
+	}
+
+	// 启动自增ID耗尽检查
+	if cfg.AutoIncrementCheckInterval > 0 {
+		tasks[taskAutoIncrementMonitor] = func(ctx context.Context) error {
+			return xdb.monitorAutoIncrement(ctx, cfg.AutoIncrementCheckInterval)
+		}
+	}
+
+	// 启动调试模式切换
+	if cfg.DebugSignals {
+		tasks[taskDebugSignals] = xdb.watchDebugSignals
+	}
+	if cfg.DebugControlFile != "" {
+		tasks[taskDebugControlFile] = func(ctx context.Context) error {
+			return xdb.watchDebugControlFile(ctx, cfg.DebugControlFile)
+		}
+	}
+
+	// 启动长查询终止
+	if cfg.QueryKillerInterval > 0 {
+		tasks[taskQueryKiller] = func(ctx context.Context) error {
+			return xdb.killLongQueriesLoop(ctx, cfg.QueryKillerInterval, cfg.QueryKillerTimeout)
+		}
+	}
+
+	for name, fn := range tasks {
+		if err := xdb.RegisterTask(name, fn); err != nil {
+			xdb.Close()
+			return nil, err
+		}
+	}
 
 	return xdb, nil
 }
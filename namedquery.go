@@ -0,0 +1,337 @@
+package xlorm
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// namedQueryMarker 命名查询文件中查询名称的标记行前缀
+const namedQueryMarker = "-- name:"
+
+// namedQuery 已解析的命名查询
+type namedQuery struct {
+	name   string
+	query  string   // 原始SQL
+	parts  []string // 参数之间的SQL片段，len(parts) == len(params)+1
+	params []string // 按出现顺序排列的参数名
+	source string   // 来源文件
+}
+
+// namedQueryRegistry 命名查询注册表
+type namedQueryRegistry struct {
+	mu      sync.RWMutex
+	queries map[string]*namedQuery
+}
+
+// newNamedQueryRegistry 创建命名查询注册表
+func newNamedQueryRegistry() *namedQueryRegistry {
+	return &namedQueryRegistry{queries: make(map[string]*namedQuery)}
+}
+
+// RegisterNamedQuery 注册命名查询，参数使用 :name 形式，例如：
+//
+//	db.RegisterNamedQuery("active_users_by_region", "SELECT * FROM users WHERE region = :region AND status IN (:statuses)")
+//	rows, err := db.Named("active_users_by_region", map[string]interface{}{"region": "east", "statuses": []int{1, 2}})
+//
+// 同名查询会被覆盖
+func (db *DB) RegisterNamedQuery(name, query string) error {
+	nq, err := parseNamedQuery(name, query)
+	if err != nil {
+		return err
+	}
+	db.namedQueries.mu.Lock()
+	defer db.namedQueries.mu.Unlock()
+	db.namedQueries.queries[name] = nq
+	return nil
+}
+
+// LoadNamedQueries 从SQL文件加载命名查询，pattern 为 glob 表达式（如 "reports/*.sql"）
+// 每个查询以 "-- name: 查询名称" 行开始，直到下一个标记行或文件结束
+func (db *DB) LoadNamedQueries(pattern string) error {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("命名查询文件路径非法: %v", err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("未找到命名查询文件: %s", pattern)
+	}
+
+	var queries []*namedQuery
+	for _, file := range files {
+		fileQueries, err := readNamedQueryFile(file)
+		if err != nil {
+			return err
+		}
+		queries = append(queries, fileQueries...)
+	}
+
+	db.namedQueries.mu.Lock()
+	defer db.namedQueries.mu.Unlock()
+	for _, nq := range queries {
+		db.namedQueries.queries[nq.name] = nq
+	}
+	db.logger.Info("已加载命名查询", "pattern", pattern, "count", len(queries))
+	return nil
+}
+
+// ValidateNamedQueries 在数据库中预编译所有命名查询，检查SQL语法及引用的表和字段是否存在
+func (db *DB) ValidateNamedQueries(ctx context.Context) error {
+	db.namedQueries.mu.RLock()
+	queries := make([]*namedQuery, 0, len(db.namedQueries.queries))
+	for _, nq := range db.namedQueries.queries {
+		queries = append(queries, nq)
+	}
+	db.namedQueries.mu.RUnlock()
+	sort.Slice(queries, func(i, j int) bool { return queries[i].name < queries[j].name })
+
+	var errs []error
+	for _, nq := range queries {
+		stmt, err := db.DB.PrepareContext(ctx, strings.Join(nq.parts, "?"))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("命名查询 %s%s 校验失败: %v", nq.name, nq.sourceSuffix(), err))
+			continue
+		}
+		stmt.Close()
+	}
+	return errors.Join(errs...)
+}
+
+// NamedQueries 获取已注册的命名查询名称
+func (db *DB) NamedQueries() []string {
+	db.namedQueries.mu.RLock()
+	defer db.namedQueries.mu.RUnlock()
+	names := make([]string, 0, len(db.namedQueries.queries))
+	for name := range db.namedQueries.queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Named 执行命名查询，params 的键为参数名，切片参数会展开为 IN 列表
+func (db *DB) Named(name string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	return db.NamedWithContext(db.GetContext(), name, params)
+}
+
+// NamedWithContext 带上下文的Named
+func (db *DB) NamedWithContext(ctx context.Context, name string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	db.namedQueries.mu.RLock()
+	nq, ok := db.namedQueries.queries[name]
+	db.namedQueries.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("命名查询不存在: %s", name)
+	}
+
+	query, args, err := nq.bind(params)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryWithContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("执行命名查询 %s 失败: %v", name, err)
+	}
+	defer rows.Close()
+	return scanRowMaps(rows)
+}
+
+// bind 绑定参数，生成带 ? 占位符的SQL
+func (nq *namedQuery) bind(params map[string]interface{}) (string, []interface{}, error) {
+	var query strings.Builder
+	args := make([]interface{}, 0, len(nq.params))
+	for i, param := range nq.params {
+		query.WriteString(nq.parts[i])
+		value, ok := params[param]
+		if !ok {
+			return "", nil, fmt.Errorf("命名查询 %s 缺少参数: %s", nq.name, param)
+		}
+
+		// 切片参数展开为 ?, ?, ?
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+			if rv.Len() == 0 {
+				return "", nil, fmt.Errorf("命名查询 %s 的参数 %s 为空切片", nq.name, param)
+			}
+			for j := 0; j < rv.Len(); j++ {
+				if j > 0 {
+					query.WriteString(", ")
+				}
+				query.WriteByte('?')
+				args = append(args, rv.Index(j).Interface())
+			}
+			continue
+		}
+		query.WriteByte('?')
+		args = append(args, value)
+	}
+	query.WriteString(nq.parts[len(nq.parts)-1])
+	return query.String(), args, nil
+}
+
+// sourceSuffix 错误信息中的来源文件
+func (nq *namedQuery) sourceSuffix() string {
+	if nq.source == "" {
+		return ""
+	}
+	return "(" + nq.source + ")"
+}
+
+// parseNamedQuery 解析命名查询，将 :name 参数拆分出来；字符串、反引号标识符和注释中的冒号以及 :: 不视为参数
+func parseNamedQuery(name, query string) (*namedQuery, error) {
+	if name == "" || !isValidFieldName(name) {
+		return nil, fmt.Errorf("命名查询名称非法: %q", name)
+	}
+	query = strings.TrimSpace(query)
+	query = strings.TrimSpace(strings.TrimSuffix(query, ";"))
+	if query == "" {
+		return nil, fmt.Errorf("命名查询 %s 的SQL不能为空", name)
+	}
+
+	nq := &namedQuery{name: name, query: query}
+	var part strings.Builder
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote != '`' && i+1 < len(query) {
+				part.WriteByte(c)
+				i++
+				c = query[i]
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && strings.HasPrefix(query[i:], "--"), c == '/' && strings.HasPrefix(query[i:], "/*"):
+			// 注释原样保留
+			end := len(query) - i
+			if c == '-' {
+				if n := strings.IndexByte(query[i:], '\n'); n >= 0 {
+					end = n
+				}
+			} else if n := strings.Index(query[i+2:], "*/"); n >= 0 {
+				end = n + 4
+			}
+			part.WriteString(query[i : i+end])
+			i += end - 1
+			continue
+		case c == ';':
+			return nil, fmt.Errorf("命名查询 %s 不允许包含多条语句", name)
+		case c == '?':
+			return nil, fmt.Errorf("命名查询 %s 应使用 :name 形式的参数", name)
+		case c == ':' && i+1 < len(query) && query[i+1] == ':':
+			part.WriteString("::")
+			i++
+			continue
+		case c == ':' && i+1 < len(query) && isIdentByte(query[i+1]):
+			j := i + 1
+			for j < len(query) && isIdentByte(query[j]) {
+				j++
+			}
+			nq.parts = append(nq.parts, part.String())
+			nq.params = append(nq.params, query[i+1:j])
+			part.Reset()
+			i = j - 1
+			continue
+		}
+		part.WriteByte(c)
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("命名查询 %s 存在未闭合的引号", name)
+	}
+	nq.parts = append(nq.parts, part.String())
+	return nq, nil
+}
+
+// readNamedQueryFile 读取命名查询文件
+func readNamedQueryFile(file string) ([]*namedQuery, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取命名查询文件失败: %v", err)
+	}
+	defer f.Close()
+
+	var queries []*namedQuery
+	var name string
+	var body strings.Builder
+	flush := func() error {
+		if name == "" {
+			if strings.TrimSpace(body.String()) != "" {
+				return fmt.Errorf("命名查询文件 %s 中存在未命名的SQL", file)
+			}
+			return nil
+		}
+		nq, err := parseNamedQuery(name, body.String())
+		if err != nil {
+			return fmt.Errorf("命名查询文件 %s: %v", file, err)
+		}
+		nq.source = file
+		queries = append(queries, nq)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, namedQueryMarker) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			name = strings.TrimSpace(strings.TrimPrefix(trimmed, namedQueryMarker))
+			body.Reset()
+			continue
+		}
+		body.WriteString(line)
+		body.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取命名查询文件失败: %v", err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+// scanRowMaps 将结果集转换为 map 切片，[]byte 转换为字符串
+func scanRowMaps(rows *sql.Rows) ([]map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("获取列信息失败: %v", err)
+	}
+	values := make([]interface{}, len(columns))
+	scanArgs := make([]interface{}, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	results := make([]map[string]interface{}, 0, 64)
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, newRowsScanError(err, "", columns, scanArgs, len(results)+1)
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[col] = string(b)
+			} else {
+				row[col] = values[i]
+			}
+		}
+		results = append(results, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果集失败: %v", err)
+	}
+	return results, nil
+}
@@ -0,0 +1,238 @@
+package xlorm
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// QueryEventType 查询事件类型
+type QueryEventType int
+
+const (
+	QueryStarted  QueryEventType = iota // 开始执行
+	QueryFinished                       // 执行结束
+)
+
+// QueryEvent 查询事件
+type QueryEvent struct {
+	Type        QueryEventType // 事件类型
+	TraceID     string         // 追踪ID，通过 WithTraceID 设置
+	Operation   string         // 操作类型（如 insert、update、findAll、query、exec）
+	Table       string         // 表名，直接执行SQL时为空
+	Query       string         // SQL语句
+	Fingerprint string         // SQL指纹，结构相同的SQL指纹相同
+	StartTime   time.Time      // 开始时间
+	Duration    time.Duration  // 执行耗时，仅 QueryFinished 事件有效
+	Rows        int64          // 返回或影响的行数，仅 QueryFinished 事件有效
+	Err         error          // 执行错误，仅 QueryFinished 事件有效
+}
+
+// queryInfo 一次SQL执行的观测信息
+type queryInfo struct {
+	ctx         context.Context
+	operation   string
+	table       string
+	query       string
+	args        []interface{}
+	start       time.Time
+	fingerprint string
+	rows        int64
+	rowsUnknown bool // 返回 *sql.Rows 时行数未知
+	err         error
+	dualWriteTx *dualWriteTx // 所在事务待复制到副库的语句，不在事务中时为空
+}
+
+// Events 返回查询事件流，供外部系统（监控面板、异常检测等）消费查询遥测数据
+// 首次调用后开始投递事件；缓冲区满时丢弃新事件而不阻塞查询，丢弃数量可通过 DroppedEvents 获取
+// 通道在 Close 时关闭
+func (db *DB) Events() <-chan QueryEvent {
+	db.eventsEnabled.Store(true)
+	return db.events
+}
+
+// DroppedEvents 获取因缓冲区已满而丢弃的查询事件数量
+func (db *DB) DroppedEvents() uint64 {
+	return db.droppedEvents.Load()
+}
+
+// beforeQuery 在SQL执行前调用
+func (db *DB) beforeQuery(qi *queryInfo) {
+	qi.start = time.Now()
+	if db.profileSlowQueries {
+		db.setProfileLabels(qi)
+	}
+	if db.eventsEnabled.Load() {
+		db.emitEvent(QueryEvent{
+			Type:        QueryStarted,
+			TraceID:     TraceIDFromContext(qi.ctx),
+			Operation:   qi.operation,
+			Table:       qi.table,
+			Query:       qi.query,
+			Fingerprint: qi.getFingerprint(),
+			StartTime:   qi.start,
+		})
+	}
+}
+
+// afterQuery 在SQL执行后调用
+func (db *DB) afterQuery(qi *queryInfo) {
+	duration := time.Since(qi.start)
+	if db.profileSlowQueries {
+		db.restoreProfileLabels(qi)
+		if duration >= db.slowQueryThreshold {
+			db.captureSlowQueryProfile(qi, duration)
+		}
+	}
+	if qi.err == nil && duration >= db.slowQueryThreshold {
+		db.captureSlowQuery(qi, duration)
+		if db.config.ExplainSlowQueries {
+			db.explainSlowQuery(qi, duration)
+		}
+	}
+	if db.asyncDBMetrics != nil {
+		db.asyncDBMetrics.RecordTableQuery(qi.table, qi.operation, duration, qi.err != nil, qi.err == nil && duration >= db.slowQueryThreshold)
+	}
+	db.mirrorQuery(qi, duration)
+	db.dualWriteQuery(qi)
+	if db.anomalyDetector != nil {
+		db.detectAnomaly(qi, duration)
+	}
+	eventsEnabled, hasPlugins := db.eventsEnabled.Load(), db.hasPlugins()
+	if !eventsEnabled && !hasPlugins {
+		return
+	}
+	event := QueryEvent{
+		Type:        QueryFinished,
+		TraceID:     TraceIDFromContext(qi.ctx),
+		Operation:   qi.operation,
+		Table:       qi.table,
+		Query:       qi.query,
+		Fingerprint: qi.getFingerprint(),
+		StartTime:   qi.start,
+		Duration:    duration,
+		Rows:        qi.rows,
+		Err:         qi.err,
+	}
+	if eventsEnabled {
+		db.emitEvent(event)
+	}
+	if hasPlugins {
+		db.pluginsOnQuery(qi.ctx, event)
+	}
+}
+
+// emitEvent 非阻塞地投递查询事件
+func (db *DB) emitEvent(event QueryEvent) {
+	db.eventsMu.RLock()
+	defer db.eventsMu.RUnlock()
+	if db.eventsClosed {
+		return
+	}
+	select {
+	case db.events <- event:
+	default:
+		db.droppedEvents.Add(1)
+	}
+}
+
+// closeEvents 关闭查询事件流
+func (db *DB) closeEvents() {
+	db.eventsMu.Lock()
+	defer db.eventsMu.Unlock()
+	if db.eventsClosed {
+		return
+	}
+	db.eventsClosed = true
+	close(db.events)
+}
+
+// getFingerprint 获取SQL指纹，首次调用时计算
+func (qi *queryInfo) getFingerprint() string {
+	if qi.fingerprint == "" && qi.query != "" {
+		qi.fingerprint = QueryFingerprint(qi.query)
+	}
+	return qi.fingerprint
+}
+
+var (
+	placeholderListRegex  = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	placeholderTupleRegex = regexp.MustCompile(`\(\?\+\)(?:\s*,\s*\(\?\+\))+`)
+)
+
+// QueryFingerprint 生成SQL指纹
+// 字符串和数字字面量替换为 ?，合并连续空白，占位符列表（如 IN (?,?,?)、多行 VALUES）合并为 (?+)
+func QueryFingerprint(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	lastSpace := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			// 跳过字符串字面量，支持反斜杠转义和重复引号转义
+			for i++; i < len(query); i++ {
+				if query[i] == '\\' {
+					i++
+					continue
+				}
+				if query[i] == c {
+					if i+1 < len(query) && query[i+1] == c {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			b.WriteByte('?')
+			lastSpace = false
+		case c == '`':
+			// 保留反引号标识符
+			j := strings.IndexByte(query[i+1:], '`')
+			if j < 0 {
+				b.WriteString(query[i:])
+				i = len(query)
+				break
+			}
+			b.WriteString(query[i : i+j+2])
+			i += j + 1
+			lastSpace = false
+		case c >= '0' && c <= '9' && (i == 0 || !isIdentByte(query[i-1])):
+			// 数字字面量
+			for i+1 < len(query) && (isIdentByte(query[i+1]) || query[i+1] == '.') {
+				i++
+			}
+			b.WriteByte('?')
+			lastSpace = false
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if !lastSpace && b.Len() > 0 {
+				b.WriteByte(' ')
+				lastSpace = true
+			}
+		default:
+			b.WriteByte(c)
+			lastSpace = false
+		}
+	}
+	fingerprint := strings.TrimSpace(b.String())
+	fingerprint = placeholderListRegex.ReplaceAllString(fingerprint, "(?+)")
+	fingerprint = placeholderTupleRegex.ReplaceAllString(fingerprint, "(?+)")
+	return fingerprint
+}
+
+// isIdentByte 判断字符是否可作为标识符的一部分
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// newQueryInfo 创建表操作的观测信息
+func (t *Table) newQueryInfo(ctx context.Context, operation, query string, args []interface{}) *queryInfo {
+	return &queryInfo{
+		ctx:       ctx,
+		operation: operation,
+		table:     t.rawTableName(),
+		query:     query,
+		args:      args,
+	}
+}
@@ -0,0 +1,306 @@
+package xlorm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidCursor 游标无法解析或与排序字段不匹配
+var ErrInvalidCursor = errors.New("游标无效")
+
+// cursorTimeLayout 游标中时间值的格式，与 MySQL DATETIME 字面量一致
+const cursorTimeLayout = "2006-01-02 15:04:05.999999999"
+
+// PageResult 分页查询结果
+type PageResult struct {
+	Items      []map[string]interface{} // 本页记录
+	Total      int64                    // 记录总数
+	Page       int64                    // 当前页码
+	PageSize   int64                    // 每页记录数
+	TotalPages int64                    // 总页数
+}
+
+// Paginate 分页查询，返回本页记录、总数和分页信息
+// COUNT 查询与本页查询并行执行（未使用已废弃的 SQL_CALC_FOUND_ROWS）
+// page 小于1时按1处理，pageSize 小于1时按20处理
+func (t *Table) Paginate(page, pageSize int64) (*PageResult, error) {
+	return t.PaginateWithContext(t.context(), page, pageSize)
+}
+
+// PaginateWithContext 带上下文的Paginate
+func (t *Table) PaginateWithContext(ctx context.Context, page, pageSize int64) (*PageResult, error) {
+	ctx = t.resolveContext(ctx)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	t.Page(page, pageSize)
+	result := &PageResult{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	t.hasTotal = false
+
+	// 复制查询条件用于并行的 COUNT 查询
+	countTable := t.cloneTable()
+	var wg sync.WaitGroup
+	var countErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		result.Total, countErr = countTable.count(ctx)
+	}()
+
+	items, err := t.findAllWithContext(ctx, "paginate")
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	if countErr != nil {
+		return nil, fmt.Errorf("获取记录总数失败: %v", countErr)
+	}
+
+	result.Items = items
+	result.TotalPages = (result.Total + result.PageSize - 1) / result.PageSize
+	return result, nil
+}
+
+// CursorPaginate 游标（keyset）分页，使用 cursorField > after 代替 OFFSET，大表深分页时性能稳定
+// cursorField 必须唯一且有序（通常为自增主键），after 为上一页返回的游标，查询首页时传 nil
+// 返回本页记录和下一页游标，nextCursor 为 nil 表示没有更多数据
+// 例如：rows, next, err := db.M("users").Where("status = ?", 1).CursorPaginate("id", nil, 100)
+func (t *Table) CursorPaginate(cursorField string, after interface{}, limit int64) (rows []map[string]interface{}, nextCursor interface{}, err error) {
+	return t.CursorPaginateWithContext(t.context(), cursorField, after, limit)
+}
+
+// CursorPaginateWithContext 带上下文的CursorPaginate
+func (t *Table) CursorPaginateWithContext(ctx context.Context, cursorField string, after interface{}, limit int64) (rows []map[string]interface{}, nextCursor interface{}, err error) {
+	if !isValidFieldName(cursorField) {
+		t.Release()
+		return nil, nil, fmt.Errorf("游标字段非法: %s", cursorField)
+	}
+	if limit <= 0 {
+		t.Release()
+		return nil, nil, errors.New("limit必须为正数")
+	}
+
+	if after != nil {
+		t.addScope(quoteColumn(cursorField)+" > ?", after)
+	}
+
+	// 结果集中的列名不含表名前缀
+	column := cursorField[strings.LastIndexByte(cursorField, '.')+1:]
+	if len(t.fields) > 0 && !slices.Contains(t.fields, column) && !slices.Contains(t.fields, cursorField) {
+		t.fields = append(t.fields, cursorField)
+	}
+
+	// 多查询一条用于判断是否还有下一页
+	t.orderBy = quoteColumn(cursorField) + " ASC"
+	t.limit = limit + 1
+	t.offset = 0
+	t.hasTotal = false
+
+	rows, err = t.findAllWithContext(ctx, "cursorPaginate")
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(rows)) <= limit {
+		return rows, nil, nil
+	}
+	rows = rows[:limit]
+	return rows, rows[limit-1][column], nil
+}
+
+// CursorSort 多字段游标分页的排序字段
+type CursorSort struct {
+	Field string // 排序字段，不能为 NULL
+	Desc  bool   // 是否降序
+}
+
+// cursorPayload 游标编码前的内容，包含排序签名用于校验游标与排序字段一致
+type cursorPayload struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+// CursorPaginateBy 多字段游标（keyset）分页，按 sorts 依次排序，适用于 created_at DESC, id DESC 这类存在重复值的排序
+// 最后一个排序字段必须唯一（通常为主键），用于在前面字段取值相同时稳定地区分记录
+// cursor 为上一页返回的不透明游标，查询首页时传空字符串；nextCursor 为空表示没有更多数据，例如：
+//
+//	sorts := []xlorm.CursorSort{{Field: "created_at", Desc: true}, {Field: "id", Desc: true}}
+//	rows, next, err := db.M("orders").Where("user_id = ?", uid).CursorPaginateBy(sorts, "", 50)
+func (t *Table) CursorPaginateBy(sorts []CursorSort, cursor string, limit int64) (rows []map[string]interface{}, nextCursor string, err error) {
+	return t.CursorPaginateByWithContext(t.context(), sorts, cursor, limit)
+}
+
+// CursorPaginateByWithContext 带上下文的CursorPaginateBy
+func (t *Table) CursorPaginateByWithContext(ctx context.Context, sorts []CursorSort, cursor string, limit int64) (rows []map[string]interface{}, nextCursor string, err error) {
+	if len(sorts) == 0 {
+		t.Release()
+		return nil, "", errors.New("游标分页必须指定排序字段")
+	}
+	for _, sort := range sorts {
+		if !isValidFieldName(sort.Field) {
+			t.Release()
+			return nil, "", fmt.Errorf("游标字段非法: %s", sort.Field)
+		}
+	}
+	if limit <= 0 {
+		t.Release()
+		return nil, "", errors.New("limit必须为正数")
+	}
+
+	signature := cursorSignature(sorts)
+	if cursor != "" {
+		values, err := decodeCursor(cursor, signature, len(sorts))
+		if err != nil {
+			t.Release()
+			return nil, "", err
+		}
+		condition, args := keysetCondition(sorts, values)
+		t.addScope(condition, args...)
+	}
+
+	// 结果集中的列名不含表名前缀
+	columns := make([]string, len(sorts))
+	orderBy := make([]string, len(sorts))
+	for i, sort := range sorts {
+		columns[i] = sort.Field[strings.LastIndexByte(sort.Field, '.')+1:]
+		if len(t.fields) > 0 && !slices.Contains(t.fields, columns[i]) && !slices.Contains(t.fields, sort.Field) {
+			t.fields = append(t.fields, sort.Field)
+		}
+		orderBy[i] = quoteColumn(sort.Field) + " ASC"
+		if sort.Desc {
+			orderBy[i] = quoteColumn(sort.Field) + " DESC"
+		}
+	}
+
+	// 多查询一条用于判断是否还有下一页
+	t.orderBy = strings.Join(orderBy, ", ")
+	t.limit = limit + 1
+	t.offset = 0
+	t.hasTotal = false
+
+	rows, err = t.findAllWithContext(ctx, "cursorPaginate")
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(rows)) <= limit {
+		return rows, "", nil
+	}
+	rows = rows[:limit]
+	last := rows[limit-1]
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		if values[i] = last[column]; values[i] == nil {
+			return nil, "", fmt.Errorf("游标字段 %s 的值为 NULL，无法生成下一页游标", sorts[i].Field)
+		}
+	}
+	nextCursor, err = encodeCursor(signature, values)
+	if err != nil {
+		return nil, "", err
+	}
+	return rows, nextCursor, nil
+}
+
+// keysetCondition 生成取排序位置之后记录的条件，
+// 例如 (a DESC, b ASC) 生成 (`a` < ?) OR (`a` = ? AND `b` > ?)
+func keysetCondition(sorts []CursorSort, values []interface{}) (string, []interface{}) {
+	var condition strings.Builder
+	var args []interface{}
+	condition.WriteByte('(')
+	for i, sort := range sorts {
+		if i > 0 {
+			condition.WriteString(" OR ")
+		}
+		condition.WriteByte('(')
+		for j := 0; j < i; j++ {
+			condition.WriteString(quoteColumn(sorts[j].Field))
+			condition.WriteString(" = ? AND ")
+			args = append(args, values[j])
+		}
+		condition.WriteString(quoteColumn(sort.Field))
+		if sort.Desc {
+			condition.WriteString(" < ?")
+		} else {
+			condition.WriteString(" > ?")
+		}
+		args = append(args, values[i])
+		condition.WriteByte(')')
+	}
+	condition.WriteByte(')')
+	return condition.String(), args
+}
+
+// cursorSignature 排序签名，如 created_at:desc,id:desc
+func cursorSignature(sorts []CursorSort) string {
+	parts := make([]string, len(sorts))
+	for i, sort := range sorts {
+		parts[i] = sort.Field + ":asc"
+		if sort.Desc {
+			parts[i] = sort.Field + ":desc"
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// encodeCursor 将排序字段的值编码为 URL 安全的不透明游标
+func encodeCursor(signature string, values []interface{}) (string, error) {
+	payload := cursorPayload{Sort: signature, Values: make([]interface{}, len(values))}
+	for i, value := range values {
+		switch v := value.(type) {
+		case []byte:
+			payload.Values[i] = string(v)
+		case time.Time:
+			payload.Values[i] = v.Format(cursorTimeLayout)
+		default:
+			payload.Values[i] = v
+		}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("生成游标失败: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor 解析游标并校验排序签名，整数还原为 int64，其余数字保留原始文本避免精度损失
+func decodeCursor(cursor, signature string, n int) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	var payload cursorPayload
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if payload.Sort != signature || len(payload.Values) != n {
+		return nil, fmt.Errorf("%w: 游标与排序字段不匹配", ErrInvalidCursor)
+	}
+	for i, value := range payload.Values {
+		switch v := value.(type) {
+		case json.Number:
+			if i64, err := v.Int64(); err == nil {
+				payload.Values[i] = i64
+			} else {
+				payload.Values[i] = v.String()
+			}
+		case string, bool:
+		default:
+			return nil, fmt.Errorf("%w: 不支持的游标值类型 %T", ErrInvalidCursor, value)
+		}
+	}
+	return payload.Values, nil
+}
@@ -0,0 +1,204 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Plugin 插件接口，租户隔离、审计、缓存、链路追踪等功能可以插件形式独立发布
+// 插件按注册顺序调用，关闭时按注册的逆序调用 OnClose；可嵌入 BasePlugin 只实现需要的方法
+type Plugin interface {
+	Name() string                                        // 插件名称，不可重复
+	Init(db *DB) error                                   // 注册时调用，返回错误时插件不会被注册
+	OnQuery(ctx context.Context, event QueryEvent) error // SQL执行结束后同步调用
+	OnTx(ctx context.Context, event TxEvent) error       // 事务开始、提交、回滚后同步调用
+	OnClose() error                                      // 数据库关闭时调用
+}
+
+// BasePlugin 插件的空实现，嵌入后只需实现 Name 及需要的方法
+type BasePlugin struct{}
+
+// Init 空实现
+func (BasePlugin) Init(*DB) error { return nil }
+
+// OnQuery 空实现
+func (BasePlugin) OnQuery(context.Context, QueryEvent) error { return nil }
+
+// OnTx 空实现
+func (BasePlugin) OnTx(context.Context, TxEvent) error { return nil }
+
+// OnClose 空实现
+func (BasePlugin) OnClose() error { return nil }
+
+// TxEventType 事务事件类型
+type TxEventType int
+
+const (
+	TxBegin    TxEventType = iota // 开始事务
+	TxCommit                      // 提交事务
+	TxRollback                    // 回滚事务
+)
+
+// String 返回事务事件类型名称
+func (t TxEventType) String() string {
+	switch t {
+	case TxBegin:
+		return "begin"
+	case TxCommit:
+		return "commit"
+	case TxRollback:
+		return "rollback"
+	default:
+		return "unknown"
+	}
+}
+
+// TxEvent 事务事件
+type TxEvent struct {
+	Type     TxEventType   // 事件类型
+	TraceID  string        // 事务跟踪ID
+	Duration time.Duration // 事务开始至今的耗时，TxBegin 事件为开始事务的耗时
+	Err      error         // 错误信息
+}
+
+// PluginStats 插件统计信息
+type PluginStats struct {
+	Calls    int64         // 调用次数
+	Errors   int64         // 返回错误次数
+	Panics   int64         // 发生 panic 的次数
+	Duration time.Duration // 累计耗时
+}
+
+// pluginEntry 已注册的插件及其统计信息
+type pluginEntry struct {
+	plugin Plugin
+	calls  atomic.Int64
+	errors atomic.Int64
+	panics atomic.Int64
+	nanos  atomic.Int64
+}
+
+// pluginRegistry 插件注册表，查询路径上无锁读取
+type pluginRegistry struct {
+	mu      sync.Mutex
+	entries atomic.Pointer[[]*pluginEntry]
+}
+
+// newPluginRegistry 创建插件注册表
+func newPluginRegistry() *pluginRegistry {
+	r := &pluginRegistry{}
+	r.entries.Store(&[]*pluginEntry{})
+	return r
+}
+
+// Use 按顺序注册插件
+func (db *DB) Use(plugins ...Plugin) error {
+	db.plugins.mu.Lock()
+	defer db.plugins.mu.Unlock()
+	for _, plugin := range plugins {
+		if plugin == nil {
+			return errors.New("插件不能为空")
+		}
+		name := plugin.Name()
+		entries := *db.plugins.entries.Load()
+		for _, entry := range entries {
+			if entry.plugin.Name() == name {
+				return fmt.Errorf("插件已注册: %s", name)
+			}
+		}
+
+		entry := &pluginEntry{plugin: plugin}
+		if err := db.callPlugin(entry, "init", func() error { return plugin.Init(db) }); err != nil {
+			return fmt.Errorf("初始化插件 %s 失败: %v", name, err)
+		}
+		next := make([]*pluginEntry, len(entries), len(entries)+1)
+		copy(next, entries)
+		next = append(next, entry)
+		db.plugins.entries.Store(&next)
+		db.logger.Info("已注册插件", "plugin", name)
+	}
+	return nil
+}
+
+// Plugins 获取已注册的插件名称，按注册顺序排列
+func (db *DB) Plugins() []string {
+	entries := *db.plugins.entries.Load()
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.plugin.Name()
+	}
+	return names
+}
+
+// PluginStats 获取各插件的统计信息
+func (db *DB) PluginStats() map[string]PluginStats {
+	entries := *db.plugins.entries.Load()
+	stats := make(map[string]PluginStats, len(entries))
+	for _, entry := range entries {
+		stats[entry.plugin.Name()] = PluginStats{
+			Calls:    entry.calls.Load(),
+			Errors:   entry.errors.Load(),
+			Panics:   entry.panics.Load(),
+			Duration: time.Duration(entry.nanos.Load()),
+		}
+	}
+	return stats
+}
+
+// hasPlugins 是否注册了插件
+func (db *DB) hasPlugins() bool {
+	return len(*db.plugins.entries.Load()) > 0
+}
+
+// pluginsOnQuery 通知插件SQL执行结束
+func (db *DB) pluginsOnQuery(ctx context.Context, event QueryEvent) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for _, entry := range *db.plugins.entries.Load() {
+		db.callPlugin(entry, "on_query", func() error { return entry.plugin.OnQuery(ctx, event) })
+	}
+}
+
+// pluginsOnTx 通知插件事务事件
+func (db *DB) pluginsOnTx(event TxEvent) {
+	ctx := context.Background()
+	for _, entry := range *db.plugins.entries.Load() {
+		db.callPlugin(entry, "on_tx", func() error { return entry.plugin.OnTx(ctx, event) })
+	}
+}
+
+// closePlugins 按注册的逆序关闭插件
+func (db *DB) closePlugins() error {
+	entries := *db.plugins.entries.Load()
+	var errs []error
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if err := db.callPlugin(entry, "on_close", entry.plugin.OnClose); err != nil {
+			errs = append(errs, fmt.Errorf("关闭插件 %s 失败: %w", entry.plugin.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// callPlugin 调用插件方法，记录统计信息，panic 不会影响查询
+func (db *DB) callPlugin(entry *pluginEntry, method string, fn func() error) (err error) {
+	start := time.Now()
+	defer func() {
+		entry.calls.Add(1)
+		entry.nanos.Add(int64(time.Since(start)))
+		if p := recover(); p != nil {
+			entry.panics.Add(1)
+			err = fmt.Errorf("插件发生panic: %v", p)
+		}
+		if err != nil {
+			entry.errors.Add(1)
+			db.logger.Error("插件执行失败", "plugin", entry.plugin.Name(), "method", method, "error", err)
+		}
+	}()
+	return fn()
+}
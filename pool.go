@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // 对象池定义
@@ -52,3 +53,61 @@ func (p *dbPoolStats) get() *sql.DBStats {
 var poolStats = &dbPoolStats{
 	stats: atomic.Pointer[sql.DBStats]{},
 }
+
+// defaultPoolHistorySize 保留的连接池统计历史条数
+const defaultPoolHistorySize = 60
+
+// PoolStatsSample 某一时刻的连接池统计
+type PoolStatsSample struct {
+	Time  time.Time   // 采集时间
+	Stats sql.DBStats // 连接池统计
+}
+
+// poolStatsHistory 保留最近连接池统计的环形缓冲区
+type poolStatsHistory struct {
+	mu      sync.Mutex
+	entries []PoolStatsSample
+	next    int  // 下一个写入位置
+	full    bool // 缓冲区是否已写满
+}
+
+// newPoolStatsHistory 创建连接池统计历史
+func newPoolStatsHistory(size int) *poolStatsHistory {
+	return &poolStatsHistory{entries: make([]PoolStatsSample, size)}
+}
+
+// add 添加统计，缓冲区满时覆盖最早的记录
+func (h *poolStatsHistory) add(sample PoolStatsSample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries[h.next] = sample
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// samples 获取统计历史，按采集时间从早到晚排列
+func (h *poolStatsHistory) samples() []PoolStatsSample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]PoolStatsSample(nil), h.entries[:h.next]...)
+	}
+	result := make([]PoolStatsSample, 0, len(h.entries))
+	result = append(result, h.entries[h.next:]...)
+	return append(result, h.entries[:h.next]...)
+}
+
+// PoolStatsHistory 获取最近的连接池统计历史（开启 EnablePoolStats 后按 PoolStatsInterval 采集，保留条数由 Config.PoolStatsHistorySize 控制），按采集时间从早到晚排列
+func (db *DB) PoolStatsHistory() []PoolStatsSample {
+	if db.poolHistory == nil {
+		return nil
+	}
+	return db.poolHistory.samples()
+}
+
+// GetPoolStatsHistory 获取最近的连接池统计历史，同 PoolStatsHistory
+func (db *DB) GetPoolStatsHistory() []PoolStatsSample {
+	return db.PoolStatsHistory()
+}
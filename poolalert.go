@@ -0,0 +1,88 @@
+package xlorm
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PoolAlertKind 连接池告警类型
+type PoolAlertKind int
+
+const (
+	PoolAlertWaitCount  PoolAlertKind = iota // 采集间隔内等待连接的次数达到阈值
+	PoolAlertInUseRatio                      // 使用中的连接数占最大连接数的比例达到阈值
+)
+
+// String 返回告警类型名称
+func (k PoolAlertKind) String() string {
+	switch k {
+	case PoolAlertWaitCount:
+		return "wait_count"
+	case PoolAlertInUseRatio:
+		return "in_use_ratio"
+	default:
+		return "unknown"
+	}
+}
+
+// PoolStatsAlert 连接池告警，指标从低于阈值变为达到阈值时触发一次，恢复后再次达到阈值时重新触发
+type PoolStatsAlert struct {
+	Kind      PoolAlertKind // 告警类型
+	Value     float64       // 当前值：采集间隔内新增的等待次数，或使用中的连接比例
+	Threshold float64       // 告警阈值
+	Stats     sql.DBStats   // 触发告警时的连接池统计
+	Time      time.Time     // 采集时间
+}
+
+// poolAlertState 连接池告警状态，只在连接池统计协程中访问
+type poolAlertState struct {
+	lastWaitCount int64   // 上次采集时的累计等待次数，-1 表示尚未采集
+	active        [2]bool // 各类告警是否处于触发状态，下标为 PoolAlertKind
+}
+
+// newPoolAlertState 创建连接池告警状态
+func newPoolAlertState() *poolAlertState {
+	return &poolAlertState{lastWaitCount: -1}
+}
+
+// checkPoolAlerts 检查连接池统计是否越过告警阈值，越过时记录日志并调用 Config.OnPoolStatsAlert
+func (db *DB) checkPoolAlerts(sample PoolStatsSample) {
+	state, stats := db.poolAlerts, sample.Stats
+	if threshold := db.config.PoolWaitCountThreshold; threshold > 0 {
+		if state.lastWaitCount >= 0 {
+			waits := stats.WaitCount - state.lastWaitCount
+			db.updatePoolAlert(PoolAlertWaitCount, float64(waits), float64(threshold), waits >= threshold, sample)
+		}
+		state.lastWaitCount = stats.WaitCount
+	}
+	if threshold := db.config.PoolInUseRatioThreshold; threshold > 0 && stats.MaxOpenConnections > 0 {
+		ratio := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+		db.updatePoolAlert(PoolAlertInUseRatio, ratio, threshold, ratio >= threshold, sample)
+	}
+}
+
+// updatePoolAlert 更新告警状态，从未触发变为触发时告警，从触发变为未触发时记录恢复
+func (db *DB) updatePoolAlert(kind PoolAlertKind, value, threshold float64, exceeded bool, sample PoolStatsSample) {
+	state := db.poolAlerts
+	if exceeded == state.active[kind] {
+		return
+	}
+	state.active[kind] = exceeded
+	if !exceeded {
+		db.logger.Info("连接池告警恢复", "kind", kind.String(), "value", value, "threshold", threshold)
+		return
+	}
+	db.logger.Warn("连接池告警",
+		"kind", kind.String(),
+		"value", value,
+		"threshold", threshold,
+		"in_use", sample.Stats.InUse,
+		"idle", sample.Stats.Idle,
+		"max_open", sample.Stats.MaxOpenConnections,
+		"wait_count", sample.Stats.WaitCount,
+		"wait_duration", sample.Stats.WaitDuration,
+	)
+	if db.config.OnPoolStatsAlert != nil {
+		db.config.OnPoolStatsAlert(PoolStatsAlert{Kind: kind, Value: value, Threshold: threshold, Stats: sample.Stats, Time: sample.Time})
+	}
+}
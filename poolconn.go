@@ -0,0 +1,290 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// poolConnector 包装驱动连接器：新连接建立时预编译注册语句，按连接存活时长记录语句延迟，并触发连接池事件
+type poolConnector struct {
+	driver.Connector
+	warmer *stmtWarmer
+	db     atomic.Pointer[DB] // DB 创建完成后设置，之前的连接不记录指标和事件
+	nextID atomic.Uint64      // 连接序号
+}
+
+// Connect 建立连接并预编译注册语句，单条语句编译失败只记录日志，不影响连接使用
+func (c *poolConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		if db := c.db.Load(); db != nil {
+			db.emitPoolEvent(PoolEvent{Kind: PoolConnCreated, Err: err, Time: time.Now()})
+		}
+		return nil, err
+	}
+	now := time.Now()
+	pc := &poolConn{Conn: conn, connector: c, id: c.nextID.Add(1), stmts: make(map[string]driver.Stmt), createdAt: now, lastUsed: now}
+	if db := c.db.Load(); db != nil {
+		db.emitPoolEvent(PoolEvent{Kind: PoolConnCreated, ConnID: pc.id, Time: now})
+	}
+	for query := range *c.warmer.queries.Load() {
+		if _, err := pc.prepare(ctx, query); err != nil {
+			if logger := c.warmer.logger.Load(); logger != nil {
+				logger.Warn("预热语句编译失败", "query", query, "error", err)
+			}
+		}
+	}
+	return pc, nil
+}
+
+// poolConn 连接池中的连接，缓存注册语句的预编译结果并记录每条语句的延迟，其余操作转发给驱动连接
+// database/sql 保证同一连接不会被并发使用，createdAt、lastUsed 无需加锁
+type poolConn struct {
+	driver.Conn
+	connector *poolConnector
+	id        uint64 // 连接序号
+	mu        sync.Mutex
+	stmts     map[string]driver.Stmt // 已编译的注册语句
+	createdAt time.Time              // 连接建立时间
+	lastUsed  time.Time              // 上一条语句执行完成的时间
+}
+
+// observe 记录一条语句的延迟及执行时连接的存活时长和空闲时长，驱动跳过（ErrSkip）的调用不记录
+func (c *poolConn) observe(start time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+	end := time.Now()
+	if db := c.connector.db.Load(); db != nil {
+		db.asyncDBMetrics.RecordConnLatency(start.Sub(c.createdAt), start.Sub(c.lastUsed), end.Sub(start))
+	}
+	c.lastUsed = end
+}
+
+// prepare 编译语句并缓存
+func (c *poolConn) prepare(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.prepareDriver(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.stmts[query] = stmt
+	c.mu.Unlock()
+	return stmt, nil
+}
+
+// prepareDriver 在驱动连接上编译语句
+func (c *poolConn) prepareDriver(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+// Prepare 实现 driver.Conn
+func (c *poolConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext 注册语句返回缓存的预编译语句，其他语句直接编译
+func (c *poolConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if !c.connector.warmer.contains(query) {
+		stmt, err := c.prepareDriver(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return &poolStmt{Stmt: stmt, conn: c}, nil
+	}
+	c.mu.Lock()
+	stmt, ok := c.stmts[query]
+	c.mu.Unlock()
+	if !ok {
+		var err error
+		if stmt, err = c.prepare(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+	return &poolStmt{Stmt: stmt, conn: c, cached: true}, nil
+}
+
+// Close 关闭缓存的语句和连接，并根据连接状态判断关闭原因
+func (c *poolConn) Close() error {
+	reason := c.closeReason()
+	c.mu.Lock()
+	for _, stmt := range c.stmts {
+		stmt.Close()
+	}
+	c.stmts = nil
+	c.mu.Unlock()
+	err := c.Conn.Close()
+	if db := c.connector.db.Load(); db != nil {
+		now := time.Now()
+		kind := PoolConnClosed
+		if reason == "max_lifetime" {
+			kind = PoolConnExpired
+		}
+		db.emitPoolEvent(PoolEvent{Kind: kind, ConnID: c.id, Reason: reason, Age: now.Sub(c.createdAt), Idle: now.Sub(c.lastUsed), Err: err, Time: now})
+	}
+	return err
+}
+
+// closeReason 推断连接被关闭的原因
+func (c *poolConn) closeReason() string {
+	if !c.IsValid() {
+		return "invalid"
+	}
+	db := c.connector.db.Load()
+	if db == nil {
+		return "closed"
+	}
+	now := time.Now()
+	if lifetime := db.config.ConnMaxLifetime; lifetime > 0 && now.Sub(c.createdAt) >= lifetime {
+		return "max_lifetime"
+	}
+	if idle := db.config.ConnMaxIdleTime; idle > 0 && now.Sub(c.lastUsed) >= idle {
+		return "max_idle_time"
+	}
+	return "closed"
+}
+
+// BeginTx 实现 driver.ConnBeginTx
+func (c *poolConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+// ExecContext 实现 driver.ExecerContext
+func (c *poolConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := e.ExecContext(ctx, query, args)
+	c.observe(start, err)
+	return result, err
+}
+
+// QueryContext 实现 driver.QueryerContext，延迟为收到结果集首包的时间
+func (c *poolConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	c.observe(start, err)
+	return rows, err
+}
+
+// Ping 实现 driver.Pinger
+func (c *poolConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession 实现 driver.SessionResetter，连接从连接池取出复用前调用
+func (c *poolConn) ResetSession(ctx context.Context) error {
+	var err error
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		err = r.ResetSession(ctx)
+	}
+	if db := c.connector.db.Load(); db != nil {
+		now := time.Now()
+		db.emitPoolEvent(PoolEvent{Kind: PoolConnReset, ConnID: c.id, Age: now.Sub(c.createdAt), Idle: now.Sub(c.lastUsed), Err: err, Time: now})
+	}
+	return err
+}
+
+// IsValid 实现 driver.Validator
+func (c *poolConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue 实现 driver.NamedValueChecker
+func (c *poolConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// poolStmt 连接上的预编译语句，cached 为 true 时 Close 不关闭底层语句，由连接关闭时统一关闭
+type poolStmt struct {
+	driver.Stmt
+	conn   *poolConn
+	cached bool
+}
+
+// Close 实现 driver.Stmt
+func (s *poolStmt) Close() error {
+	if s.cached {
+		return nil
+	}
+	return s.Stmt.Close()
+}
+
+// ExecContext 实现 driver.StmtExecContext
+func (s *poolStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = e.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			result, err = s.Stmt.Exec(values)
+		}
+	}
+	s.conn.observe(start, err)
+	return result, err
+}
+
+// QueryContext 实现 driver.StmtQueryContext
+func (s *poolStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = q.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			rows, err = s.Stmt.Query(values)
+		}
+	}
+	s.conn.observe(start, err)
+	return rows, err
+}
+
+// CheckNamedValue 实现 driver.NamedValueChecker
+func (s *poolStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// namedValuesToValues 将命名参数转换为位置参数
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("驱动不支持命名参数")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,62 @@
+package xlorm
+
+import (
+	"time"
+)
+
+// PoolEventKind 连接池事件类型
+type PoolEventKind int
+
+const (
+	PoolConnCreated PoolEventKind = iota // 新建连接
+	PoolConnClosed                       // 连接关闭（空闲超时、连接池收缩、连接失效或 DB 关闭）
+	PoolConnReset                        // 连接从连接池取出复用前重置会话
+	PoolConnExpired                      // 连接达到 ConnMaxLifetime 被关闭
+)
+
+// String 返回事件类型名称
+func (k PoolEventKind) String() string {
+	switch k {
+	case PoolConnCreated:
+		return "created"
+	case PoolConnClosed:
+		return "closed"
+	case PoolConnReset:
+		return "reset"
+	case PoolConnExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// PoolEvent 连接池事件，可通过 Config.OnPoolEvent 接收，用于观察连接的频繁重建（如负载均衡空闲超时、wsrep 断连）
+type PoolEvent struct {
+	Kind   PoolEventKind // 事件类型
+	ConnID uint64        // 连接序号，同一 DB 内递增
+	Reason string        // 连接关闭原因：max_lifetime、max_idle_time、invalid、closed
+	Age    time.Duration // 连接已存活时长
+	Idle   time.Duration // 连接自上一条语句执行完成后的空闲时长
+	Err    error         // 建立连接、重置会话或关闭连接的错误
+	Time   time.Time     // 事件时间
+}
+
+// emitPoolEvent 记录连接池事件的指标和日志，并调用 Config.OnPoolEvent
+// 事件在连接池操作中同步触发，回调应尽快返回
+func (db *DB) emitPoolEvent(ev PoolEvent) {
+	db.asyncDBMetrics.RecordPoolEvent(ev.Kind)
+	switch {
+	case ev.Err != nil:
+		db.logger.Warn("连接池事件", "event", ev.Kind.String(), "conn_id", ev.ConnID, "reason", ev.Reason,
+			"age", ev.Age, "idle", ev.Idle, "error", ev.Err)
+	case ev.Kind == PoolConnExpired || (ev.Kind == PoolConnClosed && ev.Reason == "invalid"):
+		db.logger.Info("连接池事件", "event", ev.Kind.String(), "conn_id", ev.ConnID, "reason", ev.Reason,
+			"age", ev.Age, "idle", ev.Idle)
+	case db.IsDebug() && ev.Kind != PoolConnReset:
+		db.logger.Debug("连接池事件", "event", ev.Kind.String(), "conn_id", ev.ConnID, "reason", ev.Reason,
+			"age", ev.Age, "idle", ev.Idle)
+	}
+	if db.config.OnPoolEvent != nil {
+		db.config.OnPoolEvent(ev)
+	}
+}
@@ -0,0 +1,99 @@
+package xlorm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+)
+
+const (
+	maxProfileTraceIDLen = 64 // 快照文件名中追踪ID的最大长度
+)
+
+// setProfileLabels 为当前协程设置 pprof 标签，CPU 等剖析数据可按追踪ID、表名和操作类型归因
+func (db *DB) setProfileLabels(qi *queryInfo) {
+	ctx := qi.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	labels := []string{"xlorm_operation", qi.operation}
+	if qi.table != "" {
+		labels = append(labels, "xlorm_table", qi.table)
+	}
+	if traceID := TraceIDFromContext(ctx); traceID != "" {
+		labels = append(labels, "xlorm_trace_id", traceID)
+	}
+	pprof.SetGoroutineLabels(pprof.WithLabels(ctx, pprof.Labels(labels...)))
+}
+
+// restoreProfileLabels 恢复当前协程的 pprof 标签
+func (db *DB) restoreProfileLabels(qi *queryInfo) {
+	ctx := qi.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	pprof.SetGoroutineLabels(ctx)
+}
+
+// captureSlowQueryProfile 慢查询时采集 goroutine 快照，两次采集间隔不小于 profileMinInterval
+// 快照可通过 go tool pprof 分析：协程数量激增或大量协程处于可运行状态说明延迟来自客户端调度，而非数据库
+func (db *DB) captureSlowQueryProfile(qi *queryInfo, duration time.Duration) {
+	now := time.Now()
+	last := db.lastProfileTime.Load()
+	if last > 0 && now.Sub(time.Unix(0, last)) < db.profileMinInterval {
+		return
+	}
+	if !db.lastProfileTime.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	traceID := TraceIDFromContext(qi.ctx)
+	if err := os.MkdirAll(db.profileDir, 0755); err != nil {
+		db.logger.Error("创建慢查询快照目录失败", "dir", db.profileDir, "error", err)
+		return
+	}
+	name := "slow_" + now.Format("20060102150405.000")
+	if safeID := sanitizeProfileName(traceID); safeID != "" {
+		name += "_" + safeID
+	}
+	path := filepath.Join(db.profileDir, name+".pb.gz")
+
+	file, err := os.Create(path)
+	if err != nil {
+		db.logger.Error("创建慢查询快照失败", "path", path, "error", err)
+		return
+	}
+	defer file.Close()
+	if err := pprof.Lookup("goroutine").WriteTo(file, 0); err != nil {
+		db.logger.Error("写入慢查询快照失败", "path", path, "error", err)
+		return
+	}
+
+	db.logger.Warn("已采集慢查询快照",
+		"trace_id", traceID,
+		"fingerprint", qi.getFingerprint(),
+		"duration", duration.Seconds(),
+		"goroutines", runtime.NumGoroutine(),
+		"gomaxprocs", runtime.GOMAXPROCS(0),
+		"path", path,
+	)
+}
+
+// sanitizeProfileName 清理快照文件名中的非法字符
+func sanitizeProfileName(name string) string {
+	if len(name) > maxProfileTraceIDLen {
+		name = name[:maxProfileTraceIDLen]
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return -1
+		}
+	}, name)
+}
@@ -0,0 +1,143 @@
+package xlorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	protoTimestampPkg = "google.golang.org/protobuf/types/known/timestamppb" // Timestamp 所在包
+	protoWrappersPkg  = "google.golang.org/protobuf/types/known/wrapperspb"  // StringValue 等包装类型所在包
+)
+
+// protoFieldCache 字段名到 protobuf 生成结构体字段下标的映射缓存
+var protoFieldCache sync.Map // map[reflect.Type]map[string]int
+
+// MapProto 将查询结果的一行填充到 protobuf 生成的结构体（msg 为 *pb.Xxx）
+// 列名可以匹配 proto 字段名（如 user_id）、JSON 名（如 userId）或 Go 字段名；
+// 支持 google.protobuf.Timestamp 和 StringValue、Int64Value 等包装类型，未匹配的列会被忽略
+// 通过反射识别生成代码的字段标签和知名类型，不依赖 protobuf 运行时库
+func MapProto(row map[string]interface{}, msg interface{}) error {
+	val := reflect.ValueOf(msg)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("msg 必须为非空的结构体指针: %T", msg)
+	}
+	val = val.Elem()
+	fields := protoFields(val.Type())
+	for column, value := range row {
+		index, ok := fields[column]
+		if !ok {
+			index, ok = fields[strings.ToLower(column)]
+		}
+		if !ok {
+			continue
+		}
+		field := val.Field(index)
+		if err := assignProtoValue(field, value); err != nil {
+			return fmt.Errorf("字段 %s: %v", val.Type().Field(index).Name, err)
+		}
+	}
+	return nil
+}
+
+// ScanProtos 执行查询并将结果映射为 protobuf 生成的结构体，例如：
+//
+//	users, err := xlorm.ScanProtos[pb.User](db.M("users").Where("status = ?", 1))
+func ScanProtos[T any](t *Table) ([]*T, error) {
+	return ScanProtosWithContext[T](t.context(), t)
+}
+
+// ScanProtosWithContext 带上下文的ScanProtos
+func ScanProtosWithContext[T any](ctx context.Context, t *Table) ([]*T, error) {
+	rows, err := t.findAllWithContext(ctx, "scanProtos")
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]*T, len(rows))
+	for i, row := range rows {
+		msgs[i] = new(T)
+		if err := MapProto(row, msgs[i]); err != nil {
+			return nil, fmt.Errorf("映射查询结果失败: %v", err)
+		}
+	}
+	return msgs, nil
+}
+
+// protoFields 解析结构体字段，返回可匹配的字段名到字段下标的映射
+func protoFields(typ reflect.Type) map[string]int {
+	if v, ok := protoFieldCache.Load(typ); ok {
+		return v.(map[string]int)
+	}
+	fields := make(map[string]int)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		// 跳过 state、sizeCache 等内部字段及 oneof 字段
+		if !field.IsExported() || field.Tag.Get("protobuf_oneof") != "" {
+			continue
+		}
+		names := []string{field.Name, toSnakeCase(field.Name)}
+		for _, part := range strings.Split(field.Tag.Get("protobuf"), ",") {
+			switch {
+			case strings.HasPrefix(part, "name="):
+				names = append(names, strings.TrimPrefix(part, "name="))
+			case strings.HasPrefix(part, "json="):
+				names = append(names, strings.TrimPrefix(part, "json="))
+			}
+		}
+		if jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ","); jsonName != "" && jsonName != "-" {
+			names = append(names, jsonName)
+		}
+		for _, name := range names {
+			if _, exists := fields[name]; !exists {
+				fields[name] = i
+			}
+			if lower := strings.ToLower(name); lower != name {
+				if _, exists := fields[lower]; !exists {
+					fields[lower] = i
+				}
+			}
+		}
+	}
+	protoFieldCache.Store(typ, fields)
+	return fields
+}
+
+// assignProtoValue 为 protobuf 字段赋值，处理知名类型
+func assignProtoValue(field reflect.Value, value interface{}) error {
+	if field.Kind() != reflect.Ptr || field.Type().Elem().Kind() != reflect.Struct {
+		return assignValue(field, value)
+	}
+	elemType := field.Type().Elem()
+	switch {
+	case elemType.PkgPath() == protoTimestampPkg && elemType.Name() == "Timestamp":
+		if value == nil {
+			field.SetZero()
+			return nil
+		}
+		var tm time.Time
+		if err := assignValue(reflect.ValueOf(&tm).Elem(), value); err != nil {
+			return err
+		}
+		ts := reflect.New(elemType)
+		ts.Elem().FieldByName("Seconds").SetInt(tm.Unix())
+		ts.Elem().FieldByName("Nanos").SetInt(int64(tm.Nanosecond()))
+		field.Set(ts)
+		return nil
+	case elemType.PkgPath() == protoWrappersPkg && strings.HasSuffix(elemType.Name(), "Value"):
+		if value == nil {
+			field.SetZero()
+			return nil
+		}
+		wrapper := reflect.New(elemType)
+		if err := assignValue(wrapper.Elem().FieldByName("Value"), value); err != nil {
+			return err
+		}
+		field.Set(wrapper)
+		return nil
+	}
+	return fmt.Errorf("不支持的字段类型: %s", field.Type())
+}
@@ -0,0 +1,279 @@
+package xlorm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minTagPruneSize 表关联的缓存键达到该数量后才清理过期的键
+const minTagPruneSize = 64
+
+// TagCache 支持按标签失效的缓存，查询缓存与表的关联保存在缓存后端中，
+// 多个实例共享同一缓存时，任一实例的写操作都能使其他实例写入的相关缓存失效；
+// 未实现该接口的缓存，表关联只保存在当前进程中
+type TagCache interface {
+	Cache
+	// SetWithTags 设置缓存并将键关联到各标签，关联随缓存过期清理
+	SetWithTags(key string, value interface{}, expiration time.Duration, tags ...string) error
+	// InvalidateTags 删除与各标签关联的缓存
+	InvalidateTags(tags ...string) error
+}
+
+// MultiDeleter 支持批量删除的缓存，InvalidateCache 会优先使用批量删除
+type MultiDeleter interface {
+	DeleteMulti(keys ...string) error
+}
+
+// queryCacheState 查询结果缓存及表与缓存键的关联
+type queryCacheState struct {
+	mu    sync.RWMutex
+	cache Cache
+	tags  map[string]*tagIndex // 表名 -> 缓存键，仅用于未实现 TagCache 的缓存
+	gens  map[string]uint64    // 表名 -> 失效次数，用于发现写缓存期间发生的失效
+}
+
+// tagIndex 表关联的缓存键及其过期时间（零值表示不过期）
+type tagIndex struct {
+	keys      map[string]time.Time
+	pruneSize int // 键数量达到该值时清理过期的键
+}
+
+// newQueryCacheState 创建查询结果缓存状态
+func newQueryCacheState(cache Cache) *queryCacheState {
+	return &queryCacheState{
+		cache: cache,
+		tags:  make(map[string]*tagIndex),
+		gens:  make(map[string]uint64),
+	}
+}
+
+// add 关联缓存键，键数量翻倍时清理已过期的键
+func (idx *tagIndex) add(key string, expireAt time.Time) {
+	idx.keys[key] = expireAt
+	if len(idx.keys) < idx.pruneSize {
+		return
+	}
+	now := time.Now()
+	for k, exp := range idx.keys {
+		if !exp.IsZero() && now.After(exp) {
+			delete(idx.keys, k)
+		}
+	}
+	idx.pruneSize = max(len(idx.keys)*2, minTagPruneSize)
+}
+
+// SetQueryCache 设置查询结果缓存，传 nil 时关闭 Table.Cache 的缓存功能
+// 多个实例共享 Redis 等外部缓存时，缓存需实现 TagCache，否则写操作只能使当前进程写入的缓存失效
+func (db *DB) SetQueryCache(cache Cache) {
+	db.queryCache.mu.Lock()
+	defer db.queryCache.mu.Unlock()
+	db.queryCache.cache = cache
+	db.queryCache.tags = make(map[string]*tagIndex)
+}
+
+// Cache 缓存本次查询（Find/FindAll）的结果，需先通过 Config.QueryCache 或 SetQueryCache 配置缓存
+// 缓存键与当前表关联，通过本库对该表执行 Insert/Update/Delete 等写操作后自动失效；
+// 关联查询可通过 tables 指定其他相关表（不含表前缀），任一表发生写操作时缓存失效
+// 例如：db.M("users").Where("status = ?", 1).Cache("users:active", time.Minute).FindAll()
+func (t *Table) Cache(key string, ttl time.Duration, tables ...string) *Table {
+	if t.misused("Cache") {
+		return t
+	}
+	if key == "" {
+		t.db.logger.Error("缓存键不能为空", "table", t.tableName)
+		return t
+	}
+	t.cacheKey = key
+	t.cacheAuto = false
+	t.cacheTTL = ttl
+	t.cacheTables = tables
+	return t
+}
+
+// Cacheable 将本次查询标记为可缓存，缓存键由最终的SQL和参数自动生成，其他行为与 Cache 相同
+// 例如：db.M("products").Where("category_id = ?", id).Cacheable(5*time.Minute).FindAll()
+func (t *Table) Cacheable(ttl time.Duration, tables ...string) *Table {
+	if t.misused("Cacheable") {
+		return t
+	}
+	if ttl <= 0 {
+		t.db.logger.Error("缓存时间必须为正数", "table", t.tableName, "ttl", ttl)
+		return t
+	}
+	t.cacheKey = ""
+	t.cacheAuto = true
+	t.cacheTTL = ttl
+	t.cacheTables = tables
+	return t
+}
+
+// autoCacheKey 根据查询SQL和参数生成缓存键
+// 生成SQL会重置条件标志，完成后恢复，保证随后实际执行的查询与缓存键一致
+func (t *Table) autoCacheKey() string {
+	flags, index := t.conditionFlags, t.conditionIndex
+	query, args := t.buildQuery("SELECT")
+	t.conditionFlags, t.conditionIndex = flags, index
+	h := sha256.New()
+	h.Write([]byte(query))
+	for _, arg := range args {
+		fmt.Fprintf(h, "\x00%T:%v", arg, arg)
+	}
+	return "xlorm:query:" + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// InvalidateTableCache 使与指定表（不含表前缀）关联的查询缓存失效，用于原生SQL等未经本库的写操作之后
+func (db *DB) InvalidateTableCache(tables ...string) error {
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = strings.ReplaceAll(db.GetTableName(table), "`", "")
+	}
+	return db.invalidateTables(names...)
+}
+
+// getCachedRows 读取缓存的查询结果，返回结果的副本
+func (t *Table) getCachedRows() ([]map[string]interface{}, bool) {
+	t.db.queryCache.mu.RLock()
+	cache := t.db.queryCache.cache
+	t.db.queryCache.mu.RUnlock()
+	if cache == nil {
+		return nil, false
+	}
+	value, ok := cache.Get(t.cacheKey)
+	if !ok {
+		t.db.asyncDBMetrics.RecordCacheMiss()
+		return nil, false
+	}
+
+	var rows []map[string]interface{}
+	switch v := value.(type) {
+	case []map[string]interface{}:
+		rows = copyCachedRows(v)
+	case []interface{}:
+		// JSON、msgpack 等序列化方式反序列化后的结果
+		rows = make([]map[string]interface{}, 0, len(v))
+		for _, item := range v {
+			row, ok := item.(map[string]interface{})
+			if !ok {
+				t.db.asyncDBMetrics.RecordCacheMiss()
+				return nil, false
+			}
+			rows = append(rows, row)
+		}
+	default:
+		t.db.asyncDBMetrics.RecordCacheMiss()
+		return nil, false
+	}
+	t.db.asyncDBMetrics.RecordCacheHit()
+	return rows, true
+}
+
+// copyCachedRows 复制查询结果，避免调用方修改返回的结果影响缓存（进程内缓存直接保存结果本身）
+func copyCachedRows(rows []map[string]interface{}) []map[string]interface{} {
+	copied := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		copied[i] = make(map[string]interface{}, len(row))
+		for k, val := range row {
+			if b, ok := val.([]byte); ok {
+				val = bytes.Clone(b)
+			}
+			copied[i][k] = val
+		}
+	}
+	return copied
+}
+
+// setCachedRows 缓存查询结果的副本并关联到相关表，写缓存期间不持有锁
+func (t *Table) setCachedRows(rows []map[string]interface{}) {
+	tags := []string{t.rawTableName()}
+	for _, table := range t.cacheTables {
+		tags = append(tags, strings.ReplaceAll(t.db.GetTableName(table), "`", ""))
+	}
+
+	qc := t.db.queryCache
+	qc.mu.RLock()
+	cache := qc.cache
+	gens := make([]uint64, len(tags))
+	for i, tag := range tags {
+		gens[i] = qc.gens[tag]
+	}
+	qc.mu.RUnlock()
+	if cache == nil {
+		return
+	}
+	rows = copyCachedRows(rows)
+	if tc, ok := cache.(TagCache); ok {
+		if err := tc.SetWithTags(t.cacheKey, rows, t.cacheTTL, tags...); err != nil {
+			t.db.logger.Error("设置缓存失败", "key", t.cacheKey, "error", err)
+		}
+		return
+	}
+	if err := cache.Set(t.cacheKey, rows, t.cacheTTL); err != nil {
+		t.db.logger.Error("设置缓存失败", "key", t.cacheKey, "error", err)
+		return
+	}
+
+	var expireAt time.Time
+	if t.cacheTTL > 0 {
+		expireAt = time.Now().Add(t.cacheTTL)
+	}
+	qc.mu.Lock()
+	stale := qc.cache != cache
+	for i, tag := range tags {
+		if qc.gens[tag] != gens[i] {
+			stale = true
+		}
+	}
+	if !stale {
+		for _, tag := range tags {
+			idx := qc.tags[tag]
+			if idx == nil {
+				idx = &tagIndex{keys: make(map[string]time.Time), pruneSize: minTagPruneSize}
+				qc.tags[tag] = idx
+			}
+			idx.add(t.cacheKey, expireAt)
+		}
+	}
+	qc.mu.Unlock()
+	if stale {
+		// 写缓存期间相关表已失效，删除刚写入的结果以免读到旧数据
+		if err := cache.Delete(t.cacheKey); err != nil {
+			t.db.logger.Error("删除缓存失败", "key", t.cacheKey, "error", err)
+		}
+	}
+}
+
+// invalidateTables 使与指定表（完整表名）关联的查询缓存失效
+func (db *DB) invalidateTables(tables ...string) error {
+	db.queryCache.mu.Lock()
+	cache := db.queryCache.cache
+	var keys []string
+	for _, table := range tables {
+		db.queryCache.gens[table]++
+		if idx := db.queryCache.tags[table]; idx != nil {
+			for key := range idx.keys {
+				keys = append(keys, key)
+			}
+		}
+		delete(db.queryCache.tags, table)
+	}
+	db.queryCache.mu.Unlock()
+	if tc, ok := cache.(TagCache); ok {
+		return tc.InvalidateTags(tables...)
+	}
+	if cache == nil || len(keys) == 0 {
+		return nil
+	}
+	return db.InvalidateCache(cache, keys...)
+}
+
+// invalidateCache 写操作后使与当前表关联的查询缓存失效
+func (t *Table) invalidateCache() {
+	if err := t.db.invalidateTables(t.rawTableName()); err != nil {
+		t.db.logger.Error("使查询缓存失效失败", "table", t.tableName, "error", err)
+	}
+}
@@ -0,0 +1,65 @@
+package xlorm
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+// selectStatements 过滤出指定表的查询语句
+func selectStatements(statements []string, table string) []string {
+	var selects []string
+	for _, statement := range statements {
+		if strings.HasPrefix(statement, "SELECT") && strings.Contains(statement, "FROM `"+table+"`") {
+			selects = append(selects, statement)
+		}
+	}
+	return selects
+}
+
+func TestCacheableKeepsOrConditions(t *testing.T) {
+	db, connector := newFakeDB(t, nil)
+	db.SetQueryCache(NewMemoryCache(MemoryCacheOptions{}))
+
+	if _, err := db.M("users").Where("a = ?", 1).OrWhere("b = ?", 2).Cacheable(time.Minute).FindAll(); err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	selects := selectStatements(connector.statements(), "users")
+	if len(selects) != 1 || !strings.Contains(selects[0], "WHERE (a = ? OR b = ?)") {
+		t.Fatalf("生成缓存键后 OR 条件丢失: %v", selects)
+	}
+}
+
+func TestCachedRowsAreNotSharedWithCaller(t *testing.T) {
+	db, connector := newFakeDB(t, func(query string, _ []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.Contains(query, "FROM `users`") {
+			return []string{"id", "name"}, [][]driver.Value{{int64(1), []byte("alice")}}, nil
+		}
+		return nil, nil, nil
+	})
+	db.SetQueryCache(NewMemoryCache(MemoryCacheOptions{}))
+
+	rows, err := db.M("users").Cache("users:all", time.Minute).FindAll()
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	rows[0]["id"] = "MUTATED"
+	if name, ok := rows[0]["name"].([]byte); ok && len(name) > 0 {
+		name[0] = 'X'
+	}
+
+	cached, err := db.M("users").Cache("users:all", time.Minute).FindAll()
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if n := len(selectStatements(connector.statements(), "users")); n != 1 {
+		t.Fatalf("查询执行了 %d 次，want 1", n)
+	}
+	if cached[0]["id"] != int64(1) {
+		t.Fatalf("缓存的结果被调用方修改: id = %v", cached[0]["id"])
+	}
+	if name, ok := cached[0]["name"].([]byte); ok && string(name) != "alice" {
+		t.Fatalf("缓存的结果被调用方修改: name = %s", name)
+	}
+}
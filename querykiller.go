@@ -0,0 +1,117 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	instanceAttrName = "xlorm_instance" // 标识本实例连接的连接属性名
+)
+
+// KilledQuery 被终止的查询
+type KilledQuery struct {
+	ID       int64         // 连接ID
+	Duration time.Duration // 已执行时长
+	Query    string        // SQL语句
+}
+
+// KillLongQueries 终止本实例发起的、执行时长超过 limit 的查询（KILL QUERY）
+// 通过连接属性 xlorm_instance 识别本实例的连接，需要开启 performance_schema
+// 包含 QueryKillerAllowlist 中任一关键字的查询不会被终止
+func (db *DB) KillLongQueries(ctx context.Context, limit time.Duration) ([]KilledQuery, error) {
+	if db == nil || db.DB == nil {
+		return nil, errors.New("数据库连接为空")
+	}
+	if limit < time.Second {
+		return nil, errors.New("查询时长限制不能小于1秒")
+	}
+
+	// PROCESSLIST.TIME 精度为秒，向上取整
+	seconds := int64((limit + time.Second - 1) / time.Second)
+	query := "SELECT p.ID, p.TIME, p.INFO FROM information_schema.PROCESSLIST p " +
+		"JOIN performance_schema.session_connect_attrs a ON a.PROCESSLIST_ID = p.ID " +
+		"WHERE a.ATTR_NAME = ? AND a.ATTR_VALUE = ? AND p.COMMAND = 'Query' " +
+		"AND p.TIME >= ? AND p.ID <> CONNECTION_ID() AND p.INFO IS NOT NULL"
+	rows, err := db.QueryContext(ctx, query, instanceAttrName, db.instanceID, seconds)
+	if err != nil {
+		return nil, fmt.Errorf("读取进程列表失败: %v", err)
+	}
+
+	var candidates []KilledQuery
+	for rows.Next() {
+		var q KilledQuery
+		var elapsed int64
+		if err := rows.Scan(&q.ID, &elapsed, &q.Query); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("读取进程列表失败: %v", err)
+		}
+		q.Duration = time.Duration(elapsed) * time.Second
+		if db.isQueryKillAllowed(q.Query) {
+			continue
+		}
+		candidates = append(candidates, q)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取进程列表失败: %v", err)
+	}
+
+	var killed []KilledQuery
+	var errs []error
+	for _, q := range candidates {
+		// KILL 不支持占位符，ID 为整数，直接拼接
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", q.ID)); err != nil {
+			errs = append(errs, fmt.Errorf("终止查询 %d 失败: %v", q.ID, err))
+			continue
+		}
+		db.asyncDBMetrics.RecordQueryKilled()
+		db.logger.Warn("已终止长时间运行的查询",
+			"connection_id", q.ID,
+			"duration", q.Duration,
+			"limit", limit,
+			"sql", q.Query,
+		)
+		killed = append(killed, q)
+	}
+	return killed, errors.Join(errs...)
+}
+
+// isQueryKillAllowed 判断查询是否在白名单中
+func (db *DB) isQueryKillAllowed(query string) bool {
+	if len(db.queryKillerAllowlist) == 0 {
+		return false
+	}
+	upper := strings.ToUpper(query)
+	for _, keyword := range db.queryKillerAllowlist {
+		if keyword != "" && strings.Contains(upper, strings.ToUpper(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// killLongQueriesLoop 定期终止长时间运行的查询
+func (db *DB) killLongQueriesLoop(ctx context.Context, interval, limit time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	db.logger.Debug("开启长查询终止协程")
+	for {
+		select {
+		case <-ticker.C:
+			killCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			_, err := db.KillLongQueries(killCtx, limit)
+			cancel()
+			if err != nil && !errors.Is(err, context.Canceled) {
+				db.logger.Error("长查询终止失败", "error", err)
+			}
+		case <-ctx.Done():
+			db.logger.Debug("停止长查询终止协程")
+			return nil
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package xlorm
+
+import (
+	"context"
+	"maps"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// queryTagsKey 查询标签的上下文键
+type queryTagsKey struct{}
+
+// WithQueryTags 返回携带查询标签的上下文，使用该上下文执行的SQL末尾会追加 sqlcommenter 格式的注释，
+// 与上下文中已有的标签合并，同名标签以新值为准，例如：
+//
+//	ctx = xlorm.WithQueryTags(ctx, map[string]string{"route": "/pay", "controller": "order"})
+func WithQueryTags(ctx context.Context, tags map[string]string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	merged := maps.Clone(queryTagsFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(tags))
+	}
+	maps.Copy(merged, tags)
+	return context.WithValue(ctx, queryTagsKey{}, merged)
+}
+
+// queryTagsFromContext 获取上下文中的查询标签
+func queryTagsFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	tags, _ := ctx.Value(queryTagsKey{}).(map[string]string)
+	return tags
+}
+
+// WithQueryTag 设置追加到每条SQL的全局查询标签（如 app、env），以 sqlcommenter 格式写入SQL末尾的注释：
+//
+//	SELECT * FROM `orders` WHERE `id` = ? /*app='checkout',route='%2Fpay',trace_id='abc'*/
+//
+// 便于 DBA 在 performance_schema、慢查询日志中定位调用方；上下文中的标签（WithQueryTags）和追踪ID会一并写入
+// 通过 WarmStatements 预热的语句不追加标签，否则每次执行的SQL都不同，无法复用预编译语句
+// 传入 nil 时清除全局标签，仅在上下文携带标签时追加注释
+func (db *DB) WithQueryTag(tags map[string]string) *DB {
+	if len(tags) == 0 {
+		db.queryTags.Store(nil)
+		return db
+	}
+	tags = maps.Clone(tags)
+	db.queryTags.Store(&tags)
+	return db
+}
+
+// tagQuery 为即将执行的SQL追加查询标签注释，未设置标签、SQL已以注释结尾或为预热语句时原样返回
+// 仅用于发送到数据库的SQL，日志、指纹和缓存键仍使用原始SQL
+func (db *DB) tagQuery(ctx context.Context, query string) string {
+	global := db.queryTags.Load()
+	local := queryTagsFromContext(ctx)
+	if (global == nil && len(local) == 0) || strings.HasSuffix(strings.TrimSpace(query), "*/") {
+		return query
+	}
+	if db.stmtWarmer != nil && db.stmtWarmer.contains(query) {
+		return query
+	}
+	tags := make(map[string]string, len(local)+2)
+	if global != nil {
+		maps.Copy(tags, *global)
+	}
+	maps.Copy(tags, local)
+	if traceID := TraceIDFromContext(ctx); traceID != "" {
+		tags["trace_id"] = traceID
+	}
+	return query + " " + formatQueryTags(tags)
+}
+
+// formatQueryTags 按 sqlcommenter 规范序列化标签：键值 URL 编码，值加单引号，按键排序
+func formatQueryTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString("/*")
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(sqlCommenterEscape(key))
+		sb.WriteString("='")
+		sb.WriteString(sqlCommenterEscape(tags[key]))
+		sb.WriteByte('\'')
+	}
+	sb.WriteString("*/")
+	return sb.String()
+}
+
+// sqlCommenterEscape URL 编码标签的键或值，空格编码为 %20
+func sqlCommenterEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,260 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReconcileOptions 数据同步选项
+type ReconcileOptions struct {
+	DeleteMissing bool // 是否删除当前数据中存在、desired 中不存在的记录（物理删除）
+	DryRun        bool // 仅计算差异，不执行
+}
+
+// ReconcileResult 数据同步结果
+type ReconcileResult struct {
+	Inserted   int              // 插入的记录数
+	Updated    int              // 更新的记录数
+	Deleted    int              // 删除的记录数
+	Statements []BatchStatement // 执行（或 DryRun 时将要执行）的SQL语句
+}
+
+// Reconcile 将表数据同步为 desired：按 keyCols 比较当前数据，计算需要插入、更新、删除的记录并在同一事务中执行
+// 当前数据范围由已设置的查询条件决定，例如 db.M("products").Where("tenant_id = ?", 1).Reconcile(...) 只同步该租户的数据
+// 更新时仅修改有变化的字段，执行顺序为删除、更新、插入
+func (t *Table) Reconcile(desired []map[string]interface{}, keyCols []string, opts ReconcileOptions) (*ReconcileResult, error) {
+	return t.ReconcileWithContext(t.context(), desired, keyCols, opts)
+}
+
+// ReconcileWithContext 带上下文的Reconcile
+func (t *Table) ReconcileWithContext(ctx context.Context, desired []map[string]interface{}, keyCols []string, opts ReconcileOptions) (*ReconcileResult, error) {
+	ctx = t.resolveContext(ctx)
+	db := t.db
+	table := t.name
+	startTime := time.Now()
+
+	// 写操作使用与读取相同的表前缀
+	t.resolvePrefix(ctx)
+	prefix := db.tablePre
+	if t.prefixSet {
+		prefix = t.prefix
+	}
+	writeCtx := WithTablePrefix(ctx, prefix)
+
+	desiredByKey, err := indexReconcileRows(desired, keyCols)
+	if err != nil {
+		t.Release()
+		return nil, err
+	}
+
+	// 读取当前数据
+	t.fields = nil
+	t.limit = 0
+	t.offset = 0
+	t.hasTotal = false
+	current, err := t.findAllWithContext(ctx, "reconcile")
+	if err != nil {
+		return nil, fmt.Errorf("读取当前数据失败: %v", err)
+	}
+
+	result := &ReconcileResult{}
+	var deletes, updates, inserts Batch
+	seen := make(map[string]bool, len(current))
+	for _, row := range current {
+		key := reconcileKey(row, keyCols)
+		seen[key] = true
+		want, ok := desiredByKey[key]
+		if !ok {
+			if opts.DeleteMissing {
+				condition, args := reconcileCondition(row, keyCols)
+				deletes.Delete(table, condition, args...)
+				result.Deleted++
+			}
+			continue
+		}
+
+		// 仅更新有变化的字段
+		changed := make(map[string]interface{})
+		for field, value := range want {
+			if slices.Contains(keyCols, field) {
+				continue
+			}
+			if have, exists := row[field]; !exists || !reconcileValueEqual(have, value) {
+				changed[field] = value
+			}
+		}
+		if len(changed) > 0 {
+			condition, args := reconcileCondition(row, keyCols)
+			updates.Update(table, changed, condition, args...)
+			result.Updated++
+		}
+	}
+
+	// 按 desired 的顺序插入新记录
+	for _, row := range desired {
+		if !seen[reconcileKey(row, keyCols)] {
+			inserts.Insert(table, row)
+			result.Inserted++
+		}
+	}
+
+	var batch Batch
+	batch.ops = append(batch.ops, deletes.ops...)
+	batch.ops = append(batch.ops, updates.ops...)
+	batch.ops = append(batch.ops, inserts.ops...)
+	if batch.Len() == 0 {
+		return result, nil
+	}
+
+	result.Statements, err = db.renderBatch(writeCtx, &batch)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+	if _, err := db.RunBatch(writeCtx, &batch); err != nil {
+		return nil, fmt.Errorf("同步数据失败: %v", err)
+	}
+
+	db.logger.Info("数据同步完成",
+		"table", table,
+		"inserted", result.Inserted,
+		"updated", result.Updated,
+		"deleted", result.Deleted,
+		"duration", time.Since(startTime).Seconds(),
+	)
+	return result, nil
+}
+
+// indexReconcileRows 校验 desired 并按键建立索引
+func indexReconcileRows(desired []map[string]interface{}, keyCols []string) (map[string]map[string]interface{}, error) {
+	if len(keyCols) == 0 {
+		return nil, errors.New("必须指定键字段")
+	}
+	for _, col := range keyCols {
+		if !isValidFieldName(col) {
+			return nil, fmt.Errorf("键字段非法: %s", col)
+		}
+	}
+	index := make(map[string]map[string]interface{}, len(desired))
+	for i, row := range desired {
+		for _, col := range keyCols {
+			if v, ok := row[col]; !ok || v == nil {
+				return nil, fmt.Errorf("第 %d 条记录缺少键字段 %s", i+1, col)
+			}
+		}
+		key := reconcileKey(row, keyCols)
+		if _, exists := index[key]; exists {
+			return nil, fmt.Errorf("第 %d 条记录的键重复: %s", i+1, key)
+		}
+		index[key] = row
+	}
+	return index, nil
+}
+
+// reconcileKey 生成记录的键
+func reconcileKey(row map[string]interface{}, keyCols []string) string {
+	parts := make([]string, len(keyCols))
+	for i, col := range keyCols {
+		parts[i] = reconcileValueString(row[col])
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// reconcileCondition 生成按键匹配记录的条件
+func reconcileCondition(row map[string]interface{}, keyCols []string) (string, []interface{}) {
+	cols := append([]string(nil), keyCols...)
+	sort.Strings(cols)
+	conditions := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+	for i, col := range cols {
+		conditions[i] = quoteColumn(col) + " = ?"
+		args[i] = row[col]
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// reconcileValueEqual 比较数据库中的值与期望值，忽略 int/int64、[]byte/string 等类型差异；
+// 任一方为数值时按数值比较（DECIMAL 以文本返回，"12.50" 与 12.5 相等），时间按时刻比较，不受时区影响
+func reconcileValueEqual(current, want interface{}) bool {
+	if current == nil || want == nil {
+		return current == nil && want == nil
+	}
+	if a, ok := current.(time.Time); ok {
+		if b, ok := want.(time.Time); ok {
+			return a.Equal(b)
+		}
+	}
+	if isReconcileNumber(current) || isReconcileNumber(want) {
+		if a, ok := reconcileNumber(current); ok {
+			if b, ok := reconcileNumber(want); ok {
+				return a.Cmp(b) == 0
+			}
+		}
+	}
+	return reconcileValueString(current) == reconcileValueString(want)
+}
+
+// isReconcileNumber 判断值是否为数值类型
+func isReconcileNumber(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
+
+// reconcileNumber 将数值或数值文本转换为精确的有理数
+func reconcileNumber(v interface{}) (*big.Rat, bool) {
+	switch val := v.(type) {
+	case int, int8, int16, int32, int64:
+		return new(big.Rat).SetInt64(reflect.ValueOf(val).Int()), true
+	case uint, uint8, uint16, uint32, uint64:
+		return new(big.Rat).SetUint64(reflect.ValueOf(val).Uint()), true
+	case float32:
+		return reconcileParseNumber(strconv.FormatFloat(float64(val), 'g', -1, 32))
+	case float64:
+		return reconcileParseNumber(strconv.FormatFloat(val, 'g', -1, 64))
+	case []byte:
+		return reconcileParseNumber(strings.TrimSpace(string(val)))
+	case string:
+		return reconcileParseNumber(strings.TrimSpace(val))
+	}
+	return nil, false
+}
+
+// reconcileParseNumber 解析数值文本，非法或非有限值返回 false
+func reconcileParseNumber(text string) (*big.Rat, bool) {
+	if text == "" {
+		return nil, false
+	}
+	return new(big.Rat).SetString(text)
+}
+
+// reconcileValueString 将值转换为用于比较的字符串
+func reconcileValueString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "\x00NULL"
+	case []byte:
+		return string(val)
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		return val.Format("2006-01-02 15:04:05.999999")
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,62 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrPanic 操作中发生 panic（扫描、钩子、回调等）并已恢复时返回的错误，可通过 errors.Is 判断
+var ErrPanic = errors.New("操作发生panic")
+
+// PanicError 已恢复的 panic
+type PanicError struct {
+	Operation string      // 操作类型
+	Table     string      // 表名
+	TraceID   string      // 追踪ID
+	Value     interface{} // panic 的值
+	Stack     []byte      // 发生 panic 时的调用栈
+}
+
+// Error 实现 error 接口
+func (e *PanicError) Error() string {
+	if e.TraceID != "" {
+		return fmt.Sprintf("%s %s 发生panic（trace_id=%s）: %v", e.Operation, e.Table, e.TraceID, e.Value)
+	}
+	return fmt.Sprintf("%s %s 发生panic: %v", e.Operation, e.Table, e.Value)
+}
+
+// Unwrap 支持 errors.Is(err, ErrPanic)，panic 的值为 error 时同样可以匹配
+func (e *PanicError) Unwrap() []error {
+	if err, ok := e.Value.(error); ok {
+		return []error{ErrPanic, err}
+	}
+	return []error{ErrPanic}
+}
+
+// recoverPanic 恢复 Table 操作中的 panic 并转换为 PanicError，需直接通过 defer 调用：
+//
+//	defer t.db.recoverPanic(ctx, "insert", t.rawTableName(), &err)
+func (db *DB) recoverPanic(ctx context.Context, operation, table string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	pe := &PanicError{
+		Operation: operation,
+		Table:     table,
+		TraceID:   TraceIDFromContext(ctx),
+		Value:     r,
+		Stack:     debug.Stack(),
+	}
+	db.asyncDBMetrics.RecordPanic()
+	db.logger.Error("操作发生panic",
+		"operation", operation,
+		"table", table,
+		"trace_id", pe.TraceID,
+		"panic", r,
+		"stack", string(pe.Stack),
+	)
+	*err = pe
+}
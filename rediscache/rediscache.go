@@ -0,0 +1,233 @@
+// Package rediscache 提供基于 Redis 的 xlorm 查询缓存实现，独立为子模块，未使用时核心库不依赖 go-redis 和 msgpack
+package rediscache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/jiankeluoluo/xlorm"
+	"github.com/redis/go-redis/v9"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	defaultRedisCacheTimeout = 500 * time.Millisecond // 默认 Redis 操作超时时间
+	redisInvalidateBatchSize = 500                    // 批量删除时每个 pipeline 的键数量
+)
+
+// Codec 缓存值序列化接口
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// 内置的缓存值序列化实现
+var (
+	JSONCodec    Codec = jsonCodec{}    // JSON，数字反序列化为 float64
+	GobCodec     Codec = gobCodec{}     // gob，自定义类型需先调用 gob.Register 注册
+	MsgpackCodec Codec = msgpackCodec{} // msgpack，体积小、速度快
+)
+
+func init() {
+	// 注册查询结果常用类型，使其可作为 interface{} 经 gob 序列化
+	gob.Register(map[string]interface{}{})
+	gob.Register([]map[string]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register(time.Time{})
+}
+
+// jsonCodec JSON 序列化
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// gobCodec gob 序列化
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	// 以 interface{} 编码，保留值的动态类型
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// msgpackCodec msgpack 序列化
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error { return msgpack.Unmarshal(data, v) }
+
+// Options Redis 缓存选项
+type Options struct {
+	KeyPrefix string        // 键前缀，默认为 xlorm:<DBName>:
+	Codec     Codec         // 序列化方式，默认为 JSONCodec
+	Timeout   time.Duration // 单次 Redis 操作超时时间，默认500毫秒
+}
+
+// Cache 基于 Redis 的 xlorm.Cache 及 xlorm.TagCache 实现
+type Cache struct {
+	db      *xlorm.DB
+	client  redis.UniversalClient
+	prefix  string
+	codec   Codec
+	timeout time.Duration
+}
+
+var (
+	_ xlorm.TagCache     = (*Cache)(nil)
+	_ xlorm.MultiDeleter = (*Cache)(nil)
+)
+
+// New 创建 Redis 缓存，client 支持单机、哨兵和集群客户端，错误日志写入 db 的日志
+// 例如：
+//
+//	cache := rediscache.New(db, redis.NewClient(&redis.Options{Addr: "127.0.0.1:6379"}), rediscache.Options{Codec: rediscache.MsgpackCodec})
+//	db.SetQueryCache(cache)
+func New(db *xlorm.DB, client redis.UniversalClient, opts Options) *Cache {
+	if opts.KeyPrefix == "" {
+		opts.KeyPrefix = "xlorm:" + db.GetDBName() + ":"
+	}
+	if opts.Codec == nil {
+		opts.Codec = JSONCodec
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultRedisCacheTimeout
+	}
+	return &Cache{
+		db:      db,
+		client:  client,
+		prefix:  opts.KeyPrefix,
+		codec:   opts.Codec,
+		timeout: opts.Timeout,
+	}
+}
+
+// Get 获取缓存，键不存在、Redis 出错或反序列化失败时视为未命中
+func (c *Cache) Get(key string) (interface{}, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			c.db.Logger().Error("读取Redis缓存失败", "key", key, "error", err)
+		}
+		return nil, false
+	}
+	var value interface{}
+	if err := c.codec.Unmarshal(data, &value); err != nil {
+		c.db.Logger().Error("反序列化缓存失败", "key", key, "error", err)
+		return nil, false
+	}
+	return value, true
+}
+
+// Set 设置缓存，expiration 为0时不过期
+func (c *Cache) Set(key string, value interface{}, expiration time.Duration) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("序列化缓存失败: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	return c.client.Set(ctx, c.prefix+key, data, expiration).Err()
+}
+
+// Delete 删除缓存
+func (c *Cache) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	return c.client.Del(ctx, c.prefix+key).Err()
+}
+
+// DeleteMulti 通过 pipeline 批量删除缓存，每个键单独 DEL，兼容集群模式下键分布在不同槽的情况
+func (c *Cache) DeleteMulti(keys ...string) error {
+	for start := 0; start < len(keys); start += redisInvalidateBatchSize {
+		end := min(start+redisInvalidateBatchSize, len(keys))
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys[start:end] {
+				pipe.Del(ctx, c.prefix+key)
+			}
+			return nil
+		})
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagKey 标签对应的有序集合键
+func (c *Cache) tagKey(tag string) string {
+	return c.prefix + "tag:" + tag
+}
+
+// SetWithTags 设置缓存并将键记入各标签的有序集合（分值为过期时间），同时清理标签中已过期的键，
+// 关联保存在 Redis 中，共享同一 Redis 的多个实例可互相失效
+func (c *Cache) SetWithTags(key string, value interface{}, expiration time.Duration, tags ...string) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("序列化缓存失败: %v", err)
+	}
+	now := time.Now()
+	score := math.Inf(1)
+	if expiration > 0 {
+		score = float64(now.Add(expiration).UnixMilli())
+	}
+	expired := "(" + strconv.FormatInt(now.UnixMilli(), 10)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, c.prefix+key, data, expiration)
+		for _, tag := range tags {
+			pipe.ZAdd(ctx, c.tagKey(tag), redis.Z{Score: score, Member: key})
+			pipe.ZRemRangeByScore(ctx, c.tagKey(tag), "-inf", expired)
+		}
+		return nil
+	})
+	return err
+}
+
+// InvalidateTags 删除与各标签关联的缓存，只移除已删除的关联，保留失效期间新写入的关联
+func (c *Cache) InvalidateTags(tags ...string) error {
+	for _, tag := range tags {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		keys, err := c.client.ZRange(ctx, c.tagKey(tag), 0, -1).Result()
+		cancel()
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		if err := c.DeleteMulti(keys...); err != nil {
+			return err
+		}
+		members := make([]interface{}, len(keys))
+		for i, key := range keys {
+			members[i] = key
+		}
+		ctx, cancel = context.WithTimeout(context.Background(), c.timeout)
+		err = c.client.ZRem(ctx, c.tagKey(tag), members...).Err()
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
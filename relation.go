@@ -0,0 +1,370 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// preloadBatchSize 预加载关联记录时每条 IN 查询的最大键数量
+const preloadBatchSize = 1000
+
+// RelationKind 关联类型
+type RelationKind int
+
+const (
+	HasMany   RelationKind = iota // 一对多，关联表的 ForeignKey 引用本表的 References
+	BelongsTo                     // 多对一，本表的 ForeignKey 引用关联表的 References
+)
+
+// String 返回关联类型名称
+func (k RelationKind) String() string {
+	switch k {
+	case HasMany:
+		return "has_many"
+	case BelongsTo:
+		return "belongs_to"
+	default:
+		return fmt.Sprintf("RelationKind(%d)", int(k))
+	}
+}
+
+// Relation 关联定义，也可通过结构体标签声明，例如：
+//
+//	type User struct {
+//		ID     int64   `db:"id"`
+//		Orders []Order `rel:"has_many,table=orders,foreign_key=user_id"`
+//	}
+//	type Order struct {
+//		ID     int64 `db:"id"`
+//		UserID int64 `db:"user_id"`
+//		User   *User `rel:"belongs_to,table=users,foreign_key=user_id"`
+//	}
+//
+// 带 rel 标签的字段不参与字段映射
+type Relation struct {
+	Kind       RelationKind
+	Table      string // 关联表名（不含表前缀）
+	ForeignKey string // 外键字段：HasMany 为关联表的字段，BelongsTo 为本表的字段
+	References string // 被引用的字段：HasMany 为本表的字段，BelongsTo 为关联表的字段，默认 id
+}
+
+// relationRegistry 通过 RegisterRelation 注册的关联，键为结构体类型和字段名
+type relationRegistry struct {
+	mu        sync.RWMutex
+	relations map[reflect.Type]map[string]Relation
+}
+
+// RegisterRelation 为模型的字段注册关联，优先于结构体标签，字段需设置 db:"-" 避免参与字段映射，例如：
+//
+//	db.RegisterRelation(User{}, "Orders", xlorm.Relation{Kind: xlorm.HasMany, Table: "orders", ForeignKey: "user_id"})
+func (db *DB) RegisterRelation(model interface{}, field string, rel Relation) error {
+	typ := reflect.TypeOf(model)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return fmt.Errorf("模型必须为结构体: %T", model)
+	}
+	if _, ok := typ.FieldByName(field); !ok {
+		return fmt.Errorf("模型 %s 没有字段 %s", typ, field)
+	}
+	if err := rel.validate(); err != nil {
+		return err
+	}
+	db.relations.mu.Lock()
+	defer db.relations.mu.Unlock()
+	if db.relations.relations == nil {
+		db.relations.relations = make(map[reflect.Type]map[string]Relation)
+	}
+	if db.relations.relations[typ] == nil {
+		db.relations.relations[typ] = make(map[string]Relation)
+	}
+	db.relations.relations[typ][field] = rel
+	return nil
+}
+
+// Preload 查询结果映射为结构体时（FindAllInto）批量加载指定的关联字段，
+// 每个关联按 WHERE key IN (...) 分批查询，避免逐条查询的 N+1 问题
+func (t *Table) Preload(fields ...string) *Table {
+	if t.misused("Preload") {
+		return t
+	}
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			t.preloads = append(t.preloads, field)
+		}
+	}
+	return t
+}
+
+// FindAllInto 查询多条记录并映射到 dest（结构体切片指针，元素可以是结构体或结构体指针），
+// 然后加载 Preload 指定的关联，例如：
+//
+//	var users []User
+//	err := db.M("users").Where("status = ?", 1).Preload("Orders").FindAllInto(&users)
+func (t *Table) FindAllInto(dest interface{}) error {
+	return t.FindAllIntoWithContext(t.context(), dest)
+}
+
+// FindAllIntoWithContext 带上下文的FindAllInto
+func (t *Table) FindAllIntoWithContext(ctx context.Context, dest interface{}) error {
+	ctx = t.resolveContext(ctx)
+	slice, elemType, err := structSliceOf(dest)
+	if err != nil {
+		t.Release()
+		return err
+	}
+	preloads := t.preloads
+	db := t.db
+	table := t.rawTableName()
+
+	// 关联字段需要查询到引用的字段
+	relations := make([]Relation, len(preloads))
+	for i, field := range preloads {
+		if relations[i], err = db.relationOf(elemType, field); err != nil {
+			t.Release()
+			return err
+		}
+		if len(t.fields) > 0 {
+			t.fields = appendField(t.fields, relations[i].parentKey())
+		}
+	}
+
+	rows, err := t.findAllWithContext(ctx, "findAllInto")
+	if err != nil {
+		return err
+	}
+	items := reflect.MakeSlice(slice.Type(), len(rows), len(rows))
+	for i, row := range rows {
+		item := items.Index(i)
+		if item.Kind() == reflect.Ptr {
+			item.Set(reflect.New(elemType))
+			item = item.Elem()
+		}
+		if err := db.StructMapper.MapToStruct(row, item.Addr().Interface()); err != nil {
+			return wrapScanError(err, table, i+1)
+		}
+	}
+	for i, field := range preloads {
+		if err := db.preload(ctx, rows, items, field, relations[i]); err != nil {
+			return err
+		}
+	}
+	slice.Set(items)
+	return nil
+}
+
+// preload 加载一个关联字段并写入 items，rows 为与 items 一一对应的查询结果
+func (db *DB) preload(ctx context.Context, rows []map[string]interface{}, items reflect.Value, field string, rel Relation) error {
+	parentKey, childKey := rel.parentKey(), rel.childKey()
+	var keys []interface{}
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		value := row[parentKey]
+		if value == nil {
+			continue
+		}
+		if k := relationKey(value); !seen[k] {
+			seen[k] = true
+			keys = append(keys, value)
+		}
+	}
+
+	children := make(map[string][]map[string]interface{}, len(keys))
+	for start := 0; start < len(keys); start += preloadBatchSize {
+		end := min(start+preloadBatchSize, len(keys))
+		// 关联字段由开发者定义，不受字段白名单限制
+		childRows, err := db.Table(rel.Table).whereIn(childKey, keys[start:end], false).FindAllWithContext(ctx)
+		if err != nil {
+			return fmt.Errorf("预加载关联 %s 失败: %w", field, err)
+		}
+		for _, row := range childRows {
+			k := relationKey(row[childKey])
+			children[k] = append(children[k], row)
+		}
+	}
+
+	for i, row := range rows {
+		item := items.Index(i)
+		if item.Kind() == reflect.Ptr {
+			item = item.Elem()
+		}
+		target := item.FieldByName(field)
+		value := row[parentKey]
+		if value == nil {
+			continue
+		}
+		matched := children[relationKey(value)]
+		if err := db.assignRelation(target, matched, rel); err != nil {
+			return fmt.Errorf("预加载关联 %s 失败: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// assignRelation 将关联记录映射后写入字段，HasMany 字段为切片，BelongsTo 字段为结构体或结构体指针
+func (db *DB) assignRelation(target reflect.Value, rows []map[string]interface{}, rel Relation) error {
+	if rel.Kind == BelongsTo {
+		if len(rows) == 0 {
+			return nil
+		}
+		dest := target
+		if target.Kind() == reflect.Ptr {
+			dest = reflect.New(target.Type().Elem())
+			target.Set(dest)
+		} else {
+			dest = target.Addr()
+		}
+		if err := db.StructMapper.MapToStruct(rows[0], dest.Interface()); err != nil {
+			return wrapScanError(err, rel.Table, 1)
+		}
+		return nil
+	}
+
+	items := reflect.MakeSlice(target.Type(), len(rows), len(rows))
+	for i, row := range rows {
+		item := items.Index(i)
+		if item.Kind() == reflect.Ptr {
+			item.Set(reflect.New(item.Type().Elem()))
+			item = item.Elem()
+		}
+		if err := db.StructMapper.MapToStruct(row, item.Addr().Interface()); err != nil {
+			return wrapScanError(err, rel.Table, i+1)
+		}
+	}
+	target.Set(items)
+	return nil
+}
+
+// relationOf 获取结构体字段的关联定义，优先使用 RegisterRelation 注册的定义，其次解析 rel 标签
+func (db *DB) relationOf(typ reflect.Type, field string) (Relation, error) {
+	structField, ok := typ.FieldByName(field)
+	if !ok {
+		return Relation{}, fmt.Errorf("模型 %s 没有字段 %s", typ, field)
+	}
+
+	db.relations.mu.RLock()
+	rel, ok := db.relations.relations[typ][field]
+	db.relations.mu.RUnlock()
+	if !ok {
+		tag := structField.Tag.Get("rel")
+		if tag == "" {
+			return Relation{}, fmt.Errorf("字段 %s.%s 未定义关联", typ, field)
+		}
+		var err error
+		if rel, err = parseRelationTag(tag); err != nil {
+			return Relation{}, fmt.Errorf("字段 %s.%s 的关联标签非法: %v", typ, field, err)
+		}
+	}
+	if err := rel.validate(); err != nil {
+		return Relation{}, err
+	}
+
+	fieldType := structField.Type
+	if rel.Kind == HasMany {
+		if fieldType.Kind() != reflect.Slice {
+			return Relation{}, fmt.Errorf("一对多关联字段 %s.%s 必须为切片", typ, field)
+		}
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() != reflect.Struct {
+		return Relation{}, fmt.Errorf("关联字段 %s.%s 的类型必须为结构体", typ, field)
+	}
+	return rel, nil
+}
+
+// parseRelationTag 解析关联标签，例如 has_many,table=orders,foreign_key=user_id,references=id
+func parseRelationTag(tag string) (Relation, error) {
+	parts := strings.Split(tag, ",")
+	var rel Relation
+	switch strings.TrimSpace(parts[0]) {
+	case "has_many":
+		rel.Kind = HasMany
+	case "belongs_to":
+		rel.Kind = BelongsTo
+	default:
+		return Relation{}, fmt.Errorf("未知的关联类型: %s", parts[0])
+	}
+	for _, part := range parts[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "table":
+			rel.Table = value
+		case "foreign_key":
+			rel.ForeignKey = value
+		case "references":
+			rel.References = value
+		default:
+			return Relation{}, fmt.Errorf("未知的关联选项: %s", part)
+		}
+	}
+	return rel, nil
+}
+
+// validate 校验关联定义
+func (r *Relation) validate() error {
+	if r.Kind != HasMany && r.Kind != BelongsTo {
+		return fmt.Errorf("未知的关联类型: %s", r.Kind)
+	}
+	if r.Table == "" || strings.ContainsAny(r.Table, ";\x00 ") {
+		return fmt.Errorf("关联表名非法: %s", r.Table)
+	}
+	if r.References == "" {
+		r.References = "id"
+	}
+	if !isValidFieldName(r.ForeignKey) || strings.Contains(r.ForeignKey, ".") {
+		return fmt.Errorf("关联外键字段非法: %s", r.ForeignKey)
+	}
+	if !isValidFieldName(r.References) || strings.Contains(r.References, ".") {
+		return fmt.Errorf("关联引用字段非法: %s", r.References)
+	}
+	return nil
+}
+
+// parentKey 本表中用于关联的字段
+func (r Relation) parentKey() string {
+	if r.Kind == BelongsTo {
+		return r.ForeignKey
+	}
+	return r.References
+}
+
+// childKey 关联表中用于关联的字段
+func (r Relation) childKey() string {
+	if r.Kind == BelongsTo {
+		return r.References
+	}
+	return r.ForeignKey
+}
+
+// relationKey 统一关联键的格式，[]byte 按字符串处理
+func relationKey(value interface{}) string {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return loaderKey(value)
+}
+
+// structSliceOf 校验 dest 为结构体切片指针，返回切片和元素的结构体类型
+func structSliceOf(dest interface{}) (reflect.Value, reflect.Type, error) {
+	val := reflect.ValueOf(dest)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Slice {
+		return reflect.Value{}, nil, errors.New("dest 必须为结构体切片指针")
+	}
+	slice := val.Elem()
+	elemType := slice.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return reflect.Value{}, nil, errors.New("dest 必须为结构体切片指针")
+	}
+	return slice, elemType, nil
+}
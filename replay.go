@@ -0,0 +1,237 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReplayOptions 慢查询回放选项
+type ReplayOptions struct {
+	Runs int // 每条SQL的执行次数，取最短耗时（默认1）
+}
+
+// ReplayResult 慢查询回放结果
+type ReplayResult struct {
+	Query    SlowQuery     // 原始慢查询
+	Before   time.Duration // 捕获时的耗时
+	After    time.Duration // 回放耗时
+	Plan     string        // 执行计划，优先使用 EXPLAIN ANALYZE，不支持时为 EXPLAIN 的输出
+	Analyzed bool          // Plan 是否为 EXPLAIN ANALYZE 的输出
+	Skipped  bool          // 非只读查询不回放
+	Err      error         // 回放错误
+}
+
+// Speedup 回放耗时相对捕获耗时的加速比，大于1表示变快
+func (r ReplayResult) Speedup() float64 {
+	if r.After <= 0 {
+		return 0
+	}
+	return float64(r.Before) / float64(r.After)
+}
+
+// ReplayComparison 两次回放结果的对比
+type ReplayComparison struct {
+	Fingerprint string        // SQL指纹
+	Query       string        // SQL语句
+	Before      time.Duration // 第一次回放耗时
+	After       time.Duration // 第二次回放耗时
+	Speedup     float64       // 加速比，大于1表示变快
+	PlanChanged bool          // 执行计划是否变化（EXPLAIN ANALYZE 输出包含耗时，仅比较 EXPLAIN 输出时准确）
+}
+
+// ReplaySlowQueries 在当前数据库上回放慢查询（通常来自另一实例的 SlowQueries），用于验证索引调整的效果
+// 仅回放 SELECT 及不含写操作的 WITH 查询，并在只读事务中执行后回滚；相同指纹的SQL只回放耗时最长的一条
+// 例如：
+//
+//	before, _ := staging.ReplaySlowQueries(ctx, prod.SlowQueries(), xlorm.ReplayOptions{Runs: 3})
+//	// 添加索引后再次回放
+//	after, _ := staging.ReplaySlowQueries(ctx, prod.SlowQueries(), xlorm.ReplayOptions{Runs: 3})
+//	for _, c := range xlorm.CompareReplays(before, after) { ... }
+func (db *DB) ReplaySlowQueries(ctx context.Context, queries []SlowQuery, opts ReplayOptions) ([]ReplayResult, error) {
+	if db == nil || db.DB == nil {
+		return nil, errors.New("数据库连接为空")
+	}
+	if opts.Runs <= 0 {
+		opts.Runs = 1
+	}
+
+	// 按指纹去重，保留耗时最长的一条
+	var unique []SlowQuery
+	index := make(map[string]int)
+	for _, q := range queries {
+		fingerprint := replayFingerprint(q)
+		if i, ok := index[fingerprint]; ok {
+			if q.Duration > unique[i].Duration {
+				unique[i] = q
+			}
+			continue
+		}
+		index[fingerprint] = len(unique)
+		unique = append(unique, q)
+	}
+
+	results := make([]ReplayResult, 0, len(unique))
+	for _, q := range unique {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result := ReplayResult{Query: q, Before: q.Duration}
+		if !isReadOnlyQuery(q.Query) {
+			result.Skipped = true
+			results = append(results, result)
+			continue
+		}
+		for run := 0; run < opts.Runs; run++ {
+			duration, plan, analyzed, err := db.replayQuery(ctx, q)
+			if err != nil {
+				result.Err = err
+				break
+			}
+			if result.After == 0 || duration < result.After {
+				result.After = duration
+			}
+			result.Plan, result.Analyzed = plan, analyzed
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// CompareReplays 按SQL指纹对比两次回放的结果，用于比较索引调整前后的耗时和执行计划
+func CompareReplays(before, after []ReplayResult) []ReplayComparison {
+	afterByFingerprint := make(map[string]ReplayResult, len(after))
+	for _, r := range after {
+		afterByFingerprint[replayFingerprint(r.Query)] = r
+	}
+	var comparisons []ReplayComparison
+	for _, b := range before {
+		fingerprint := replayFingerprint(b.Query)
+		a, ok := afterByFingerprint[fingerprint]
+		if !ok || b.Skipped || a.Skipped || b.Err != nil || a.Err != nil {
+			continue
+		}
+		comparison := ReplayComparison{
+			Fingerprint: fingerprint,
+			Query:       b.Query.Query,
+			Before:      b.After,
+			After:       a.After,
+			PlanChanged: b.Plan != a.Plan,
+		}
+		if a.After > 0 {
+			comparison.Speedup = float64(b.After) / float64(a.After)
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	return comparisons
+}
+
+// replayQuery 在只读事务中回放一条SQL，返回耗时和执行计划
+func (db *DB) replayQuery(ctx context.Context, q SlowQuery) (time.Duration, string, bool, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return 0, "", false, fmt.Errorf("开始只读事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	// EXPLAIN ANALYZE 会实际执行SQL并输出各步骤的耗时（MySQL 8.0.18+）
+	start := time.Now()
+	plan, err := queryPlan(ctx, tx, "EXPLAIN ANALYZE "+q.Query, q.Args)
+	if err == nil {
+		return time.Since(start), plan, true, nil
+	}
+
+	// 不支持 EXPLAIN ANALYZE 时直接执行SQL计时，执行计划使用 EXPLAIN
+	start = time.Now()
+	rows, err := tx.QueryContext(ctx, q.Query, q.Args...)
+	if err != nil {
+		return 0, "", false, fmt.Errorf("回放SQL失败: %v", err)
+	}
+	for rows.Next() {
+	}
+	err = rows.Err()
+	rows.Close()
+	duration := time.Since(start)
+	if err != nil {
+		return 0, "", false, fmt.Errorf("回放SQL失败: %v", err)
+	}
+	plan, err = queryPlan(ctx, tx, "EXPLAIN "+q.Query, q.Args)
+	if err != nil {
+		return duration, "", false, fmt.Errorf("获取执行计划失败: %v", err)
+	}
+	return duration, plan, false, nil
+}
+
+// queryPlan 执行 EXPLAIN 并将结果格式化为文本，每行一条记录
+func queryPlan(ctx context.Context, tx *sql.Tx, query string, args []interface{}) (string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	values := make([]sql.NullString, len(columns))
+	scanArgs := make([]interface{}, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	var plan strings.Builder
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return "", err
+		}
+		// EXPLAIN ANALYZE 只有一列树形文本
+		if len(columns) == 1 {
+			plan.WriteString(values[0].String)
+			plan.WriteByte('\n')
+			continue
+		}
+		for i, col := range columns {
+			if i > 0 {
+				plan.WriteByte(' ')
+			}
+			value := "NULL"
+			if values[i].Valid {
+				value = values[i].String
+			}
+			plan.WriteString(col + "=" + value)
+		}
+		plan.WriteByte('\n')
+	}
+	return strings.TrimSpace(plan.String()), rows.Err()
+}
+
+// isReadOnlyQuery 判断SQL是否为不加锁的只读查询
+func isReadOnlyQuery(query string) bool {
+	words := strings.Fields(strings.ToUpper(query))
+	if len(words) == 0 || (words[0] != "SELECT" && words[0] != "WITH" && words[0] != "(SELECT") {
+		return false
+	}
+	for i, word := range words {
+		switch strings.Trim(word, "(),") {
+		case "INSERT", "UPDATE", "DELETE", "REPLACE", "INTO":
+			// SELECT ... FOR UPDATE 的 UPDATE 同样视为写操作
+			return false
+		case "SHARE", "LOCK":
+			if i > 0 && (words[i-1] == "FOR" || words[i-1] == "IN") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// replayFingerprint 获取慢查询的SQL指纹
+func replayFingerprint(q SlowQuery) string {
+	if q.Fingerprint != "" {
+		return q.Fingerprint
+	}
+	return QueryFingerprint(q.Query)
+}
@@ -0,0 +1,100 @@
+package xlorm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errEmptyRaw 原始SQL片段为空
+var errEmptyRaw = errors.New("原始SQL片段不能为空")
+
+// RawSQL 受信任的原始SQL片段，用于 OrderByRaw、GroupByRaw、HavingRaw，不做任何校验直接拼接到SQL中，
+// 只能包含开发者编写的固定SQL，不能包含用户输入
+type RawSQL struct {
+	sql string
+}
+
+// Raw 创建原始SQL片段，例如：db.M("users").OrderByRaw(xlorm.Raw("FIELD(status, 2, 1, 0)"))
+func Raw(sql string) RawSQL {
+	return RawSQL{sql: sql}
+}
+
+// Raw 创建原始SQL片段，等同于 xlorm.Raw
+func (db *DB) Raw(sql string) RawSQL {
+	return Raw(sql)
+}
+
+// String 返回SQL片段
+func (r RawSQL) String() string {
+	return r.sql
+}
+
+// escapeColumnPath 校验并引用字段名，支持 table.column 和 database.table.column 形式，
+// 用户输入的排序、分组字段只允许字母、数字和下划线
+func escapeColumnPath(name string) (string, error) {
+	parts, err := splitQualifiedName(name)
+	if err != nil {
+		return "", err
+	}
+	for _, part := range parts {
+		if !isValidFieldName(part) || strings.Contains(part, ".") {
+			return "", fmt.Errorf("字段名非法: %s", name)
+		}
+	}
+	return joinQualifiedName(parts), nil
+}
+
+// sanitizeOrderBy 将排序子句解析为字段和方向后重新生成，例如 "name desc, u.id" 生成 `name` DESC, `u`.`id`
+// 只允许字段名和 ASC/DESC，函数、表达式等需使用 OrderByRaw
+func sanitizeOrderBy(order string) (string, error) {
+	if strings.ContainsAny(order, "();\x00") {
+		return "", fmt.Errorf("排序条件包含非法字符，表达式请使用 OrderByRaw: %s", order)
+	}
+	items := strings.Split(order, ",")
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		parts := strings.Fields(item)
+		if len(parts) == 0 || len(parts) > 2 {
+			return "", fmt.Errorf("排序条件非法: %s", order)
+		}
+		column, err := escapeColumnPath(parts[0])
+		if err != nil {
+			return "", err
+		}
+		if len(parts) == 2 {
+			direction := strings.ToUpper(parts[1])
+			if direction != "ASC" && direction != "DESC" {
+				return "", fmt.Errorf("排序方向非法: %s", parts[1])
+			}
+			column += " " + direction
+		}
+		result = append(result, column)
+	}
+	return strings.Join(result, ", "), nil
+}
+
+// sanitizeGroupBy 将分组子句解析为字段后重新生成，只允许以逗号分隔的字段名，表达式需使用 GroupByRaw
+func sanitizeGroupBy(groupBy string) (string, error) {
+	if strings.ContainsAny(groupBy, "();\x00") {
+		return "", fmt.Errorf("分组条件包含非法字符，表达式请使用 GroupByRaw: %s", groupBy)
+	}
+	items := strings.Split(groupBy, ",")
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		column, err := escapeColumnPath(strings.TrimSpace(item))
+		if err != nil {
+			return "", err
+		}
+		result = append(result, column)
+	}
+	return strings.Join(result, ", "), nil
+}
+
+// sanitizeHaving 校验分组过滤条件，拒绝括号、分号、注释和引号，聚合函数等表达式需使用 HavingRaw
+func sanitizeHaving(having string) error {
+	if strings.ContainsAny(having, "();\x00'\"#") || strings.Contains(having, "--") || strings.Contains(having, "/*") {
+		return fmt.Errorf("分组过滤条件包含非法字符，表达式请使用 HavingRaw: %s", having)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package xlorm
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// scanColumnIndexExpr 匹配 database/sql 扫描错误中的列下标
+var scanColumnIndexExpr = regexp.MustCompile(`Scan error on column index (\d+)`)
+
+// ErrColumnMismatch 严格映射模式下结果列与结构体字段不一致
+var ErrColumnMismatch = errors.New("结果列与结构体字段不一致")
+
+// scannerType sql.Scanner 接口类型
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// ColumnMismatchError 严格映射模式下结果列与结构体字段不一致的详情，可通过 errors.Is(err, ErrColumnMismatch) 判断
+type ColumnMismatchError struct {
+	Type    string   // 结构体类型
+	Unknown []string // 结果中存在但结构体没有对应字段的列
+	Missing []string // 结构体字段在结果中缺失的列
+}
+
+// Error 实现 error 接口
+func (e *ColumnMismatchError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrColumnMismatch.Error())
+	b.WriteString(": ")
+	b.WriteString(e.Type)
+	if len(e.Unknown) > 0 {
+		b.WriteString(" 多余的列 ")
+		b.WriteString(strings.Join(e.Unknown, ", "))
+	}
+	if len(e.Missing) > 0 {
+		b.WriteString(" 缺失的列 ")
+		b.WriteString(strings.Join(e.Missing, ", "))
+	}
+	return b.String()
+}
+
+// Unwrap 返回 ErrColumnMismatch
+func (e *ColumnMismatchError) Unwrap() error {
+	return ErrColumnMismatch
+}
+
+// ScanError 扫描或映射查询结果失败的错误，包含出错的表、列、行号及目标类型
+// 可通过 errors.As 获取，例如：
+//
+//	var se *xlorm.ScanError
+//	if errors.As(err, &se) { log.Println(se.Table, se.Column, se.Row) }
+type ScanError struct {
+	Table  string      // 表名，未知时为空
+	Column string      // 列名，未知时为空
+	Field  string      // 结构体字段名，扫描到 map 时为空
+	Row    int         // 出错的行号（从 1 开始），未知时为 0
+	GoType string      // 目标 Go 类型
+	Value  interface{} // 数据库返回的原始值，rows.Scan 失败时为 nil
+	Err    error       // 原始错误
+}
+
+// Error 实现 error 接口
+func (e *ScanError) Error() string {
+	var b strings.Builder
+	b.WriteString("扫描数据失败")
+	if e.Table != "" {
+		b.WriteString(": 表 ")
+		b.WriteString(e.Table)
+	}
+	if e.Row > 0 {
+		fmt.Fprintf(&b, " 第 %d 行", e.Row)
+	}
+	if e.Column != "" {
+		b.WriteString(" 列 ")
+		b.WriteString(e.Column)
+	}
+	if e.Field != "" {
+		b.WriteString(" 字段 ")
+		b.WriteString(e.Field)
+	}
+	if e.GoType != "" {
+		b.WriteString(" 目标类型 ")
+		b.WriteString(e.GoType)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+// Unwrap 返回原始错误
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+// wrapScanError 为扫描或映射错误补充表名和行号，err 不是 ScanError 时新建
+func wrapScanError(err error, table string, row int) error {
+	var se *ScanError
+	if !errors.As(err, &se) {
+		return &ScanError{Table: table, Row: row, Err: err}
+	}
+	if se.Table == "" {
+		se.Table = table
+	}
+	if se.Row == 0 {
+		se.Row = row
+	}
+	return se
+}
+
+// newRowsScanError 将 rows.Scan 的错误转换为 ScanError，根据错误信息中的列下标找到列名
+func newRowsScanError(err error, table string, columns []string, dest []interface{}, row int) error {
+	se := &ScanError{Table: table, Row: row, Err: err}
+	if m := scanColumnIndexExpr.FindStringSubmatch(err.Error()); m != nil {
+		if i, convErr := strconv.Atoi(m[1]); convErr == nil && i < len(columns) {
+			se.Column = columns[i]
+			if i < len(dest) && dest[i] != nil {
+				se.GoType = reflect.TypeOf(dest[i]).Elem().String()
+			}
+		}
+	}
+	return se
+}
@@ -0,0 +1,78 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+const (
+	defaultSchemaVersionTable  = "schema_migrations" // 默认迁移表
+	defaultSchemaVersionColumn = "version"           // 默认版本字段
+)
+
+// ErrSchemaVersion 数据库结构版本与代码要求的版本不一致
+var ErrSchemaVersion = errors.New("数据库结构版本不兼容")
+
+// SchemaVersionError 数据库结构版本不一致的详细信息
+type SchemaVersionError struct {
+	Required int64 // 代码要求的版本
+	Current  int64 // 数据库当前的版本
+}
+
+// Error 返回错误描述
+func (e *SchemaVersionError) Error() string {
+	if e.Current < e.Required {
+		return fmt.Sprintf("数据库结构版本 %d 低于代码要求的版本 %d，请先执行迁移", e.Current, e.Required)
+	}
+	return fmt.Sprintf("数据库结构版本 %d 高于代码要求的版本 %d，请升级代码", e.Current, e.Required)
+}
+
+// Unwrap 支持 errors.Is(err, ErrSchemaVersion)
+func (e *SchemaVersionError) Unwrap() error {
+	return ErrSchemaVersion
+}
+
+// SchemaVersion 获取数据库当前的结构版本，即迁移表中版本字段的最大值，迁移表为空时返回0
+func (db *DB) SchemaVersion() (int64, error) {
+	return db.SchemaVersionWithContext(db.GetContext())
+}
+
+// SchemaVersionWithContext 带上下文的SchemaVersion
+func (db *DB) SchemaVersionWithContext(ctx context.Context) (int64, error) {
+	if db == nil || db.DB == nil {
+		return 0, errors.New("数据库连接为空")
+	}
+	table, column := db.config.SchemaVersionTable, db.config.SchemaVersionColumn
+	if table == "" {
+		table = defaultSchemaVersionTable
+	}
+	if column == "" {
+		column = defaultSchemaVersionColumn
+	}
+	var version sql.NullInt64
+	query := fmt.Sprintf("SELECT MAX(%s) FROM %s", quoteColumn(column), quoteColumn(table))
+	if err := db.DB.QueryRowContext(ctx, query).Scan(&version); err != nil {
+		return 0, fmt.Errorf("读取数据库结构版本失败: %v", err)
+	}
+	return version.Int64, nil
+}
+
+// RequireSchemaVersion 校验数据库结构版本是否等于代码要求的版本 n，数据库版本过旧或过新时返回 *SchemaVersionError，
+// 用于发布时避免新旧代码与数据库结构混用；配置 Config.RequiredSchemaVersion 时启动阶段自动校验
+func (db *DB) RequireSchemaVersion(n int64) error {
+	return db.RequireSchemaVersionWithContext(db.GetContext(), n)
+}
+
+// RequireSchemaVersionWithContext 带上下文的RequireSchemaVersion
+func (db *DB) RequireSchemaVersionWithContext(ctx context.Context, n int64) error {
+	current, err := db.SchemaVersionWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if current != n {
+		return &SchemaVersionError{Required: n, Current: current}
+	}
+	return nil
+}
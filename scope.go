@@ -0,0 +1,123 @@
+package xlorm
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Scope 全局作用域，在查询、更新、删除前对表操作追加条件，例如多租户隔离：
+//
+//	db.AddGlobalScope(func(t *xlorm.Table) {
+//		if tenantID, ok := TenantFromContext(t.Context()); ok {
+//			t.Where("tenant_id = ?", tenantID)
+//		}
+//	})
+//
+// 作用域中通过 Where、OrWhere、WhereIn 等添加的条件整体加括号后以 AND 连接在查询条件之后，
+// 不会被调用方的 OR 条件绕过；调用 WithoutGlobalScopes 可忽略全部作用域（Unscoped 只忽略软删除过滤）
+type Scope func(t *Table)
+
+// scopeRegistry 全局作用域注册表
+type scopeRegistry struct {
+	mu     sync.RWMutex
+	global []Scope            // 对所有表生效的作用域
+	tables map[string][]Scope // 表级作用域，键为完整表名
+}
+
+// newScopeRegistry 创建作用域注册表
+func newScopeRegistry() *scopeRegistry {
+	return &scopeRegistry{tables: make(map[string][]Scope)}
+}
+
+// AddGlobalScope 注册对所有表生效的作用域
+func (db *DB) AddGlobalScope(scope Scope) *DB {
+	if scope == nil || db.scopes == nil {
+		return db
+	}
+	db.scopes.mu.Lock()
+	defer db.scopes.mu.Unlock()
+	db.scopes.global = append(db.scopes.global, scope)
+	return db
+}
+
+// AddTableScope 注册表级作用域，tableName 为不含表前缀的表名
+func (db *DB) AddTableScope(tableName string, scope Scope) *DB {
+	if tableName == "" || scope == nil || db.scopes == nil {
+		return db
+	}
+	key := db.GetTableName(tableName)
+	db.scopes.mu.Lock()
+	defer db.scopes.mu.Unlock()
+	db.scopes.tables[key] = append(db.scopes.tables[key], scope)
+	return db
+}
+
+// ClearScopes 清除所有全局和表级作用域
+func (db *DB) ClearScopes() {
+	if db.scopes == nil {
+		return
+	}
+	db.scopes.mu.Lock()
+	defer db.scopes.mu.Unlock()
+	db.scopes.global = nil
+	db.scopes.tables = make(map[string][]Scope)
+}
+
+// Context 获取本次操作的上下文，供作用域读取租户等信息
+func (t *Table) Context() context.Context {
+	return t.context()
+}
+
+// Name 获取调用 M 时传入的表名（不含表前缀）
+func (t *Table) Name() string {
+	return t.name
+}
+
+// WithoutGlobalScopes 本次操作忽略全局和表级作用域，用于后台任务等需要跨租户访问的场景
+func (t *Table) WithoutGlobalScopes() *Table {
+	if t.misused("WithoutGlobalScopes") {
+		return t
+	}
+	t.noGlobalScopes = true
+	return t
+}
+
+// globalScopeConditions 执行全局和表级作用域，返回其添加的条件，每个作用域的条件整体加括号
+func (t *Table) globalScopeConditions() ([]string, []interface{}) {
+	if t.noGlobalScopes || t.db.scopes == nil {
+		return nil, nil
+	}
+	var scopes []Scope
+	t.db.scopes.mu.RLock()
+	scopes = append(scopes, t.db.scopes.global...)
+	scopes = append(scopes, t.db.scopes.tables[t.db.GetTableName(t.name)]...)
+	t.db.scopes.mu.RUnlock()
+	if len(scopes) == 0 {
+		return nil, nil
+	}
+
+	var conditions []string
+	var args []interface{}
+	for _, scope := range scopes {
+		// 在临时表对象上执行，避免修改本次操作的查询条件
+		scratch := tablePool.Get().(*Table)
+		scratch.Reset()
+		scratch.db = t.db
+		scratch.ctx = t.ctx
+		scratch.name = t.name
+		scratch.tableName = t.tableName
+		scratch.prefix = t.prefix
+		scratch.prefixSet = t.prefixSet
+		scratch.shard = t.shard
+		scratch.noGlobalScopes = true
+		scope(scratch)
+		where, whereArgs := scratch.GetWhere(false)
+		scratch.Release()
+		if where = strings.TrimSpace(where); where != "" {
+			conditions = append(conditions, "("+where+")")
+			args = append(args, whereArgs...)
+		}
+	}
+	return conditions, args
+}
@@ -0,0 +1,40 @@
+package xlorm
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGlobalScopeIsNotBypassedByOrConditions(t *testing.T) {
+	var gotQuery string
+	var gotArgs []driver.Value
+	db, _ := newFakeDB(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.Contains(query, "FROM `orders`") {
+			gotQuery, gotArgs = query, args
+		}
+		return nil, nil, nil
+	})
+	db.AddTableScope("orders", func(t *Table) {
+		t.Where("tenant_id = ?", 1)
+	})
+
+	if _, err := db.M("orders").Where("status = 1 OR owner_id = ?", 7).FindAll(); err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if !strings.Contains(gotQuery, "WHERE (status = 1 OR owner_id = ?) AND (tenant_id = ?)") {
+		t.Fatalf("租户条件未与完整查询条件以 AND 连接，其他租户的数据会被返回: %s", gotQuery)
+	}
+	if want := []driver.Value{int64(7), int64(1)}; !reflect.DeepEqual(gotArgs, want) {
+		t.Fatalf("args = %v, want %v", gotArgs, want)
+	}
+
+	// OrWhere 添加的条件同样不能绕过租户条件
+	if _, err := db.M("orders").Where("status = ?", 1).OrWhere("owner_id = ?", 7).FindAll(); err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if !strings.Contains(gotQuery, "WHERE (status = ? OR owner_id = ?) AND (tenant_id = ?)") {
+		t.Fatalf("租户条件未与 OR 条件以 AND 连接: %s", gotQuery)
+	}
+}
@@ -0,0 +1,243 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	minRowBytesPerBatch = 4096 // 默认批量大小下单行可用字节数低于该值时告警
+)
+
+// selfCheckRequiredPrivileges 自检要求的权限
+var selfCheckRequiredPrivileges = []string{"SELECT", "INSERT", "CREATE"}
+
+// SelfCheckWarning 自检告警
+type SelfCheckWarning struct {
+	Check      string // 检查项：privileges、sql_mode、time_zone、max_allowed_packet、charset
+	Message    string // 问题描述
+	Suggestion string // 处理建议
+}
+
+// String 返回告警描述
+func (w SelfCheckWarning) String() string {
+	return fmt.Sprintf("[%s] %s；建议：%s", w.Check, w.Message, w.Suggestion)
+}
+
+// selfCheck 单个自检项
+type selfCheck struct {
+	name string
+	fn   func(ctx context.Context) ([]SelfCheckWarning, error)
+}
+
+// SelfCheck 检查数据库环境是否满足本库的使用要求，返回告警列表
+// 检查项包括：账号权限（SELECT/INSERT/CREATE）、sql_mode 兼容性、时区表及会话时区、
+// max_allowed_packet 与批量写入大小、字符集及排序规则是否与配置一致
+// 单个检查项执行失败时记录为告警，仅在上下文取消或超时时返回错误
+func (db *DB) SelfCheck(ctx context.Context) ([]SelfCheckWarning, error) {
+	if db == nil || db.DB == nil {
+		return nil, errors.New("数据库连接为空")
+	}
+	checks := []selfCheck{
+		{"privileges", db.checkPrivileges},
+		{"sql_mode", db.checkSQLMode},
+		{"time_zone", db.checkTimeZone},
+		{"max_allowed_packet", db.checkMaxAllowedPacket},
+		{"charset", db.checkCharset},
+	}
+
+	var warnings []SelfCheckWarning
+	for _, check := range checks {
+		result, err := check.fn(ctx)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return warnings, fmt.Errorf("自检被中断: %v", ctxErr)
+			}
+			warnings = append(warnings, SelfCheckWarning{
+				Check:      check.name,
+				Message:    fmt.Sprintf("检查失败: %v", err),
+				Suggestion: "确认账号有权限读取系统变量及 information_schema",
+			})
+			continue
+		}
+		warnings = append(warnings, result...)
+	}
+	return warnings, nil
+}
+
+// checkPrivileges 检查当前账号在当前数据库上的权限
+// 通过 SHOW GRANTS 解析直接授予的权限，通过角色授予的权限需在会话中激活后才能识别
+func (db *DB) checkPrivileges(ctx context.Context) ([]SelfCheckWarning, error) {
+	var database string
+	if err := db.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&database); err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, "SHOW GRANTS FOR CURRENT_USER()")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	granted := make(map[string]bool)
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			return nil, err
+		}
+		for _, priv := range parseGrantPrivileges(grant, database) {
+			granted[priv] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if granted["ALL PRIVILEGES"] || granted["ALL"] {
+		return nil, nil
+	}
+
+	var missing []string
+	for _, priv := range selfCheckRequiredPrivileges {
+		if !granted[priv] {
+			missing = append(missing, priv)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	return []SelfCheckWarning{{
+		Check:      "privileges",
+		Message:    fmt.Sprintf("当前账号在数据库 %s 上缺少权限: %s", database, strings.Join(missing, ", ")),
+		Suggestion: fmt.Sprintf("执行 GRANT %s ON `%s`.* TO 当前账号，或确认相关权限已通过角色授予并激活", strings.Join(missing, ", "), database),
+	}}, nil
+}
+
+// parseGrantPrivileges 解析 GRANT 语句中作用于指定数据库（或全局）的权限
+func parseGrantPrivileges(grant, database string) []string {
+	upper := strings.ToUpper(grant)
+	if !strings.HasPrefix(upper, "GRANT ") {
+		return nil
+	}
+	on := strings.Index(upper, " ON ")
+	to := strings.LastIndex(upper, " TO ")
+	if on < 0 || to < on {
+		return nil
+	}
+
+	// 仅统计全局和库级授权
+	target := strings.TrimSpace(grant[on+4 : to])
+	dot := strings.LastIndex(target, ".")
+	if dot < 0 || target[dot+1:] != "*" {
+		return nil
+	}
+	schema := strings.ReplaceAll(strings.Trim(target[:dot], "`"), `\_`, "_")
+	if schema != "*" && schema != database {
+		return nil
+	}
+
+	var privileges []string
+	for _, priv := range strings.Split(upper[len("GRANT "):on], ",") {
+		// 列级权限不视为库级权限
+		if strings.Contains(priv, "(") {
+			continue
+		}
+		privileges = append(privileges, strings.TrimSpace(priv))
+	}
+	return privileges
+}
+
+// checkSQLMode 检查 sql_mode 是否与本库兼容
+func (db *DB) checkSQLMode(ctx context.Context) ([]SelfCheckWarning, error) {
+	current, err := db.SQLMode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	modes := make(map[string]bool, len(current))
+	for _, mode := range current {
+		modes[mode] = true
+	}
+
+	var warnings []SelfCheckWarning
+	if !modes["STRICT_TRANS_TABLES"] && !modes["STRICT_ALL_TABLES"] {
+		warnings = append(warnings, SelfCheckWarning{
+			Check:      "sql_mode",
+			Message:    "未开启严格模式，超长字符串和越界数值会被静默截断",
+			Suggestion: "在 sql_mode 中加入 STRICT_TRANS_TABLES",
+		})
+	}
+	if modes["ANSI_QUOTES"] {
+		warnings = append(warnings, SelfCheckWarning{
+			Check:      "sql_mode",
+			Message:    "已开启 ANSI_QUOTES，条件中双引号包裹的字符串会被当作标识符",
+			Suggestion: "从 sql_mode 中移除 ANSI_QUOTES，或在条件中只使用单引号和 ? 占位符",
+		})
+	}
+	if modes["NO_BACKSLASH_ESCAPES"] {
+		warnings = append(warnings, SelfCheckWarning{
+			Check:      "sql_mode",
+			Message:    "已开启 NO_BACKSLASH_ESCAPES，命名查询等SQL解析对反斜杠转义的处理与服务端不一致",
+			Suggestion: "从 sql_mode 中移除 NO_BACKSLASH_ESCAPES",
+		})
+	}
+	return warnings, nil
+}
+
+// checkTimeZone 检查时区表是否已加载，以及会话时区是否与本地时区一致（DSN 使用 loc=Local）
+func (db *DB) checkTimeZone(ctx context.Context) ([]SelfCheckWarning, error) {
+	var warnings []SelfCheckWarning
+	var converted sql.NullString
+	if err := db.QueryRowContext(ctx, "SELECT CONVERT_TZ('2000-01-01 00:00:00', 'UTC', 'Asia/Shanghai')").Scan(&converted); err != nil {
+		return nil, err
+	}
+	if !converted.Valid {
+		warnings = append(warnings, SelfCheckWarning{
+			Check:      "time_zone",
+			Message:    "时区表未加载，CONVERT_TZ 及命名时区（如 Asia/Shanghai）不可用",
+			Suggestion: "执行 mysql_tzinfo_to_sql /usr/share/zoneinfo | mysql -u root mysql 加载时区表",
+		})
+	}
+
+	var offset int64
+	if err := db.QueryRowContext(ctx, "SELECT TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW())").Scan(&offset); err != nil {
+		return nil, err
+	}
+	_, localOffset := time.Now().Zone()
+	// 两次取时间之间可能跨秒，允许少量误差
+	if diff := offset - int64(localOffset); diff > 60 || diff < -60 {
+		warnings = append(warnings, SelfCheckWarning{
+			Check:      "time_zone",
+			Message:    fmt.Sprintf("数据库会话时区偏移 %s 与本地时区偏移 %s 不一致，DATETIME 字段读写会出现时间偏差", time.Duration(offset)*time.Second, time.Duration(localOffset)*time.Second),
+			Suggestion: "调整数据库 time_zone 或应用所在机器的时区，使两者一致",
+		})
+	}
+	return warnings, nil
+}
+
+// checkMaxAllowedPacket 检查 max_allowed_packet 能否容纳默认批量大小的批量写入
+func (db *DB) checkMaxAllowedPacket(ctx context.Context) ([]SelfCheckWarning, error) {
+	var packet int64
+	if err := db.QueryRowContext(ctx, "SELECT @@max_allowed_packet").Scan(&packet); err != nil {
+		return nil, err
+	}
+	rowBytes := packet / defaultBatchSize
+	if rowBytes >= minRowBytesPerBatch {
+		return nil, nil
+	}
+	return []SelfCheckWarning{{
+		Check:      "max_allowed_packet",
+		Message:    fmt.Sprintf("max_allowed_packet 为 %d 字节，按默认批量大小 %d 行计算，单行平均超过 %d 字节时 BatchInsert/BatchUpdate 会失败", packet, defaultBatchSize, rowBytes),
+		Suggestion: fmt.Sprintf("将 max_allowed_packet 调整到至少 %d 字节，或调用批量方法时传入更小的 batchSize", int64(minRowBytesPerBatch)*defaultBatchSize),
+	}}, nil
+}
+
+// checkCharset 检查连接、数据库及字段的字符集和排序规则是否与配置一致
+func (db *DB) checkCharset(ctx context.Context) ([]SelfCheckWarning, error) {
+	report, err := db.CheckCollation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return report.Warnings(), nil
+}
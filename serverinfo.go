@@ -0,0 +1,194 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedFeature 数据库服务端版本不支持所需的功能
+var ErrUnsupportedFeature = errors.New("数据库版本不支持该功能")
+
+// ServerFlavor 数据库服务端类型
+type ServerFlavor int
+
+const (
+	FlavorUnknown ServerFlavor = iota // 未知（未检测或无法识别）
+	FlavorMySQL                       // MySQL
+	FlavorMariaDB                     // MariaDB
+	FlavorTiDB                        // TiDB
+)
+
+// String 返回服务端类型名称
+func (f ServerFlavor) String() string {
+	switch f {
+	case FlavorMySQL:
+		return "MySQL"
+	case FlavorMariaDB:
+		return "MariaDB"
+	case FlavorTiDB:
+		return "TiDB"
+	default:
+		return "unknown"
+	}
+}
+
+// Feature 依赖服务端版本的SQL功能
+type Feature int
+
+const (
+	FeatureCTE             Feature = iota // 公用表表达式（WITH ...）
+	FeatureSkipLocked                     // FOR UPDATE SKIP LOCKED
+	FeatureJSON                           // JSON 类型及 JSON_* 函数
+	FeatureWindowFunctions                // 窗口函数（... OVER (...)）
+	FeatureValuesTable                    // VALUES ROW(...) 表值构造器
+)
+
+// String 返回功能名称
+func (f Feature) String() string {
+	switch f {
+	case FeatureCTE:
+		return "CTE"
+	case FeatureSkipLocked:
+		return "SKIP LOCKED"
+	case FeatureJSON:
+		return "JSON functions"
+	case FeatureWindowFunctions:
+		return "window functions"
+	case FeatureValuesTable:
+		return "VALUES table constructor"
+	default:
+		return "unknown"
+	}
+}
+
+// featureMinVersions 各类服务端支持功能的最低版本，未列出表示不支持
+var featureMinVersions = map[ServerFlavor]map[Feature][3]int{
+	FlavorMySQL: {
+		FeatureCTE:             {8, 0, 1},
+		FeatureSkipLocked:      {8, 0, 1},
+		FeatureJSON:            {5, 7, 8},
+		FeatureWindowFunctions: {8, 0, 2},
+		FeatureValuesTable:     {8, 0, 19},
+	},
+	FlavorMariaDB: {
+		FeatureCTE:             {10, 2, 1},
+		FeatureSkipLocked:      {10, 6, 0},
+		FeatureJSON:            {10, 2, 7},
+		FeatureWindowFunctions: {10, 2, 0},
+	},
+	FlavorTiDB: {
+		FeatureCTE:             {5, 1, 0},
+		FeatureJSON:            {2, 1, 0},
+		FeatureWindowFunctions: {3, 0, 0},
+	},
+}
+
+// ServerInfo 数据库服务端版本信息，TiDB 的版本号为 TiDB 自身版本而非兼容的 MySQL 版本
+type ServerInfo struct {
+	Version string       // SELECT VERSION() 返回的原始版本字符串
+	Flavor  ServerFlavor // 服务端类型
+	Major   int          // 主版本号
+	Minor   int          // 次版本号
+	Patch   int          // 修订号
+}
+
+// AtLeast 版本是否不低于指定版本
+func (s ServerInfo) AtLeast(major, minor, patch int) bool {
+	if s.Major != major {
+		return s.Major > major
+	}
+	if s.Minor != minor {
+		return s.Minor > minor
+	}
+	return s.Patch >= patch
+}
+
+// Supports 服务端是否支持指定功能，未识别的服务端视为支持，由服务端自行报错
+func (s ServerInfo) Supports(feature Feature) bool {
+	minVersions, ok := featureMinVersions[s.Flavor]
+	if !ok {
+		return true
+	}
+	v, ok := minVersions[feature]
+	return ok && s.AtLeast(v[0], v[1], v[2])
+}
+
+// String 返回服务端类型和版本，如 MySQL 8.0.36
+func (s ServerInfo) String() string {
+	return fmt.Sprintf("%s %d.%d.%d", s.Flavor, s.Major, s.Minor, s.Patch)
+}
+
+// parseServerVersion 解析 SELECT VERSION() 的结果，例如：
+// 8.0.36、5.7.44-log、10.11.6-MariaDB-1:10.11.6+maria~ubu2204、5.5.5-10.6.12-MariaDB、8.0.11-TiDB-v7.5.0
+func parseServerVersion(version string) ServerInfo {
+	info := ServerInfo{Version: version, Flavor: FlavorMySQL}
+	number := version
+	lower := strings.ToLower(version)
+	switch {
+	case strings.Contains(lower, "tidb"):
+		info.Flavor = FlavorTiDB
+		if i := strings.Index(lower, "tidb-v"); i >= 0 {
+			number = version[i+len("tidb-v"):]
+		}
+	case strings.Contains(lower, "mariadb"):
+		info.Flavor = FlavorMariaDB
+		// 旧版本复制协议要求的 5.5.5- 前缀
+		number = strings.TrimPrefix(version, "5.5.5-")
+	}
+	if end := strings.IndexFunc(number, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); end >= 0 {
+		number = number[:end]
+	}
+	parts := strings.SplitN(number, ".", 3)
+	nums := [3]*int{&info.Major, &info.Minor, &info.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		*nums[i] = n
+	}
+	if info.Major == 0 {
+		info.Flavor = FlavorUnknown
+	}
+	return info
+}
+
+// detectServerInfo 查询并保存服务端版本信息
+func (db *DB) detectServerInfo(ctx context.Context) (ServerInfo, error) {
+	var version string
+	if err := db.DB.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return ServerInfo{}, fmt.Errorf("读取数据库版本失败: %v", err)
+	}
+	info := parseServerVersion(version)
+	db.serverInfo.Store(&info)
+	return info, nil
+}
+
+// ServerInfo 获取连接建立时检测到的服务端版本信息，检测失败时 Flavor 为 FlavorUnknown
+func (db *DB) ServerInfo() ServerInfo {
+	if info := db.serverInfo.Load(); info != nil {
+		return *info
+	}
+	return ServerInfo{}
+}
+
+// Supports 服务端是否支持指定功能
+func (db *DB) Supports(feature Feature) bool {
+	return db.ServerInfo().Supports(feature)
+}
+
+// RequireFeature 检查服务端是否支持指定功能，不支持时返回包装了 ErrUnsupportedFeature 的错误，
+// 可在执行依赖该功能的SQL前调用，避免在运行时才得到语法错误
+func (db *DB) RequireFeature(feature Feature) error {
+	info := db.ServerInfo()
+	if info.Supports(feature) {
+		return nil
+	}
+	if v, ok := featureMinVersions[info.Flavor][feature]; ok {
+		return fmt.Errorf("%w: %s 需要 %s %d.%d.%d 及以上版本，当前为 %s", ErrUnsupportedFeature, feature, info.Flavor, v[0], v[1], v[2], info)
+	}
+	return fmt.Errorf("%w: %s 不支持 %s", ErrUnsupportedFeature, info, feature)
+}
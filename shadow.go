@@ -0,0 +1,223 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultShadowQueueSize = 1000             // 默认镜像队列长度
+	shadowQueryTimeout     = 30 * time.Second // 镜像查询超时时间
+)
+
+// ShadowOptions 影子流量镜像选项
+type ShadowOptions struct {
+	SampleRate   float64            // 采样比例（0~1），例如 0.1 表示镜像10%的查询
+	MirrorWrites bool               // 是否同时镜像写操作，影子库应连接到用于验证的临时库
+	QueueSize    int                // 镜像队列长度，队列满时丢弃（默认1000）
+	Workers      int                // 执行镜像查询的协程数（默认1）
+	OnResult     func(ShadowResult) // 每条镜像查询完成后的回调，在镜像协程中调用
+}
+
+// ShadowResult 一次镜像查询的对比结果
+type ShadowResult struct {
+	Operation       string        // 操作类型
+	Query           string        // SQL语句
+	Args            []interface{} // SQL参数
+	Fingerprint     string        // SQL指纹
+	Write           bool          // 是否为写操作
+	PrimaryDuration time.Duration // 主库耗时
+	ShadowDuration  time.Duration // 影子库耗时
+	PrimaryRows     int64         // 主库返回或影响的行数，直接执行 Query 时行数未知为 -1
+	ShadowRows      int64         // 影子库返回或影响的行数
+	Err             error         // 影子库执行错误
+}
+
+// RowsMatch 主库和影子库的行数是否一致，主库行数未知时只要求影子库执行成功
+func (r ShadowResult) RowsMatch() bool {
+	return r.Err == nil && (r.PrimaryRows < 0 || r.PrimaryRows == r.ShadowRows)
+}
+
+// ShadowStats 影子流量镜像统计
+type ShadowStats struct {
+	Mirrored       uint64        // 已镜像的查询数
+	Dropped        uint64        // 队列满时丢弃的查询数
+	Errors         uint64        // 影子库执行失败数
+	RowMismatches  uint64        // 行数不一致数
+	PrimaryTime    time.Duration // 已镜像查询在主库的总耗时
+	ShadowTime     time.Duration // 已镜像查询在影子库的总耗时
+	ShadowSlowdown float64       // 影子库相对主库的耗时倍数，大于1表示更慢
+}
+
+// shadowMirror 影子流量镜像
+type shadowMirror struct {
+	shadow *DB
+	opts   ShadowOptions
+	queue  chan ShadowResult
+
+	mirrored      atomic.Uint64
+	dropped       atomic.Uint64
+	errors        atomic.Uint64
+	rowMismatches atomic.Uint64
+	primaryTime   atomic.Int64
+	shadowTime    atomic.Int64
+}
+
+// SetShadow 将采样的查询异步镜像到影子库执行并对比耗时和行数，用于迁移验证（例如 MySQL 迁移到 TiDB）
+// 默认只镜像只读查询；MirrorWrites 为 true 时同时镜像写操作（事务中的写操作会被逐条镜像，不保证事务性）
+// shadow 为 nil 时关闭镜像。镜像在后台执行，不影响主库查询的耗时和结果
+func (db *DB) SetShadow(shadow *DB, opts ShadowOptions) error {
+	if shadow == db {
+		return errors.New("影子库不能为当前数据库")
+	}
+	if old := db.shadow.Swap(nil); old != nil {
+		_ = db.StopTask(taskShadow)
+	}
+	if shadow == nil {
+		return nil
+	}
+	if opts.SampleRate <= 0 || opts.SampleRate > 1 {
+		return errors.New("采样比例必须在 (0, 1] 之间")
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultShadowQueueSize
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	m := &shadowMirror{
+		shadow: shadow,
+		opts:   opts,
+		queue:  make(chan ShadowResult, opts.QueueSize),
+	}
+	if err := db.RegisterTask(taskShadow, m.run); err != nil {
+		return err
+	}
+	db.shadow.Store(m)
+	return nil
+}
+
+// ShadowStats 获取影子流量镜像统计，未开启镜像时返回零值
+func (db *DB) ShadowStats() ShadowStats {
+	m := db.shadow.Load()
+	if m == nil {
+		return ShadowStats{}
+	}
+	stats := ShadowStats{
+		Mirrored:      m.mirrored.Load(),
+		Dropped:       m.dropped.Load(),
+		Errors:        m.errors.Load(),
+		RowMismatches: m.rowMismatches.Load(),
+		PrimaryTime:   time.Duration(m.primaryTime.Load()),
+		ShadowTime:    time.Duration(m.shadowTime.Load()),
+	}
+	if stats.PrimaryTime > 0 {
+		stats.ShadowSlowdown = float64(stats.ShadowTime) / float64(stats.PrimaryTime)
+	}
+	return stats
+}
+
+// mirrorQuery 在主库查询成功后按采样比例将查询加入镜像队列
+func (db *DB) mirrorQuery(qi *queryInfo, duration time.Duration) {
+	m := db.shadow.Load()
+	if m == nil || qi.err != nil {
+		return
+	}
+	write := !isReadOnlyQuery(qi.query)
+	if (write && !m.opts.MirrorWrites) || rand.Float64() >= m.opts.SampleRate {
+		return
+	}
+	result := ShadowResult{
+		Operation:       qi.operation,
+		Query:           qi.query,
+		Args:            append([]interface{}(nil), qi.args...),
+		Fingerprint:     qi.getFingerprint(),
+		Write:           write,
+		PrimaryDuration: duration,
+		PrimaryRows:     qi.rows,
+	}
+	if qi.rowsUnknown {
+		result.PrimaryRows = -1
+	}
+	select {
+	case m.queue <- result:
+	default:
+		m.dropped.Add(1)
+	}
+}
+
+// run 镜像任务，启动 Workers 个协程执行队列中的查询
+func (m *shadowMirror) run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 0; i < m.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case result := <-m.queue:
+					m.execute(ctx, result)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// execute 在影子库执行查询并记录对比结果
+func (m *shadowMirror) execute(ctx context.Context, result ShadowResult) {
+	ctx, cancel := context.WithTimeout(ctx, shadowQueryTimeout)
+	defer cancel()
+	start := time.Now()
+	result.ShadowRows, result.Err = m.shadow.execShadow(ctx, result.Query, result.Args, result.Write)
+	result.ShadowDuration = time.Since(start)
+	if ctx.Err() != nil && errors.Is(result.Err, context.Canceled) {
+		return
+	}
+
+	m.mirrored.Add(1)
+	m.primaryTime.Add(int64(result.PrimaryDuration))
+	m.shadowTime.Add(int64(result.ShadowDuration))
+	if result.Err != nil {
+		m.errors.Add(1)
+		m.shadow.logger.Warn("影子库执行失败", "query", result.Query, "error", result.Err)
+	} else if !result.RowsMatch() {
+		m.rowMismatches.Add(1)
+		m.shadow.logger.Warn("影子库行数不一致",
+			"query", result.Query,
+			"primary_rows", result.PrimaryRows,
+			"shadow_rows", result.ShadowRows,
+		)
+	}
+	if m.opts.OnResult != nil {
+		m.opts.OnResult(result)
+	}
+}
+
+// execShadow 执行镜像查询，返回读取或影响的行数
+func (db *DB) execShadow(ctx context.Context, query string, args []interface{}, write bool) (int64, error) {
+	if write {
+		result, err := db.DB.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	}
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var count int64
+	for rows.Next() {
+		count++
+	}
+	return count, rows.Err()
+}
@@ -0,0 +1,237 @@
+package xlorm
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ShardStrategy 分表策略，根据分片键计算分表后缀，例如 07 表示 orders_07
+type ShardStrategy interface {
+	Shard(key interface{}) (string, error)
+}
+
+// ShardFunc 函数形式的分表策略
+type ShardFunc func(key interface{}) (string, error)
+
+// Shard 实现 ShardStrategy
+func (f ShardFunc) Shard(key interface{}) (string, error) {
+	return f(key)
+}
+
+// shardRule 表的分表规则
+type shardRule struct {
+	column   string        // 分片键字段，BatchInsert 按该字段分组
+	strategy ShardStrategy // 分表策略
+}
+
+// HashShard 按分片键取模分成 n 张表，整数键直接取模，其他键取 CRC32 后取模
+// 后缀按 n-1 的位数补零（至少两位），例如 n 为 16 时 user_id 为 7 的记录在 orders_07
+func HashShard(n int) ShardStrategy {
+	width := max(len(strconv.Itoa(n-1)), 2)
+	return ShardFunc(func(key interface{}) (string, error) {
+		if n <= 0 {
+			return "", errors.New("分表数量必须为正数")
+		}
+		var index uint64
+		if v, ok := shardInt(key); ok {
+			if v < 0 {
+				v = -v
+			}
+			index = uint64(v) % uint64(n)
+		} else {
+			index = uint64(crc32.ChecksumIEEE([]byte(fmt.Sprint(key)))) % uint64(n)
+		}
+		return fmt.Sprintf("%0*d", width, index), nil
+	})
+}
+
+// RangeShard 按整数分片键的区间分表，每 size 个键一张表，例如 size 为 1000000 时 id 为 2500000 的记录在 orders_2
+func RangeShard(size int64) ShardStrategy {
+	return ShardFunc(func(key interface{}) (string, error) {
+		if size <= 0 {
+			return "", errors.New("分表区间必须为正数")
+		}
+		v, ok := shardInt(key)
+		if !ok || v < 0 {
+			return "", fmt.Errorf("区间分表的分片键必须为非负整数: %v", key)
+		}
+		return strconv.FormatInt(v/size, 10), nil
+	})
+}
+
+// DateShard 按时间分片键分表，layout 为 Go 时间格式，例如 "200601" 按月分表（orders_202401）
+// 分片键支持 time.Time、*time.Time 以及 "2006-01-02"、"2006-01-02 15:04:05"、RFC3339 格式的字符串
+func DateShard(layout string) ShardStrategy {
+	return ShardFunc(func(key interface{}) (string, error) {
+		var t time.Time
+		switch v := key.(type) {
+		case time.Time:
+			t = v
+		case *time.Time:
+			if v == nil {
+				return "", errors.New("时间分片键不能为空")
+			}
+			t = *v
+		case string:
+			var err error
+			for _, l := range []string{time.DateOnly, time.DateTime, time.RFC3339} {
+				if t, err = time.ParseInLocation(l, v, time.Local); err == nil {
+					break
+				}
+			}
+			if err != nil {
+				return "", fmt.Errorf("时间分片键格式错误: %s", v)
+			}
+		default:
+			return "", fmt.Errorf("时间分片键类型不支持: %T", key)
+		}
+		return t.Format(layout), nil
+	})
+}
+
+// RegisterShard 为表注册分表规则，column 为分片键字段（BatchInsert 按该字段将数据分组写入各分表）
+// 注册后通过 M(table).ShardBy(key) 操作分表，例如：
+//
+//	db.RegisterShard("orders", "user_id", xlorm.HashShard(16))
+//	db.M("orders").ShardBy(userID).Where("user_id = ?", userID).FindAll() // 查询 orders_07
+func (db *DB) RegisterShard(table, column string, strategy ShardStrategy) error {
+	if table == "" || strategy == nil {
+		return errors.New("表名和分表策略不能为空")
+	}
+	if column != "" && !isValidFieldName(column) {
+		return fmt.Errorf("分片键字段非法: %s", column)
+	}
+	db.shards.Store(table, &shardRule{column: column, strategy: strategy})
+	return nil
+}
+
+// ShardBy 按分片键将本次操作路由到对应的分表，表需先通过 RegisterShard 注册分表规则
+func (t *Table) ShardBy(key interface{}) *Table {
+	if t.misused("ShardBy") {
+		return t
+	}
+	rule := t.shardRule()
+	if rule == nil {
+		t.db.logger.Error("表未注册分表规则", "table", t.name)
+		return t
+	}
+	suffix, err := rule.strategy.Shard(key)
+	if err != nil {
+		t.db.logger.Error("计算分表失败", "table", t.name, "key", key, "error", err)
+		return t
+	}
+	if err := t.setShard(suffix); err != nil {
+		t.db.logger.Error("计算分表失败", "table", t.name, "key", key, "error", err)
+	}
+	return t
+}
+
+// Shard 直接指定分表后缀，例如 Shard("202401") 操作 orders_202401，无需注册分表规则
+func (t *Table) Shard(suffix string) *Table {
+	if t.misused("Shard") {
+		return t
+	}
+	if err := t.setShard(suffix); err != nil {
+		t.db.logger.Error("分表后缀非法", "table", t.name, "suffix", suffix, "error", err)
+	}
+	return t
+}
+
+// shardRule 获取表的分表规则，未注册时返回 nil
+func (t *Table) shardRule() *shardRule {
+	if t.name == "" {
+		return nil
+	}
+	rule, ok := t.db.shards.Load(t.name)
+	if !ok {
+		return nil
+	}
+	return rule.(*shardRule)
+}
+
+// setShard 设置分表后缀并重新生成完整表名
+func (t *Table) setShard(suffix string) error {
+	if t.name == "" {
+		return errors.New("表名不能为空")
+	}
+	if suffix == "" || !isValidFieldName(suffix) || strings.Contains(suffix, ".") {
+		return fmt.Errorf("分表后缀非法: %s", suffix)
+	}
+	t.shard = suffix
+	prefix := t.db.tablePre
+	if t.prefixSet {
+		prefix = t.prefix
+	}
+	t.tableName = t.db.buildTableName(t.physicalName(), prefix)
+	return nil
+}
+
+// physicalName 获取分表后的表名（不含表前缀），未分表时为调用 M 时传入的表名
+func (t *Table) physicalName() string {
+	if t.shard == "" {
+		return t.name
+	}
+	return t.name + "_" + t.shard
+}
+
+// shardBatch 按分片键将批量数据分组，返回按分表后缀排序的后缀列表和分组；未注册分表规则或已指定分表时返回 nil
+func (t *Table) shardBatch(data []map[string]interface{}) ([]string, map[string][]map[string]interface{}, error) {
+	rule := t.shardRule()
+	if t.shard != "" || rule == nil {
+		return nil, nil, nil
+	}
+	if rule.column == "" {
+		return nil, nil, fmt.Errorf("表 %s 的分表规则未指定分片键字段，请使用 ShardBy 指定分表", t.name)
+	}
+	groups := make(map[string][]map[string]interface{})
+	for i, row := range data {
+		key, ok := row[rule.column]
+		if !ok {
+			return nil, nil, fmt.Errorf("第 %d 条数据缺少分片键字段: %s", i+1, rule.column)
+		}
+		suffix, err := rule.strategy.Shard(key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("第 %d 条数据计算分表失败: %v", i+1, err)
+		}
+		groups[suffix] = append(groups[suffix], row)
+	}
+	suffixes := make([]string, 0, len(groups))
+	for suffix := range groups {
+		suffixes = append(suffixes, suffix)
+	}
+	sort.Strings(suffixes)
+	return suffixes, groups, nil
+}
+
+// shardInt 将整数类型的分片键转换为 int64
+func shardInt(key interface{}) (int64, bool) {
+	switch v := key.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,181 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ShardedDB 分库路由，按分片键将操作路由到多个数据库实例之一：
+// 分表策略（如 HashShard）计算出的后缀即为分库名称，例如 HashShard(4) 对应名称为 00~03 的四个库
+//
+//	sdb, err := xlorm.NewShardedDB("user_id", xlorm.HashShard(4), map[string]*xlorm.DB{"00": db0, "01": db1, "02": db2, "03": db3})
+//	sdb.M("orders", userID).Insert(order) // 显式指定分片键
+//	sdb.Where("orders", "status = ? AND user_id = ?", 1, userID).FindAll() // 从条件中提取分片键
+//	rows, err := sdb.FindAll(ctx, "orders", func(t *xlorm.Table) { t.Where("status = ?", 0) }) // 查询全部分库
+type ShardedDB struct {
+	column   string         // 分片键字段
+	strategy ShardStrategy  // 分库策略
+	shards   map[string]*DB // 分库名称 -> 数据库实例
+	names    []string       // 排序后的分库名称
+	keyExpr  *regexp.Regexp // 匹配条件中分片键等值比较的表达式
+}
+
+// NewShardedDB 创建分库路由，column 为分片键字段，strategy 根据分片键返回分库名称
+func NewShardedDB(column string, strategy ShardStrategy, shards map[string]*DB) (*ShardedDB, error) {
+	if column == "" || !isValidFieldName(column) || strings.Contains(column, ".") {
+		return nil, fmt.Errorf("分片键字段非法: %s", column)
+	}
+	if strategy == nil {
+		return nil, errors.New("分库策略不能为空")
+	}
+	if len(shards) == 0 {
+		return nil, errors.New("分库不能为空")
+	}
+	sdb := &ShardedDB{
+		column:   column,
+		strategy: strategy,
+		shards:   make(map[string]*DB, len(shards)),
+		keyExpr:  regexp.MustCompile("(?i)(?:^|[\\s(.])`?" + regexp.QuoteMeta(column) + "`?\\s*=\\s*\\?"),
+	}
+	for name, db := range shards {
+		if db == nil {
+			return nil, fmt.Errorf("分库 %s 的数据库连接为空", name)
+		}
+		sdb.shards[name] = db
+		sdb.names = append(sdb.names, name)
+	}
+	sort.Strings(sdb.names)
+	return sdb, nil
+}
+
+// Shard 获取分片键对应的数据库实例
+func (s *ShardedDB) Shard(key interface{}) (*DB, error) {
+	name, err := s.strategy.Shard(key)
+	if err != nil {
+		return nil, fmt.Errorf("计算分库失败: %v", err)
+	}
+	db, ok := s.shards[name]
+	if !ok {
+		return nil, fmt.Errorf("分库 %s 不存在", name)
+	}
+	return db, nil
+}
+
+// M 在分片键对应的数据库上操作表，分片键无法路由时记录错误并返回第一个分库的空 Table，后续操作将失败
+func (s *ShardedDB) M(table string, key interface{}) *Table {
+	db, err := s.Shard(key)
+	if err != nil {
+		first := s.shards[s.names[0]]
+		first.logger.Error("分库路由失败", "table", table, "key", key, "error", err)
+		return first.M("")
+	}
+	return db.M(table)
+}
+
+// Where 从条件中提取分片键（形如 user_id = ? 的等值条件）并路由到对应的数据库，返回已添加该条件的 Table
+// 条件中不含分片键时记录错误并返回第一个分库的空 Table，需要查询全部分库时使用 FindAll
+func (s *ShardedDB) Where(table, condition string, args ...interface{}) *Table {
+	key, ok := s.extractKey(condition, args)
+	if !ok {
+		first := s.shards[s.names[0]]
+		first.logger.Error("条件中未找到分片键", "table", table, "column", s.column, "condition", condition)
+		return first.M("")
+	}
+	return s.M(table, key).Where(condition, args...)
+}
+
+// Insert 根据数据中的分片键字段路由并插入记录
+func (s *ShardedDB) Insert(ctx context.Context, table string, data map[string]interface{}) (int64, error) {
+	key, ok := data[s.column]
+	if !ok {
+		return 0, fmt.Errorf("数据缺少分片键字段: %s", s.column)
+	}
+	db, err := s.Shard(key)
+	if err != nil {
+		return 0, err
+	}
+	return db.M(table).InsertWithContext(ctx, data)
+}
+
+// FindAll 在全部分库上并发执行查询并合并结果（按分库名称顺序），用于管理后台等跨分库查询
+// fn 用于设置查询条件，对每个分库各调用一次；任一分库失败时返回错误
+// 各分库的排序、分页各自生效，合并后的结果不保证全局有序
+func (s *ShardedDB) FindAll(ctx context.Context, table string, fn func(t *Table)) ([]map[string]interface{}, error) {
+	results := make([][]map[string]interface{}, len(s.names))
+	errs := make([]error, len(s.names))
+	var wg sync.WaitGroup
+	for i, name := range s.names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			t := s.shards[name].M(table)
+			if fn != nil {
+				fn(t)
+			}
+			rows, err := t.FindAllWithContext(ctx)
+			if err != nil {
+				errs[i] = fmt.Errorf("分库 %s 查询失败: %w", name, err)
+				return
+			}
+			results[i] = rows
+		}(i, name)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	var total int
+	for _, rows := range results {
+		total += len(rows)
+	}
+	merged := make([]map[string]interface{}, 0, total)
+	for _, rows := range results {
+		merged = append(merged, rows...)
+	}
+	return merged, nil
+}
+
+// Each 依次对每个分库执行 fn（按分库名称顺序），fn 返回错误时停止
+func (s *ShardedDB) Each(fn func(name string, db *DB) error) error {
+	for _, name := range s.names {
+		if err := fn(name, s.shards[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shards 获取全部分库名称，按名称排序
+func (s *ShardedDB) Shards() []string {
+	return append([]string(nil), s.names...)
+}
+
+// Close 关闭全部分库
+func (s *ShardedDB) Close() error {
+	var errs []error
+	for _, name := range s.names {
+		if err := s.shards[name].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭分库 %s 失败: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// extractKey 从条件中提取分片键的参数值
+func (s *ShardedDB) extractKey(condition string, args []interface{}) (interface{}, bool) {
+	loc := s.keyExpr.FindStringIndex(condition)
+	if loc == nil {
+		return nil, false
+	}
+	// 匹配到的占位符之前的占位符数量即为参数下标
+	index := strings.Count(condition[:loc[1]-1], "?")
+	if index >= len(args) {
+		return nil, false
+	}
+	return args[index], true
+}
@@ -0,0 +1,127 @@
+package xlorm
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultSlowLogSize 默认保留的慢查询条数
+const defaultSlowLogSize = 100
+
+// SlowQuery 捕获的慢查询
+type SlowQuery struct {
+	TraceID     string        // 追踪ID
+	Operation   string        // 操作类型
+	Table       string        // 表名，直接执行SQL时为空
+	Query       string        // SQL语句
+	Args        []interface{} // SQL参数
+	Fingerprint string        // SQL指纹
+	StartTime   time.Time     // 开始时间
+	Duration    time.Duration // 执行耗时
+	Rows        int64         // 返回或影响的行数
+}
+
+// SlowQueryEvent 慢查询回调收到的事件
+type SlowQueryEvent = SlowQuery
+
+// slowLog 保留最近慢查询的环形缓冲区
+type slowLog struct {
+	mu       sync.Mutex
+	entries  []SlowQuery
+	next     int                                    // 下一个写入位置
+	full     bool                                   // 缓冲区是否已写满
+	handlers atomic.Pointer[[]func(SlowQueryEvent)] // 慢查询回调，写时复制
+}
+
+// newSlowLog 创建慢查询缓冲区，size 小于等于0时不保留慢查询
+func newSlowLog(size int) *slowLog {
+	l := &slowLog{}
+	if size > 0 {
+		l.entries = make([]SlowQuery, size)
+	}
+	l.handlers.Store(&[]func(SlowQueryEvent){})
+	return l
+}
+
+// add 添加慢查询，缓冲区满时覆盖最早的记录
+func (l *slowLog) add(q SlowQuery) {
+	if len(l.entries) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[l.next] = q
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// SlowQueries 获取最近捕获的慢查询，按执行时间从早到晚排列
+// 执行成功且耗时不低于 SlowQueryTime 的SQL会被捕获，保留条数由 Config.SlowLogSize 控制
+func (db *DB) SlowQueries() []SlowQuery {
+	l := db.slowLog
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.full {
+		return append([]SlowQuery(nil), l.entries[:l.next]...)
+	}
+	result := make([]SlowQuery, 0, len(l.entries))
+	result = append(result, l.entries[l.next:]...)
+	return append(result, l.entries[:l.next]...)
+}
+
+// ClearSlowQueries 清空捕获的慢查询
+func (db *DB) ClearSlowQueries() {
+	l := db.slowLog
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clear(l.entries)
+	l.next = 0
+	l.full = false
+}
+
+// OnSlowQuery 注册慢查询回调，执行成功且耗时不低于 SlowQueryTime 的SQL会触发回调，
+// 可用于将慢查询推送到 Sentry 等告警系统；回调在查询所在协程中同步调用，耗时操作应自行异步处理，回调 panic 只记录日志
+func (db *DB) OnSlowQuery(fn func(SlowQueryEvent)) {
+	if fn == nil {
+		return
+	}
+	l := db.slowLog
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	handlers := append(append([]func(SlowQueryEvent){}, *l.handlers.Load()...), fn)
+	l.handlers.Store(&handlers)
+}
+
+// notifySlowQuery 调用慢查询回调
+func (db *DB) notifySlowQuery(q SlowQuery) {
+	for _, fn := range *db.slowLog.handlers.Load() {
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					db.logger.Error("慢查询回调发生panic", "query", q.Query, "panic", p)
+				}
+			}()
+			fn(q)
+		}()
+	}
+}
+
+// captureSlowQuery 捕获慢查询并调用慢查询回调
+func (db *DB) captureSlowQuery(qi *queryInfo, duration time.Duration) {
+	q := SlowQuery{
+		TraceID:     TraceIDFromContext(qi.ctx),
+		Operation:   qi.operation,
+		Table:       qi.table,
+		Query:       qi.query,
+		Args:        append([]interface{}(nil), qi.args...),
+		Fingerprint: qi.getFingerprint(),
+		StartTime:   qi.start,
+		Duration:    duration,
+		Rows:        qi.rows,
+	}
+	db.slowLog.add(q)
+	db.notifySlowQuery(q)
+}
@@ -0,0 +1,66 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WithSoftDelete 开启软删除，field 为记录删除时间的字段（如 deleted_at）
+// 开启后 Delete 改为将该字段更新为当前时间，查询、统计和更新自动追加 field IS NULL 条件
+func (t *Table) WithSoftDelete(field string) *Table {
+	if t.misused("WithSoftDelete") {
+		return t
+	}
+	if !isValidFieldName(field) {
+		t.db.logger.Error("软删除字段非法", "field", field)
+		return t
+	}
+	t.softDeleteField = field
+	return t
+}
+
+// Unscoped 忽略软删除过滤，查询包含已删除的记录，Delete 将物理删除记录；全局作用域仍然生效，需忽略时调用 WithoutGlobalScopes
+func (t *Table) Unscoped() *Table {
+	if t.misused("Unscoped") {
+		return t
+	}
+	t.unscoped = true
+	return t
+}
+
+// Restore 恢复符合条件的已软删除记录，将软删除字段置为 NULL
+func (t *Table) Restore() (rowsAffected int64, err error) {
+	return t.RestoreWithContext(t.context())
+}
+
+// RestoreWithContext 带上下文的Restore
+func (t *Table) RestoreWithContext(ctx context.Context) (rowsAffected int64, err error) {
+	if t.softDeleteField == "" {
+		t.Release()
+		return 0, errors.New("未开启软删除，无法恢复记录")
+	}
+	t.onlyTrashed = true
+	return t.update(ctx, map[string]interface{}{t.softDeleteField: nil})
+}
+
+// softDelete 软删除记录
+func (t *Table) softDelete(ctx context.Context) (int64, error) {
+	return t.updateWithHooks(ctx, map[string]interface{}{t.softDeleteField: time.Now()}, BeforeDelete, AfterDelete, "soft_delete")
+}
+
+// softDeleteCondition 获取软删除过滤条件，无需过滤时返回空字符串
+func (t *Table) softDeleteCondition() string {
+	if t.softDeleteField == "" {
+		return ""
+	}
+	field := t.tableName + "." + quoteColumn(t.softDeleteField)
+	switch {
+	case t.onlyTrashed:
+		return field + " IS NOT NULL"
+	case t.unscoped:
+		return ""
+	default:
+		return field + " IS NULL"
+	}
+}
@@ -0,0 +1,84 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SQLMode 获取连接实际生效的 sql_mode
+func (db *DB) SQLMode(ctx context.Context) ([]string, error) {
+	if db == nil || db.DB == nil {
+		return nil, errors.New("数据库连接为空")
+	}
+	var sqlMode string
+	if err := db.QueryRowContext(ctx, "SELECT @@SESSION.sql_mode").Scan(&sqlMode); err != nil {
+		return nil, fmt.Errorf("读取sql_mode失败: %v", err)
+	}
+	return parseSQLModes(sqlMode), nil
+}
+
+// MissingSQLModes 检查连接是否启用了指定的 sql_mode，返回未启用的部分
+func (db *DB) MissingSQLModes(ctx context.Context, modes ...string) ([]string, error) {
+	current, err := db.SQLMode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	enabled := make(map[string]bool, len(current))
+	for _, mode := range current {
+		enabled[mode] = true
+	}
+	var missing []string
+	for _, mode := range modes {
+		if mode = strings.ToUpper(strings.TrimSpace(mode)); !enabled[mode] {
+			missing = append(missing, mode)
+		}
+	}
+	return missing, nil
+}
+
+// parseSQLModes 拆分 sql_mode
+func parseSQLModes(sqlMode string) []string {
+	var modes []string
+	for _, mode := range strings.Split(sqlMode, ",") {
+		if mode = strings.ToUpper(strings.TrimSpace(mode)); mode != "" {
+			modes = append(modes, mode)
+		}
+	}
+	return modes
+}
+
+// validateSQLModes 校验 sql_mode 名称，只允许字母和下划线
+func validateSQLModes(modes []string) error {
+	for _, mode := range modes {
+		if mode == "" {
+			return errors.New("sql_mode不能为空")
+		}
+		for i := 0; i < len(mode); i++ {
+			c := mode[i]
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_') {
+				return fmt.Errorf("无效的sql_mode: %s", mode)
+			}
+		}
+	}
+	return nil
+}
+
+// sqlModeDSNParam 生成设置连接 sql_mode 的 DSN 参数，驱动在每个连接建立后执行 SET sql_mode=...
+// SQLMode 覆盖服务端默认值，SQLModeFlags 在其基础上（未设置 SQLMode 时为服务端默认值）追加
+func sqlModeDSNParam(cfg *Config) string {
+	if cfg.SQLMode == "" && len(cfg.SQLModeFlags) == 0 {
+		return ""
+	}
+	base := "@@sql_mode"
+	if cfg.SQLMode != "" {
+		base = "'" + strings.Join(parseSQLModes(cfg.SQLMode), ",") + "'"
+	}
+	value := base
+	if len(cfg.SQLModeFlags) > 0 {
+		value = "TRIM(BOTH ',' FROM CONCAT(" + base + ", '," + strings.ToUpper(strings.Join(cfg.SQLModeFlags, ",")) + "'))"
+	}
+	return "&sql_mode=" + url.QueryEscape(value)
+}
@@ -0,0 +1,374 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// renderedSQLNote 代入参数后的SQL前缀，标明其不是实际执行的语句
+const renderedSQLNote = "/* 参数已代入，仅供调试，非实际执行的SQL */ "
+
+// RenderSQL 将参数按 MySQL 字面量格式代入SQL中的 ? 占位符（跳过字符串、反引号标识符和注释中的 ?），
+// 结果仅用于日志和调试时复制到客户端执行，不可用于实际执行；参数多于占位符时忽略多余参数，少于时保留 ?
+func RenderSQL(query string, args ...interface{}) string {
+	if len(args) == 0 {
+		return query
+	}
+	var sb strings.Builder
+	sb.Grow(len(query) + len(args)*8)
+	last, n := 0, 0
+	for _, tok := range scanSQLTokens(query) {
+		if tok.kind != sqlTokenPlaceholder || n >= len(args) {
+			continue
+		}
+		sb.WriteString(query[last:tok.pos])
+		sb.WriteString(sqlLiteral(args[n]))
+		last = tok.pos + 1
+		n++
+	}
+	sb.WriteString(query[last:])
+	return sb.String()
+}
+
+// sqlLiteral 将参数格式化为 MySQL 字面量
+func sqlLiteral(v interface{}) string {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return "NULL"
+		}
+		value, err := valuer.Value()
+		if err != nil {
+			return "?"
+		}
+		v = value
+	}
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quoteSQLString(x)
+	case []byte:
+		if x == nil {
+			return "NULL"
+		}
+		return "X'" + hex.EncodeToString(x) + "'"
+	case bool:
+		if x {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		if x.IsZero() {
+			return "'0000-00-00 00:00:00'"
+		}
+		return "'" + x.Format("2006-01-02 15:04:05.999999") + "'"
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return sqlLiteral(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64)
+	case reflect.Bool:
+		return sqlLiteral(rv.Bool())
+	case reflect.String:
+		return quoteSQLString(rv.String())
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return sqlLiteral(rv.Bytes())
+		}
+	}
+	return quoteSQLString(fmt.Sprint(v))
+}
+
+// quoteSQLString 按 MySQL 默认的反斜杠转义规则为字符串加引号
+func quoteSQLString(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + 2)
+	sb.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case 0:
+			sb.WriteString(`\0`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\x1a':
+			sb.WriteString(`\Z`)
+		case '\'', '"', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// sqlTokenKind SQL词法单元类型
+type sqlTokenKind int
+
+const (
+	sqlTokenIdent       sqlTokenKind = iota // 标识符或关键字
+	sqlTokenPlaceholder                     // ? 占位符
+	sqlTokenLiteral                         // 字符串或数字字面量
+	sqlTokenPunct                           // 运算符和标点
+)
+
+// sqlToken SQL词法单元，ident 为小写的标识符（去掉反引号）
+type sqlToken struct {
+	kind  sqlTokenKind
+	pos   int
+	text  string
+	ident string
+}
+
+// scanSQLTokens 粗略切分SQL词法单元，跳过注释，仅用于定位占位符及其对应的列
+func scanSQLTokens(query string) []sqlToken {
+	var tokens []sqlToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || (c == '-' && strings.HasPrefix(query[i:], "-- ")):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '\'' || c == '"':
+			start := i
+			for i++; i < len(query); i++ {
+				if query[i] == '\\' {
+					i++
+				} else if query[i] == c {
+					if i+1 < len(query) && query[i+1] == c {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			i++
+			tokens = append(tokens, sqlToken{kind: sqlTokenLiteral, pos: start, text: query[start:min(i, len(query))]})
+		case c == '`':
+			start := i
+			end := strings.IndexByte(query[i+1:], '`')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 2
+			name := query[start+1 : i-1]
+			tokens = append(tokens, sqlToken{kind: sqlTokenIdent, pos: start, text: query[start:i], ident: strings.ToLower(name)})
+		case c == '?':
+			tokens = append(tokens, sqlToken{kind: sqlTokenPlaceholder, pos: i, text: "?"})
+			i++
+		case c == '_' || c == '$' || c >= 0x80 || (c|0x20 >= 'a' && c|0x20 <= 'z'):
+			start := i
+			for i < len(query) && isSQLIdentByte(query[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenIdent, pos: start, text: query[start:i], ident: strings.ToLower(query[start:i])})
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(query) && (isSQLIdentByte(query[i]) || query[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenLiteral, pos: start, text: query[start:i]})
+		default:
+			start := i
+			i++
+			if strings.IndexByte("<>!=", c) >= 0 {
+				for i < len(query) && strings.IndexByte("<>=", query[i]) >= 0 {
+					i++
+				}
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenPunct, pos: start, text: query[start:i]})
+		}
+	}
+	return tokens
+}
+
+// isSQLIdentByte 判断是否为未加引号标识符中的字符
+func isSQLIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || (c >= '0' && c <= '9') || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
+
+// placeholderSkipWords 由占位符向前查找列名时跳过的关键字
+var placeholderSkipWords = map[string]bool{
+	"in": true, "not": true, "like": true, "between": true, "and": true, "is": true, "regexp": true, "rlike": true,
+}
+
+// placeholderColumns 推断每个占位符对应的列名（小写，不含表名限定），无法推断时为空字符串；
+// 支持 col = ?、col IN (?, ?)、col BETWEEN ? AND ? 等比较形式以及 INSERT ... (cols) VALUES (?, ?) 的按位对应
+func placeholderColumns(query string) []string {
+	tokens := scanSQLTokens(query)
+	var columns []string
+	var insertCols []string
+	valuesDepth, valuesPos := 0, 0
+	inValues := false
+	for i, tok := range tokens {
+		if tok.kind == sqlTokenIdent && tok.ident == "values" && tok.text[0] != '`' && !inValues && insertCols == nil {
+			insertCols = insertColumnList(tokens[:i])
+			inValues = insertCols != nil
+			continue
+		}
+		if inValues {
+			switch tok.text {
+			case "(":
+				if valuesDepth++; valuesDepth == 1 {
+					valuesPos = 0
+				}
+			case ")":
+				if valuesDepth--; valuesDepth == 0 && (i+1 >= len(tokens) || tokens[i+1].text != ",") {
+					inValues = false
+				}
+			case ",":
+				if valuesDepth == 1 {
+					valuesPos++
+				}
+			}
+		}
+		if tok.kind != sqlTokenPlaceholder {
+			continue
+		}
+		if inValues {
+			column := ""
+			if valuesPos < len(insertCols) {
+				column = insertCols[valuesPos]
+			}
+			columns = append(columns, column)
+			continue
+		}
+		columns = append(columns, comparedColumn(tokens[:i]))
+	}
+	return columns
+}
+
+// comparedColumn 由占位符向前查找与其比较的列名
+func comparedColumn(tokens []sqlToken) string {
+	for j := len(tokens) - 1; j >= 0; j-- {
+		tok := tokens[j]
+		switch tok.kind {
+		case sqlTokenPlaceholder, sqlTokenLiteral:
+			continue
+		case sqlTokenIdent:
+			if placeholderSkipWords[tok.ident] && tok.text[0] != '`' {
+				continue
+			}
+			return tok.ident
+		case sqlTokenPunct:
+			switch tok.text {
+			case ",", "(", "=", "<", ">", "<=", ">=", "<>", "!=", "<=>":
+				continue
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// insertColumnList 解析 VALUES 之前的 INSERT/REPLACE 列清单，非该形式时返回 nil
+func insertColumnList(tokens []sqlToken) []string {
+	if len(tokens) < 3 || tokens[len(tokens)-1].text != ")" {
+		return nil
+	}
+	isInsert := false
+	for _, tok := range tokens {
+		if tok.kind == sqlTokenIdent && (tok.ident == "insert" || tok.ident == "replace") && tok.text[0] != '`' {
+			isInsert = true
+			break
+		}
+	}
+	if !isInsert {
+		return nil
+	}
+	var cols []string
+	for j := len(tokens) - 2; j >= 0; j-- {
+		tok := tokens[j]
+		switch {
+		case tok.text == "(":
+			for l, r := 0, len(cols)-1; l < r; l, r = l+1, r-1 {
+				cols[l], cols[r] = cols[r], cols[l]
+			}
+			return cols
+		case tok.text == ",":
+		case tok.kind == sqlTokenIdent:
+			cols = append(cols, tok.ident)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// redactArgs 返回按 Config.LogRedactColumns 脱敏后的参数副本，未配置或无需脱敏时返回原参数
+func (db *DB) redactArgs(query string, args []interface{}) []interface{} {
+	if len(db.config.LogRedactColumns) == 0 || len(args) == 0 {
+		return args
+	}
+	var redacted []interface{}
+	for i, column := range placeholderColumns(query) {
+		if i >= len(args) {
+			break
+		}
+		if column == "" || !db.isRedactedColumn(column) {
+			continue
+		}
+		if redacted == nil {
+			redacted = append([]interface{}(nil), args...)
+		}
+		redacted[i] = maskedSecret
+	}
+	if redacted == nil {
+		return args
+	}
+	return redacted
+}
+
+// isRedactedColumn 判断列是否需要在日志中脱敏（不区分大小写）
+func (db *DB) isRedactedColumn(column string) bool {
+	for _, c := range db.config.LogRedactColumns {
+		if strings.EqualFold(c, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// logQuery 调试模式或上下文开启SQL日志时记录SQL及脱敏后的参数，并附加代入参数后的SQL（rendered_sql，非实际执行的语句）便于复制到客户端
+func (db *DB) logQuery(ctx context.Context, msg, operation, query string, args []interface{}, kv ...any) {
+	if !db.shouldLogSQL(ctx) {
+		return
+	}
+	args = db.redactArgs(query, args)
+	attrs := make([]any, 0, len(kv)+6)
+	attrs = append(attrs, operation, query, "args", args, "rendered_sql", renderedSQLNote+RenderSQL(query, args...))
+	db.logSQL(ctx, msg, append(attrs, kv...)...)
+}
@@ -0,0 +1,213 @@
+package xlorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// sqlTemplateOutputFuncs 模板中允许输出内容的函数
+var sqlTemplateOutputFuncs = map[string]bool{
+	"bind":  true,
+	"in":    true,
+	"ident": true,
+	"frag":  true,
+}
+
+// SQLTemplateOptions SQL模板选项
+type SQLTemplateOptions struct {
+	Identifiers []string          // 允许通过 ident 插入的标识符（表名、字段名）
+	Fragments   map[string]string // 允许通过 frag 插入的SQL片段，键为片段名
+}
+
+// sqlTemplate 安全的SQL模板
+type sqlTemplate struct {
+	tmpl        *template.Template
+	identifiers map[string]bool
+	fragments   map[string]string
+}
+
+// sqlTemplateState 单次渲染的状态
+type sqlTemplateState struct {
+	args []interface{}
+}
+
+// NewSQLTemplate 创建基于 text/template 的SQL模板，用于构建器链式调用难以表达的动态查询
+// 模板中只能通过以下函数输出内容，直接输出变量（如 {{.Name}}）会在解析时报错：
+//   - bind：将值转换为 ? 占位符，例如 {{bind .Status}}
+//   - in：将切片展开为 (?, ?, ?)，例如 id IN {{in .IDs}}
+//   - ident：插入白名单中的标识符，例如 ORDER BY {{ident .SortField}}
+//   - frag：插入白名单中的SQL片段，例如 {{frag "active_only"}}
+//
+// 例如：
+//
+//	st, err := xlorm.NewSQLTemplate("report", `SELECT {{ident .Field}}, COUNT(*) AS cnt FROM orders
+//		WHERE created_at >= {{bind .Since}}{{if .Regions}} AND region IN {{in .Regions}}{{end}}
+//		GROUP BY {{ident .Field}}`, xlorm.SQLTemplateOptions{Identifiers: []string{"region", "channel"}})
+//	rows, err := db.QueryTemplate(ctx, st, params)
+func NewSQLTemplate(name, text string, opts SQLTemplateOptions) (*sqlTemplate, error) {
+	st := &sqlTemplate{
+		identifiers: make(map[string]bool, len(opts.Identifiers)),
+		fragments:   make(map[string]string, len(opts.Fragments)),
+	}
+	for _, ident := range opts.Identifiers {
+		if !isValidFieldName(ident) {
+			return nil, fmt.Errorf("标识符非法: %s", ident)
+		}
+		st.identifiers[ident] = true
+	}
+	for key, fragment := range opts.Fragments {
+		if strings.ContainsAny(fragment, ";\x00") {
+			return nil, fmt.Errorf("SQL片段 %s 检测到可能的SQL注入尝试: %s", key, fragment)
+		}
+		st.fragments[key] = fragment
+	}
+
+	// 解析时使用占位函数，执行时替换为绑定了渲染状态的实现
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(st.funcs(nil)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("解析SQL模板失败: %v", err)
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		if err := checkSQLTemplateNode(t.Tree.Root); err != nil {
+			return nil, fmt.Errorf("SQL模板 %s 非法: %v", t.Name(), err)
+		}
+	}
+	st.tmpl = tmpl
+	return st, nil
+}
+
+// Render 渲染SQL模板，返回带 ? 占位符的SQL和参数
+func (st *sqlTemplate) Render(data interface{}) (string, []interface{}, error) {
+	tmpl, err := st.tmpl.Clone()
+	if err != nil {
+		return "", nil, fmt.Errorf("渲染SQL模板失败: %v", err)
+	}
+	state := &sqlTemplateState{}
+	tmpl.Funcs(st.funcs(state))
+
+	var query strings.Builder
+	if err := tmpl.Execute(&query, data); err != nil {
+		return "", nil, fmt.Errorf("渲染SQL模板失败: %v", err)
+	}
+	return strings.TrimSpace(query.String()), state.args, nil
+}
+
+// QueryTemplate 渲染SQL模板并执行查询
+func (db *DB) QueryTemplate(ctx context.Context, st *sqlTemplate, data interface{}) ([]map[string]interface{}, error) {
+	query, args, err := st.Render(data)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryWithContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRowMaps(rows)
+}
+
+// ExecTemplate 渲染SQL模板并执行
+func (db *DB) ExecTemplate(st *sqlTemplate, data interface{}) (sql.Result, error) {
+	query, args, err := st.Render(data)
+	if err != nil {
+		return nil, err
+	}
+	return db.Exec(query, args...)
+}
+
+// funcs 模板函数，state 为 nil 时仅用于解析
+func (st *sqlTemplate) funcs(state *sqlTemplateState) template.FuncMap {
+	return template.FuncMap{
+		"bind": func(value interface{}) string {
+			if state != nil {
+				state.args = append(state.args, value)
+			}
+			return "?"
+		},
+		"in": func(values interface{}) (string, error) {
+			rv := reflect.ValueOf(values)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				return "", fmt.Errorf("in 的参数必须为切片: %T", values)
+			}
+			if rv.Len() == 0 {
+				return "", errors.New("in 的参数不能为空切片")
+			}
+			placeholders := make([]string, rv.Len())
+			for i := range placeholders {
+				placeholders[i] = "?"
+				if state != nil {
+					state.args = append(state.args, rv.Index(i).Interface())
+				}
+			}
+			return "(" + strings.Join(placeholders, ", ") + ")", nil
+		},
+		"ident": func(name string) (string, error) {
+			if !st.identifiers[name] {
+				return "", fmt.Errorf("标识符不在白名单中: %s", name)
+			}
+			return quoteColumn(name), nil
+		},
+		"frag": func(key string) (string, error) {
+			fragment, ok := st.fragments[key]
+			if !ok {
+				return "", fmt.Errorf("SQL片段不在白名单中: %s", key)
+			}
+			return fragment, nil
+		},
+	}
+}
+
+// checkSQLTemplateNode 检查模板中所有输出动作都经过允许的输出函数
+func checkSQLTemplateNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkSQLTemplateNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		// 变量声明不输出内容
+		if len(n.Pipe.Decl) > 0 {
+			return nil
+		}
+		cmds := n.Pipe.Cmds
+		if len(cmds) == 0 {
+			return fmt.Errorf("不允许直接输出: %s", n)
+		}
+		ident, ok := cmds[len(cmds)-1].Args[0].(*parse.IdentifierNode)
+		if !ok || !sqlTemplateOutputFuncs[ident.Ident] {
+			return fmt.Errorf("不允许直接输出，请使用 bind、in、ident 或 frag: %s", n)
+		}
+	case *parse.IfNode:
+		return checkSQLTemplateBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		return checkSQLTemplateBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkSQLTemplateBranch(&n.BranchNode)
+	}
+	return nil
+}
+
+// checkSQLTemplateBranch 检查分支节点
+func checkSQLTemplateBranch(n *parse.BranchNode) error {
+	if err := checkSQLTemplateNode(n.List); err != nil {
+		return err
+	}
+	if n.ElseList != nil {
+		return checkSQLTemplateNode(n.ElseList)
+	}
+	return nil
+}
@@ -1,10 +1,14 @@
 package xlorm
 
 import (
+	"database/sql"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -76,6 +80,8 @@ type StructMapper struct {
 
 	skipDefault   bool
 	skipCallbacks map[string]bool
+
+	strict atomic.Bool // MapToStruct 是否校验结果列与结构体字段一一对应
 }
 
 // NewStructMapper 创建一个新的 StructMapper 实例
@@ -324,7 +330,8 @@ func (sm *StructMapper) getStructMeta(t reflect.Type) *structMeta {
 // 解析字段元数据的内部方法
 func (sm *StructMapper) parseFieldMeta(field *reflect.StructField) fieldMeta {
 	dbTag := field.Tag.Get("db")
-	if dbTag == "-" {
+	// 关联字段由 Preload 加载，不参与字段映射
+	if dbTag == "-" || field.Tag.Get("rel") != "" {
 		return fieldMeta{ignored: true}
 	}
 
@@ -479,3 +486,194 @@ func (sm *StructMapper) convertValue(defaultVal string, fieldType reflect.Type)
 	}
 	return nil, fmt.Errorf("unsupported type conversion for %v", fieldType)
 }
+
+// MapToStruct 将查询结果（map）写入结构体，dest 必须为结构体指针
+// 按 db 标签匹配列名（未设置标签时使用字段名），结果中不存在的列保持原值
+func (sm *StructMapper) MapToStruct(m map[string]interface{}, dest interface{}) error {
+	val := reflect.ValueOf(dest)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return fmt.Errorf("dest must be a non-nil pointer to struct")
+	}
+	val = val.Elem()
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("dest must be a non-nil pointer to struct")
+	}
+	if sm.strict.Load() {
+		if err := sm.checkColumns(m, val.Type()); err != nil {
+			return err
+		}
+	}
+	return sm.fillStruct(m, val)
+}
+
+// SetStrict 设置严格映射模式，开启后 MapToStruct 在结果中有结构体不存在的列、
+// 或结构体字段在结果中缺失时返回 ColumnMismatchError；使用 Fields 只查询部分字段时需关闭
+func (sm *StructMapper) SetStrict(strict bool) {
+	sm.strict.Store(strict)
+}
+
+// checkColumns 校验结果列与结构体字段是否一一对应
+func (sm *StructMapper) checkColumns(m map[string]interface{}, typ reflect.Type) error {
+	columns := make(map[string]struct{}, len(m))
+	sm.structColumns(typ, columns)
+	var unknown, missing []string
+	for column := range m {
+		if _, ok := columns[column]; !ok {
+			unknown = append(unknown, column)
+		}
+	}
+	for column := range columns {
+		if _, ok := m[column]; !ok {
+			missing = append(missing, column)
+		}
+	}
+	if len(unknown) == 0 && len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	sort.Strings(missing)
+	return &ColumnMismatchError{Type: typ.String(), Unknown: unknown, Missing: missing}
+}
+
+// structColumns 收集结构体（含嵌套结构体）字段对应的列名，与 fillStruct 的匹配规则一致
+func (sm *StructMapper) structColumns(typ reflect.Type, columns map[string]struct{}) {
+	meta := sm.getStructMeta(typ)
+	for _, fieldName := range meta.fieldOrder {
+		field, _ := typ.FieldByName(fieldName)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct && !isBasicType(field.Type) && !reflect.PointerTo(field.Type).Implements(scannerType) {
+			sm.structColumns(field.Type, columns)
+			continue
+		}
+		column := meta.fields[fieldName].dbName
+		if column == "" {
+			column = fieldName
+		}
+		columns[column] = struct{}{}
+	}
+}
+
+// fillStruct 递归填充结构体字段
+func (sm *StructMapper) fillStruct(m map[string]interface{}, val reflect.Value) error {
+	meta := sm.getStructMeta(val.Type())
+	for _, fieldName := range meta.fieldOrder {
+		field := val.FieldByName(fieldName)
+		if !field.CanSet() {
+			continue
+		}
+
+		// 递归处理嵌套结构体
+		if field.Kind() == reflect.Struct && !isBasicType(field.Type()) {
+			if _, ok := field.Addr().Interface().(sql.Scanner); !ok {
+				if err := sm.fillStruct(m, field); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		column := meta.fields[fieldName].dbName
+		if column == "" {
+			column = fieldName
+		}
+		value, ok := m[column]
+		if !ok {
+			continue
+		}
+		if err := assignValue(field, value); err != nil {
+			return &ScanError{Column: column, Field: fieldName, GoType: field.Type().String(), Value: value, Err: err}
+		}
+	}
+	return nil
+}
+
+// assignValue 将数据库返回的值赋给字段，支持指针、sql.Scanner 及字符串到数值、布尔、时间的转换
+func assignValue(field reflect.Value, value interface{}) error {
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+	if value == nil {
+		field.SetZero()
+		return nil
+	}
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := assignValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	src := reflect.ValueOf(value)
+	if src.Type().AssignableTo(field.Type()) {
+		field.Set(src)
+		return nil
+	}
+
+	// 数据库驱动未开启 parseTime 等情况下，值以字符串返回
+	if s, ok := value.(string); ok {
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(s, 10, field.Type().Bits())
+			if err != nil {
+				return err
+			}
+			field.SetInt(n)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := strconv.ParseUint(s, 10, field.Type().Bits())
+			if err != nil {
+				return err
+			}
+			field.SetUint(n)
+			return nil
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(s, field.Type().Bits())
+			if err != nil {
+				return err
+			}
+			field.SetFloat(f)
+			return nil
+		case reflect.Bool:
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return err
+			}
+			field.SetBool(b)
+			return nil
+		case reflect.Slice:
+			if field.Type().Elem().Kind() == reflect.Uint8 {
+				field.SetBytes([]byte(s))
+				return nil
+			}
+		case reflect.Struct:
+			if field.Type() == reflect.TypeOf(time.Time{}) {
+				tm, err := time.ParseInLocation("2006-01-02 15:04:05.999999", s, time.Local)
+				if err != nil {
+					if tm, err = time.ParseInLocation(time.DateOnly, s, time.Local); err != nil {
+						return err
+					}
+				}
+				field.Set(reflect.ValueOf(tm))
+				return nil
+			}
+		}
+	}
+
+	// 数值类型之间的转换（如 int64 -> int32、int64 -> bool）
+	switch {
+	case field.Kind() == reflect.Bool && src.CanInt():
+		field.SetBool(src.Int() != 0)
+		return nil
+	case src.Type().ConvertibleTo(field.Type()) && src.Kind() != reflect.String && field.Kind() != reflect.String:
+		field.Set(src.Convert(field.Type()))
+		return nil
+	case field.Kind() == reflect.String:
+		field.SetString(fmt.Sprint(value))
+		return nil
+	}
+	return fmt.Errorf("cannot assign %T to %s", value, field.Type())
+}
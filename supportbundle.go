@@ -0,0 +1,136 @@
+package xlorm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// supportBundleTimeout 导出诊断包时查询数据库版本的超时时间
+const supportBundleTimeout = 2 * time.Second
+
+// xlormModulePath 本模块路径，用于从构建信息中读取版本
+const xlormModulePath = "github.com/jiankeluoluo/xlorm"
+
+// SupportBundle 将诊断信息导出为一个 JSON 文档写入 w，用于附加到问题反馈和故障工单，包含：
+// 生效配置（密码已脱敏）、性能指标、连接池状态及统计历史、最近的慢查询（不含参数）、版本信息
+//
+//	f, _ := os.Create("xlorm-bundle.json")
+//	defer f.Close()
+//	err := db.SupportBundle(f)
+func (db *DB) SupportBundle(w io.Writer) error {
+	bundle := map[string]interface{}{
+		"generated_at": time.Now(),
+		"version":      db.bundleVersion(),
+		"config":       bundleConfig(db.EffectiveConfig()),
+		"pool":         db.bundlePool(),
+		"slow_queries": db.bundleSlowQueries(),
+	}
+	metrics := map[string]interface{}{}
+	if m := db.DBMetrics(); m != nil {
+		metrics = m.GetDBMetrics()
+		metrics["dropped_metrics"] = db.asyncDBMetrics.GetDroppedMetricsCount()
+	}
+	metrics["dropped_events"] = db.DroppedEvents()
+	metrics["plugins"] = db.PluginStats()
+	bundle["metrics"] = metrics
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(bundle); err != nil {
+		return fmt.Errorf("导出诊断包失败: %v", err)
+	}
+	return nil
+}
+
+// bundleVersion 版本信息
+func (db *DB) bundleVersion() map[string]interface{} {
+	version := map[string]interface{}{
+		"go":          runtime.Version(),
+		"os":          runtime.GOOS,
+		"arch":        runtime.GOARCH,
+		"xlorm":       "unknown",
+		"instance_id": db.instanceID,
+		"start_time":  db.startTime,
+		"uptime":      time.Since(db.startTime).String(),
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Path == xlormModulePath {
+			version["xlorm"] = info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == xlormModulePath {
+				version["xlorm"] = dep.Version
+			}
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), supportBundleTimeout)
+	defer cancel()
+	var server string
+	if err := db.DB.QueryRowContext(ctx, "SELECT VERSION()").Scan(&server); err != nil {
+		version["mysql"] = "获取失败: " + err.Error()
+	} else {
+		version["mysql"] = server
+	}
+	if info := db.ServerInfo(); info.Flavor != FlavorUnknown {
+		version["server"] = info.String()
+	}
+	return version
+}
+
+// bundleConfig 将配置转换为可编码为 JSON 的键值，函数和接口类型字段只导出是否设置及类型
+func bundleConfig(cfg Config) map[string]interface{} {
+	v := reflect.ValueOf(cfg)
+	typ := v.Type()
+	result := make(map[string]interface{}, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := v.Field(i)
+		value := configValue(field)
+		switch {
+		case field.Kind() == reflect.Ptr && !field.IsNil():
+			value = fmt.Sprintf("<%T>", field.Interface())
+		case field.Type() == reflect.TypeOf(time.Duration(0)):
+			value = time.Duration(field.Int()).String()
+		}
+		result[typ.Field(i).Name] = value
+	}
+	return result
+}
+
+// bundlePool 连接池当前状态及统计历史
+func (db *DB) bundlePool() map[string]interface{} {
+	history := db.PoolStatsHistory()
+	samples := make([]map[string]interface{}, len(history))
+	for i, sample := range history {
+		samples[i] = poolStatsMap(sample.Stats)
+		samples[i]["time"] = sample.Time
+	}
+	return map[string]interface{}{
+		"current": poolStatsMap(db.DB.Stats()),
+		"history": samples,
+	}
+}
+
+// bundleSlowQueries 最近的慢查询，参数可能包含敏感数据，不导出
+func (db *DB) bundleSlowQueries() []map[string]interface{} {
+	queries := db.SlowQueries()
+	result := make([]map[string]interface{}, len(queries))
+	for i, q := range queries {
+		result[i] = map[string]interface{}{
+			"trace_id":    q.TraceID,
+			"operation":   q.Operation,
+			"table":       q.Table,
+			"query":       q.Query,
+			"fingerprint": q.Fingerprint,
+			"start_time":  q.StartTime,
+			"duration":    q.Duration.String(),
+			"rows":        q.Rows,
+		}
+	}
+	return result
+}
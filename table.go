@@ -20,7 +20,12 @@ const (
 // Table 表操作结构体
 type Table struct {
 	db        *DB
+	ctx       context.Context // 本次操作的上下文，通过 WithContext 设置
+	name      string          // 调用 M 时传入的表名（不含表前缀）
 	tableName string
+	prefix    string // 本次操作使用的表前缀
+	prefixSet bool   // 是否已指定（或从上下文解析出）表前缀
+	shard     string // 分表后缀，例如 07 表示 name_07
 	orderBy   string
 	groupBy   string
 	having    string
@@ -33,6 +38,32 @@ type Table struct {
 	offset    int64
 	hasTotal  bool // 是否需要获取总数
 
+	softDeleteField string // 软删除字段
+	unscoped        bool   // 是否忽略软删除过滤
+	noGlobalScopes  bool   // 是否忽略全局和表级作用域
+	onlyTrashed     bool   // 是否仅匹配已软删除的记录
+
+	hooks    map[HookType][]Hook // 仅对本次操作生效的钩子
+	preloads []string            // FindAllInto 时预加载的关联字段
+
+	scopes    []string      // 内部作用域条件（如游标分页），始终以 AND 连接在查询条件之后
+	scopeArgs []interface{} // 内部作用域条件参数
+
+	chunkField string // 分块读取使用的有序唯一字段
+	chunkSize  int64  // 分块读取每块记录数
+
+	cacheKey    string        // 查询结果缓存键
+	cacheTTL    time.Duration // 查询结果缓存时间
+	cacheAuto   bool          // 是否根据SQL和参数自动生成缓存键
+	cacheTables []string      // 缓存关联的其他表
+
+	aggregate string // 聚合查询的表达式，如 SUM(`amount`)
+
+	timeout  time.Duration  // 每条语句的超时时间，为0时使用 Config.DefaultQueryTimeout
+	settings *TableSettings // 通过 SetTableSettings 注册的默认操作设置
+	owner    uint64         // 创建该对象的 goroutine 编号，仅在开启 CheckTableMisuse 时记录，0 表示不检查
+	released bool           // 开启 CheckTableMisuse 时标记已释放，用于检测执行后的复用
+
 	// 新增位运算相关字段
 	conditionFlags uint64
 	conditionIndex int
@@ -43,6 +74,11 @@ func (t *Table) Release() {
 	if t.db.IsDebug() {
 		t.db.logger.Debug("释放Table对象", "table", t.tableName)
 	}
+	if t.checkMisuse() {
+		// 检测模式下不放回对象池，保留状态以便发现执行后的复用
+		t.released = true
+		return
+	}
 	t.Reset()
 	tablePool.Put(t)
 }
@@ -50,7 +86,12 @@ func (t *Table) Release() {
 // Reset 重置Table对象的状态
 func (t *Table) Reset() {
 	t.db = nil
+	t.ctx = nil
+	t.name = ""
 	t.tableName = ""
+	t.prefix = ""
+	t.prefixSet = false
+	t.shard = ""
 	t.orderBy = ""
 	t.limit = 0
 	t.offset = 0
@@ -62,24 +103,64 @@ func (t *Table) Reset() {
 	t.joins = nil
 	t.hasTotal = false
 	t.total = 0
+	t.softDeleteField = ""
+	t.unscoped = false
+	t.noGlobalScopes = false
+	t.onlyTrashed = false
+	t.hooks = nil
+	t.preloads = nil
+	t.scopes = nil
+	t.scopeArgs = nil
+	t.chunkField = ""
+	t.chunkSize = 0
+	t.cacheKey = ""
+	t.cacheTTL = 0
+	t.cacheAuto = false
+	t.cacheTables = nil
+	t.aggregate = ""
+	t.timeout = 0
+	t.settings = nil
+	t.owner = 0
+	t.released = false
 
 	// 重置新增字段
 	t.conditionFlags = 0
 	t.conditionIndex = 0
 }
 
+// WithContext 设置本次操作的上下文，未带 ctx 参数的操作（如 Find、Insert、Count）使用该上下文，
+// 未设置时使用数据库连接的上下文
 func (t *Table) WithContext(ctx context.Context) *Table {
-	t.db.ctxMu.Lock()
-	defer t.db.ctxMu.Unlock()
-	t.db.ctx = ctx
+	if t.misused("WithContext") {
+		return t
+	}
+	t.ctx = ctx
 	return t
 }
 
+// context 获取本次操作的上下文，未通过 WithContext 设置时使用数据库连接的上下文
+func (t *Table) context() context.Context {
+	if t.ctx != nil {
+		return t.ctx
+	}
+	return t.db.GetContext()
+}
+
+// resolveContext 传入的 ctx 为 nil 时使用本次操作的上下文
+func (t *Table) resolveContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return t.context()
+	}
+	// 保存上下文，供全局作用域读取
+	t.ctx = ctx
+	return ctx
+}
+
 // Insert 插入记录
 // lastInsertId 返回插入的记录的ID
 // err 返回错误信息
 func (t *Table) Insert(data interface{}) (lastInsertId int64, err error) {
-	return t.insert(context.Background(), data, "INSERT")
+	return t.insert(t.context(), data, "INSERT")
 }
 
 // InsertWithContext 插入记录
@@ -91,7 +172,7 @@ func (t *Table) InsertWithContext(ctx context.Context, data interface{}) (lastIn
 
 // Update 更新记录
 func (t *Table) Update(data interface{}) (rowsAffected int64, err error) {
-	return t.update(context.Background(), data)
+	return t.update(t.context(), data)
 }
 
 // UpdateWithContext 更新记录
@@ -99,9 +180,88 @@ func (t *Table) UpdateWithContext(ctx context.Context, data interface{}) (rowsAf
 	return t.update(ctx, data)
 }
 
+// UpdateIf 在额外条件成立时更新记录
+// 额外条件加括号后以 AND 连接在已有的查询条件之后，不会被其中的 OR 条件绕过；changed 表示是否有记录被修改
+func (t *Table) UpdateIf(data interface{}, condition string, args ...interface{}) (changed bool, err error) {
+	if condition == "" {
+		t.Release()
+		return false, errors.New("UpdateIf 必须指定条件")
+	}
+	if strings.Count(condition, "?") != len(args) || strings.ContainsAny(condition, ";\x00") {
+		t.Release()
+		return false, fmt.Errorf("UpdateIf 条件非法: %s", condition)
+	}
+	if len(t.where) == 0 {
+		// 没有其他查询条件时额外条件即为更新条件
+		t.Where(condition, args...)
+	} else {
+		t.addScope("("+condition+")", args...)
+	}
+	rowsAffected, err := t.update(t.context(), data)
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// UpdateWhereEquals 比较并交换(CAS)：仅当字段当前值等于 expected 时更新为 newValue
+// changed 表示是否更新成功，为 false 时说明字段值已被其他请求修改
+func (t *Table) UpdateWhereEquals(field string, expected, newValue interface{}) (changed bool, err error) {
+	if !isValidFieldName(field) {
+		t.Release()
+		return false, fmt.Errorf("字段名非法: %s", field)
+	}
+	condition := quoteColumn(field) + " = ?"
+	if expected == nil {
+		condition = quoteColumn(field) + " IS NULL"
+		return t.UpdateIf(map[string]interface{}{field: newValue}, condition)
+	}
+	return t.UpdateIf(map[string]interface{}{field: newValue}, condition, expected)
+}
+
+// Increment 字段原子自增，生成 `field` = `field` + ?，避免先读后写的并发问题
+// extra 为同时更新的其他字段，例如：db.M("accounts").Where("id = ?", 1).Increment("balance", 10, map[string]interface{}{"updated_at": time.Now()})
+func (t *Table) Increment(field string, n interface{}, extra ...map[string]interface{}) (rowsAffected int64, err error) {
+	return t.IncrementWithContext(t.context(), field, n, extra...)
+}
+
+// IncrementWithContext 带上下文的Increment
+func (t *Table) IncrementWithContext(ctx context.Context, field string, n interface{}, extra ...map[string]interface{}) (rowsAffected int64, err error) {
+	return t.incrementBy(ctx, field, Inc(n), extra)
+}
+
+// Decrement 字段原子自减，生成 `field` = `field` - ?
+func (t *Table) Decrement(field string, n interface{}, extra ...map[string]interface{}) (rowsAffected int64, err error) {
+	return t.DecrementWithContext(t.context(), field, n, extra...)
+}
+
+// DecrementWithContext 带上下文的Decrement
+func (t *Table) DecrementWithContext(ctx context.Context, field string, n interface{}, extra ...map[string]interface{}) (rowsAffected int64, err error) {
+	return t.incrementBy(ctx, field, Dec(n), extra)
+}
+
+// incrementBy 使用自增或自减表达式更新字段
+func (t *Table) incrementBy(ctx context.Context, field string, e *expression, extra []map[string]interface{}) (int64, error) {
+	if !isValidFieldName(field) {
+		t.Release()
+		return 0, fmt.Errorf("字段名非法: %s", field)
+	}
+	data := map[string]interface{}{field: e}
+	for _, m := range extra {
+		for k, v := range m {
+			if k == field {
+				t.Release()
+				return 0, fmt.Errorf("字段 %s 不能同时自增和更新", field)
+			}
+			data[k] = v
+		}
+	}
+	return t.update(ctx, data)
+}
+
 // Delete 删除记录
 func (t *Table) Delete() (rowsAffected int64, err error) {
-	return t.delete(context.Background())
+	return t.delete(t.context())
 }
 
 // DeleteWithContext 删除记录
@@ -113,7 +273,7 @@ func (t *Table) DeleteWithContext(ctx context.Context) (rowsAffected int64, err
 func (t *Table) Find() (map[string]interface{}, error) {
 	t.limit = 1
 	t.hasTotal = false
-	records, err := t.findAllWithContext(context.Background(), "find")
+	records, err := t.findAllWithContext(t.context(), "find")
 	if err != nil {
 		return nil, err
 	}
@@ -136,7 +296,7 @@ func (t *Table) Find() (map[string]interface{}, error) {
 //   - "获取列信息失败": 无法获取结果集的列信息，可能是由于表结构发生变化
 //   - "扫描数据失败": 将数据库返回的数据转换为Go类型时失败
 func (t *Table) FindAll() ([]map[string]interface{}, error) {
-	return t.findAllWithContext(context.Background(), "findAll")
+	return t.findAllWithContext(t.context(), "findAll")
 }
 
 // FindAllWithContext 带上下文的FindAll
@@ -146,18 +306,24 @@ func (t *Table) FindAllWithContext(ctx context.Context) ([]map[string]interface{
 
 // FindAllWithCursor 使用游标逐行读取数据，减少内存占用
 // handler 是处理每一行记录的回调函数，返回error时会中止处理
-func (t *Table) FindAllWithCursor(ctx context.Context, handler func(map[string]interface{}) error) error {
+func (t *Table) FindAllWithCursor(ctx context.Context, handler func(map[string]interface{}) error) (err error) {
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, "findAllWithCursor", t.rawTableName(), &err)
+	if err = t.checkUse("findAllWithCursor"); err != nil {
+		return
+	}
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
 	defer t.Release()
+	t.resolvePrefix(ctx)
 	startTime := time.Now()
 	// 如果需要获取总数，先执行 Count 查询
 	if t.hasTotal {
 		// 创建一个新的Table对象用于Count查询，避免影响当前查询
-		countTable := t.db.M(t.tableName)
-		// 复制查询条件
-		t.copyQueryConditions(countTable)
+		countTable := t.cloneTable()
 
 		// 执行Count查询
-		total, err := countTable.Count()
+		total, err := countTable.count(ctx)
 		if err != nil {
 			return fmt.Errorf("获取记录总数失败: %v", err)
 		}
@@ -167,15 +333,29 @@ func (t *Table) FindAllWithCursor(ctx context.Context, handler func(map[string]i
 	// 构建查询SQL
 	query, args := t.buildQuery("SELECT")
 
-	if t.db.IsDebug() {
-		t.db.logger.Debug("执行SQL", "findAllWithContext", query, "args", args)
+	hc := t.newHookContext(ctx, nil)
+	hc.Query, hc.Args = query, args
+	if err := t.runHooks(BeforeQuery, hc); err != nil {
+		return err
+	}
+	if t.dryRun(ctx, "findAllWithCursor", query, args) {
+		return nil
 	}
 
+	t.db.logQuery(ctx, "执行SQL", "findAllWithContext", query, args)
+
 	// 执行查询
-	rows, err := t.db.QueryContext(ctx, query, args...)
+	if err := t.db.checkQueryCost(ctx, query, args); err != nil {
+		return err
+	}
+	qi := t.newQueryInfo(ctx, "findAllWithCursor", query, args)
+	t.db.beforeQuery(qi)
+	defer t.db.afterQuery(qi)
+	rows, err := t.db.QueryContext(ctx, t.db.tagQuery(ctx, query), args...)
 	if err != nil {
+		qi.err = err
 		t.db.asyncDBMetrics.RecordError()
-		t.db.logger.Error("执行查询失败", "findAllWithContext", query, "args", args, "error", err)
+		t.db.logger.Error("执行查询失败", "findAllWithContext", query, "args", t.db.redactArgs(query, args), "error", err)
 		return fmt.Errorf("执行查询失败: %v", err)
 	}
 	defer rows.Close()
@@ -183,8 +363,9 @@ func (t *Table) FindAllWithCursor(ctx context.Context, handler func(map[string]i
 	// 获取列信息
 	columns, err := rows.Columns()
 	if err != nil {
+		qi.err = err
 		t.db.asyncDBMetrics.RecordError()
-		t.db.logger.Error("获取列信息失败", "findAllWithContext", query, "args", args, "error", err)
+		t.db.logger.Error("获取列信息失败", "findAllWithContext", query, "args", t.db.redactArgs(query, args), "error", err)
 		return fmt.Errorf("获取列信息失败: %v", err)
 	}
 
@@ -201,9 +382,11 @@ func (t *Table) FindAllWithCursor(ctx context.Context, handler func(map[string]i
 	for rows.Next() {
 		// 扫描数据
 		if err := rows.Scan(scanArgs...); err != nil {
+			err = newRowsScanError(err, t.rawTableName(), columns, scanArgs, int(qi.rows)+1)
+			qi.err = err
 			t.db.asyncDBMetrics.RecordError()
-			t.db.logger.Error("扫描数据失败", "findAllWithContext", query, "args", args, "error", err)
-			return fmt.Errorf("扫描数据失败: %v", err)
+			t.db.logger.Error("扫描数据失败", "findAllWithContext", query, "args", t.db.redactArgs(query, args), "error", err)
+			return err
 		}
 
 		// 转换为map
@@ -218,6 +401,8 @@ func (t *Table) FindAllWithCursor(ctx context.Context, handler func(map[string]i
 			}
 		}
 
+		qi.rows++
+
 		// 调用处理函数
 		if err := handler(record); err != nil {
 			return err // 允许调用方中止处理流程
@@ -226,8 +411,9 @@ func (t *Table) FindAllWithCursor(ctx context.Context, handler func(map[string]i
 
 	// 检查遍历错误
 	if err := rows.Err(); err != nil {
+		qi.err = err
 		t.db.asyncDBMetrics.RecordError()
-		t.db.logger.Error("遍历结果集失败", "findAllWithContext", query, "args", args, "error", err)
+		t.db.logger.Error("遍历结果集失败", "findAllWithContext", query, "args", t.db.redactArgs(query, args), "error", err)
 		return fmt.Errorf("遍历结果集失败: %v", err)
 	}
 
@@ -237,33 +423,67 @@ func (t *Table) FindAllWithCursor(ctx context.Context, handler func(map[string]i
 
 	if duration >= t.db.slowQueryThreshold {
 		t.db.asyncDBMetrics.RecordSlowQuery()
-		t.db.logger.Warn("慢查询",
+		t.db.logger.WarnContext(ctx, "慢查询",
 			"query", query,
-			"args", args,
+			"args", t.db.redactArgs(query, args),
 			"duration", duration.Seconds(),
 			"threshold", t.db.slowQueryThreshold,
 		)
 	}
 
-	return nil
+	return t.runHooks(AfterQuery, hc)
 }
 
 // Count 获取记录数
 func (t *Table) Count() (int64, error) {
+	return t.count(t.context())
+}
+
+// CountWithContext 带上下文的Count
+func (t *Table) CountWithContext(ctx context.Context) (int64, error) {
+	return t.count(ctx)
+}
+
+// count 实际执行记录数查询
+func (t *Table) count(ctx context.Context) (_ int64, err error) {
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, "count", t.rawTableName(), &err)
+	if err = t.checkUse("count"); err != nil {
+		return
+	}
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
 	defer t.Release()
+	t.resolvePrefix(ctx)
 	startTime := time.Now()
 	query, args := t.buildQuery("COUNT")
+	hc := t.newHookContext(ctx, nil)
+	hc.Query, hc.Args = query, args
+	if err := t.runHooks(BeforeQuery, hc); err != nil {
+		return 0, err
+	}
+	if t.dryRun(ctx, "count", query, args) {
+		return 0, nil
+	}
 	var count int64
-	if t.db.IsDebug() {
-		t.db.logger.Debug("执行SQL", "count", query, "args", args)
+	t.db.logQuery(ctx, "执行SQL", "count", query, args)
+	if err := t.db.checkQueryCost(ctx, query, args); err != nil {
+		return 0, err
 	}
-	err := t.db.QueryRow(query, args...).Scan(&count)
+	qi := t.newQueryInfo(ctx, "count", query, args)
+	t.db.beforeQuery(qi)
+	err = t.db.QueryRowContext(ctx, t.db.tagQuery(ctx, query), args...).Scan(&count)
+	qi.rows, qi.err = 1, err
+	t.db.afterQuery(qi)
 	if err != nil {
 		t.db.asyncDBMetrics.RecordError()
-		t.db.logger.Error("执行查询失败", "count", query, "args", args, "error", err)
+		t.db.logger.Error("执行查询失败", "count", query, "args", t.db.redactArgs(query, args), "error", err)
 		return 0, fmt.Errorf("执行查询失败: %v", err)
 	}
 	t.db.asyncDBMetrics.RecordQueryDuration("count", time.Since(startTime))
+	if err := t.runHooks(AfterQuery, hc); err != nil {
+		return count, err
+	}
 	return count, nil
 }
 
@@ -276,18 +496,80 @@ func (t *Table) GetTotal() int64 {
 	return t.total
 }
 
+// WithPrefix 指定本次操作使用的表前缀，覆盖配置的 TablePrefix 和上下文解析出的前缀
+// 例如：db.M("orders").WithPrefix("tenant42_") 操作表 tenant42_orders，WithPrefix("") 不使用前缀
+func (t *Table) WithPrefix(prefix string) *Table {
+	if t.misused("WithPrefix") {
+		return t
+	}
+	if prefix != "" && !isValidFieldName(prefix) {
+		t.db.logger.Error("表前缀非法", "prefix", prefix)
+		return t
+	}
+	t.setPrefix(prefix)
+	return t
+}
+
+// Prefix WithPrefix的别名
+func (t *Table) Prefix(prefix string) *Table {
+	if t.misused("Prefix") {
+		return t
+	}
+	return t.WithPrefix(prefix)
+}
+
+// setPrefix 使用指定表前缀重新生成完整表名
+func (t *Table) setPrefix(prefix string) {
+	if t.name == "" {
+		return
+	}
+	t.prefix = prefix
+	t.prefixSet = true
+	t.tableName = t.db.buildTableName(t.physicalName(), prefix)
+}
+
+// resolvePrefix 未指定表前缀时，依次从上下文和 TablePrefixResolver 解析表前缀
+func (t *Table) resolvePrefix(ctx context.Context) {
+	if t.prefixSet || ctx == nil {
+		return
+	}
+	prefix, ok := tablePrefixFromContext(ctx)
+	if !ok && t.db.tablePreResolver != nil {
+		prefix, ok = t.db.tablePreResolver(ctx)
+	}
+	if !ok {
+		return
+	}
+	if prefix != "" && !isValidFieldName(prefix) {
+		t.db.logger.Error("表前缀非法", "prefix", prefix)
+		return
+	}
+	t.setPrefix(prefix)
+}
+
+// rawTableName 获取不带反引号的完整表名（含表前缀，跨库时形如 schema.table）
+func (t *Table) rawTableName() string {
+	return strings.ReplaceAll(t.tableName, "`", "")
+}
+
 // GetWhere 获取WHERE子句
+// 作用域条件（如软删除过滤）以 AND 连接在加括号的查询条件之后
 func (t *Table) GetWhere(addPreStr bool) (string, []interface{}) {
-	// 添加条件
-	if len(t.where) > 0 {
-		// 预估SQL长度，避免频繁扩容
-		query := strings.Builder{}
-		query.Grow(256)
+	scopes, scopeArgs := t.scopeConditions()
+	if len(t.where) == 0 && len(scopes) == 0 {
+		return "", nil
+	}
 
-		if addPreStr {
-			query.WriteString(" WHERE ")
-		}
+	// 预估SQL长度，避免频繁扩容
+	query := strings.Builder{}
+	query.Grow(256)
 
+	if addPreStr {
+		query.WriteString(" WHERE ")
+	}
+
+	// 添加条件
+	if len(t.where) > 0 {
 		// 使用位运算快速判断条件类型
 		switch {
 		case t.conditionFlags&condOR != 0:
@@ -312,6 +594,12 @@ func (t *Table) GetWhere(addPreStr bool) (string, []interface{}) {
 			}
 			query.WriteByte(')')
 
+		case len(scopes) > 0:
+			// 存在作用域条件，使用括号避免条件中的 OR 与作用域条件的 AND 优先级混淆
+			query.WriteByte('(')
+			query.WriteString(strings.Join(t.where, " AND "))
+			query.WriteByte(')')
+
 		default:
 			// 纯 AND 条件，直接连接
 			query.WriteString(strings.Join(t.where, " AND "))
@@ -319,14 +607,50 @@ func (t *Table) GetWhere(addPreStr bool) (string, []interface{}) {
 		// 重置条件标志（重要！）
 		t.conditionFlags = 0
 		t.conditionIndex = 0
-		return query.String(), t.args
 	}
 
-	return "", nil
+	args := t.args
+	// 添加作用域条件
+	if len(scopes) > 0 {
+		if len(t.where) > 0 {
+			query.WriteString(" AND ")
+		}
+		query.WriteString(strings.Join(scopes, " AND "))
+		args = append(args[:len(args):len(args)], scopeArgs...)
+	}
+	return query.String(), args
+}
+
+// scopeConditions 获取作用域条件（软删除过滤、全局作用域、内部作用域），与查询条件以 AND 连接
+func (t *Table) scopeConditions() ([]string, []interface{}) {
+	condition := t.softDeleteCondition()
+	globalScopes, globalArgs := t.globalScopeConditions()
+	if condition == "" && len(globalScopes) == 0 {
+		return t.scopes, t.scopeArgs
+	}
+	scopes := make([]string, 0, len(t.scopes)+len(globalScopes)+1)
+	if condition != "" {
+		scopes = append(scopes, condition)
+	}
+	scopes = append(scopes, globalScopes...)
+	scopes = append(scopes, t.scopes...)
+	args := make([]interface{}, 0, len(globalArgs)+len(t.scopeArgs))
+	args = append(args, globalArgs...)
+	args = append(args, t.scopeArgs...)
+	return scopes, args
+}
+
+// addScope 添加内部作用域条件
+func (t *Table) addScope(condition string, args ...interface{}) {
+	t.scopes = append(t.scopes, condition)
+	t.scopeArgs = append(t.scopeArgs, args...)
 }
 
 // Where 添加查询条件
 func (t *Table) Where(condition string, args ...interface{}) *Table {
+	if t.misused("Where") {
+		return t
+	}
 	if condition == "" {
 		return t
 	}
@@ -360,6 +684,9 @@ func (t *Table) Where(condition string, args ...interface{}) *Table {
 
 // OrWhere 添加 OR 查询条件
 func (t *Table) OrWhere(condition string, args ...interface{}) *Table {
+	if t.misused("OrWhere") {
+		return t
+	}
 	if condition == "" {
 		return t
 	}
@@ -391,6 +718,9 @@ func (t *Table) OrWhere(condition string, args ...interface{}) *Table {
 
 // NotWhere 添加 NOT 查询条件
 func (t *Table) NotWhere(condition string, args ...interface{}) *Table {
+	if t.misused("NotWhere") {
+		return t
+	}
 	if condition == "" {
 		return t
 	}
@@ -422,27 +752,85 @@ func (t *Table) NotWhere(condition string, args ...interface{}) *Table {
 	return t
 }
 
+// WhereIn 添加 IN 查询条件，values 为切片，会自动展开为 (?,?,?) 占位符
+// 例如：WhereIn("id", []int{1, 2, 3}) 生成 `id` IN (?,?,?)，空切片生成恒假条件 1 = 0
+func (t *Table) WhereIn(field string, values interface{}) *Table {
+	if list := t.fieldAllowlist(); list != nil {
+		if _, ok := allowFields(list.filterable, field); !ok {
+			t.db.logger.Error("过滤字段不在白名单中", "table", t.name, "field", field)
+			return t.rejectFilter()
+		}
+	}
+	return t.whereIn(field, values, false)
+}
+
+// WhereNotIn 添加 NOT IN 查询条件，values 为切片，空切片生成恒真条件 1 = 1
+func (t *Table) WhereNotIn(field string, values interface{}) *Table {
+	if list := t.fieldAllowlist(); list != nil {
+		if _, ok := allowFields(list.filterable, field); !ok {
+			t.db.logger.Error("过滤字段不在白名单中", "table", t.name, "field", field)
+			return t.rejectFilter()
+		}
+	}
+	return t.whereIn(field, values, true)
+}
+
+// whereIn 添加 IN/NOT IN 条件，不校验字段白名单，供内部按开发者定义的字段查询时使用
+func (t *Table) whereIn(field string, values interface{}, not bool) *Table {
+	condition, args, err := buildInCondition(field, values, not)
+	if err != nil {
+		msg := "WhereIn条件非法"
+		if not {
+			msg = "WhereNotIn条件非法"
+		}
+		t.db.logger.Error(msg, "field", field, "error", err)
+		return t
+	}
+	return t.Where(condition, args...)
+}
+
 // OrderBy 添加排序条件
 func (t *Table) OrderBy(order string) *Table {
-	if order == "" {
+	if t.misused("OrderBy") {
 		return t
 	}
-	if !isValidSafeOrderBy(order) {
-		t.db.logger.Error("非法排序字段", "order", order)
+	if order == "" {
 		return t
 	}
-	// 检查SQL注入
-	if strings.ContainsAny(order, ";\x00") {
-		t.db.logger.Error("检测到可能的SQL注入尝试", "order", order)
+	sanitized, err := sanitizeOrderBy(order)
+	if err != nil {
+		t.db.logger.Error("非法排序字段", "order", order, "error", err)
 		return t
 	}
+	if list := t.fieldAllowlist(); list != nil {
+		if field, ok := allowFields(list.sortable, orderByFields(order)...); !ok {
+			t.db.logger.Error("排序字段不在白名单中", "table", t.name, "field", field)
+			return t
+		}
+	}
+
+	t.orderBy = sanitized
+	return t
+}
 
-	t.orderBy = order
+// OrderByRaw 使用原始SQL片段排序，不做校验，例如：OrderByRaw(xlorm.Raw("FIELD(status, 2, 1, 0)"))
+func (t *Table) OrderByRaw(raw RawSQL) *Table {
+	if t.misused("OrderByRaw") {
+		return t
+	}
+	if raw.sql == "" {
+		t.db.logger.Error(errEmptyRaw.Error(), "clause", "ORDER BY")
+		return t
+	}
+	t.orderBy = raw.sql
 	return t
 }
 
 // Limit 添加限制条件
 func (t *Table) Limit(limit int64) *Table {
+	if t.misused("Limit") {
+		return t
+	}
 	if limit < 0 {
 		t.db.logger.Error("limit不能为负数", "limit", limit)
 		return t
@@ -453,6 +841,9 @@ func (t *Table) Limit(limit int64) *Table {
 
 // Page 设置分页
 func (t *Table) Page(page, pageSize int64) *Table {
+	if t.misused("Page") {
+		return t
+	}
 	if page < 1 {
 		page = 1
 	}
@@ -466,6 +857,9 @@ func (t *Table) Page(page, pageSize int64) *Table {
 
 // Offset 添加偏移量
 func (t *Table) Offset(offset int64) *Table {
+	if t.misused("Offset") {
+		return t
+	}
 	if offset < 0 {
 		t.db.logger.Error("offset不能为负数", "offset", offset)
 		return t
@@ -476,6 +870,9 @@ func (t *Table) Offset(offset int64) *Table {
 
 // Fields 设置查询字段
 func (t *Table) Fields(fields ...string) *Table {
+	if t.misused("Fields") {
+		return t
+	}
 	if len(fields) == 0 {
 		return t
 	}
@@ -489,6 +886,12 @@ func (t *Table) Fields(fields ...string) *Table {
 			t.db.logger.Error("检测到可能的SQL注入尝试", "field", field)
 			return t
 		}
+		if list := t.fieldAllowlist(); list != nil {
+			if _, ok := allowFields(list.selectable, field); !ok {
+				t.db.logger.Error("查询字段不在白名单中", "table", t.name, "field", field)
+				return t
+			}
+		}
 		t.fields = append(t.fields, field)
 	}
 	return t
@@ -496,6 +899,9 @@ func (t *Table) Fields(fields ...string) *Table {
 
 // Join 添加表连接
 func (t *Table) Join(join string) *Table {
+	if t.misused("Join") {
+		return t
+	}
 	if join == "" {
 		return t
 	}
@@ -512,29 +918,56 @@ func (t *Table) Join(join string) *Table {
 
 // GroupBy 添加分组条件
 func (t *Table) GroupBy(groupBy string) *Table {
+	if t.misused("GroupBy") {
+		return t
+	}
 	if groupBy == "" {
 		return t
 	}
 
-	// 检查SQL注入
-	if strings.ContainsAny(groupBy, ";\x00") {
-		t.db.logger.Error("检测到可能的SQL注入尝试", "groupBy", groupBy)
+	sanitized, err := sanitizeGroupBy(groupBy)
+	if err != nil {
+		t.db.logger.Error("检测到可能的SQL注入尝试", "groupBy", groupBy, "error", err)
 		return t
 	}
+	if list := t.fieldAllowlist(); list != nil && list.groupable != nil {
+		for _, field := range strings.Split(groupBy, ",") {
+			field = strings.TrimSpace(field)
+			if _, ok := allowFields(list.groupable, field); !ok {
+				t.db.logger.Error("分组字段不在白名单中", "table", t.name, "field", field)
+				return t
+			}
+		}
+	}
 
-	t.groupBy = groupBy
+	t.groupBy = sanitized
+	return t
+}
+
+// GroupByRaw 使用原始SQL片段分组，不做校验，例如：GroupByRaw(xlorm.Raw("DATE(created_at)"))
+func (t *Table) GroupByRaw(raw RawSQL) *Table {
+	if t.misused("GroupByRaw") {
+		return t
+	}
+	if raw.sql == "" {
+		t.db.logger.Error(errEmptyRaw.Error(), "clause", "GROUP BY")
+		return t
+	}
+	t.groupBy = raw.sql
 	return t
 }
 
 // Having 添加分组过滤条件
 func (t *Table) Having(having string) *Table {
+	if t.misused("Having") {
+		return t
+	}
 	if having == "" {
 		return t
 	}
 
-	// 检查SQL注入
-	if strings.ContainsAny(having, ";\x00") {
-		t.db.logger.Error("检测到可能的SQL注入尝试", "having", having)
+	if err := sanitizeHaving(having); err != nil {
+		t.db.logger.Error("检测到可能的SQL注入尝试", "having", having, "error", err)
 		return t
 	}
 
@@ -542,17 +975,41 @@ func (t *Table) Having(having string) *Table {
 	return t
 }
 
+// HavingRaw 使用原始SQL片段作为分组过滤条件，不做校验，例如：HavingRaw(xlorm.Raw("COUNT(*) > 1"))
+func (t *Table) HavingRaw(raw RawSQL) *Table {
+	if t.misused("HavingRaw") {
+		return t
+	}
+	if raw.sql == "" {
+		t.db.logger.Error(errEmptyRaw.Error(), "clause", "HAVING")
+		return t
+	}
+	t.having = raw.sql
+	return t
+}
+
 // HasTotal 设置是否需要获取总数
 // 当设置为true时，在执行FindAll时会自动执行一次Count查询获取符合条件的记录总数
 // 可以通过GetTotal方法获取查询结果
 func (t *Table) HasTotal(need bool) *Table {
+	if t.misused("HasTotal") {
+		return t
+	}
 	t.hasTotal = need
 	return t
 }
 
 // findAllWithContext 实际执行带上下文的FindAll
-func (t *Table) findAllWithContext(ctx context.Context, findType string) ([]map[string]interface{}, error) {
+func (t *Table) findAllWithContext(ctx context.Context, findType string) (_ []map[string]interface{}, err error) {
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, findType, t.rawTableName(), &err)
+	if err = t.checkUse(findType); err != nil {
+		return
+	}
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
 	defer t.Release()
+	t.resolvePrefix(ctx)
 	startTime := time.Now()
 	if findType == "" {
 		findType = "findAllWithContext"
@@ -560,30 +1017,52 @@ func (t *Table) findAllWithContext(ctx context.Context, findType string) ([]map[
 	// 如果需要获取总数，先执行 Count 查询
 	if t.hasTotal {
 		// 创建一个新的Table对象用于Count查询，避免影响当前查询
-		countTable := t.db.M(t.tableName)
-		// 复制查询条件
-		t.copyQueryConditions(countTable)
+		countTable := t.cloneTable()
 
 		// 执行Count查询
-		total, err := countTable.Count()
+		total, err := countTable.count(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("获取记录总数失败: %v", err)
 		}
 		t.total = total
 	}
 
+	// 读取查询缓存
+	if t.cacheAuto {
+		t.cacheKey = t.autoCacheKey()
+	}
+	if t.cacheKey != "" {
+		if rows, ok := t.getCachedRows(); ok {
+			return rows, nil
+		}
+	}
+
 	// 构建查询SQL
 	query, args := t.buildQuery("SELECT")
 
-	if t.db.IsDebug() {
-		t.db.logger.Debug("执行SQL", findType, query, "args", args)
+	hc := t.newHookContext(ctx, nil)
+	hc.Query, hc.Args = query, args
+	if err := t.runHooks(BeforeQuery, hc); err != nil {
+		return nil, err
+	}
+	if t.dryRun(ctx, findType, query, args) {
+		return nil, nil
 	}
 
+	t.db.logQuery(ctx, "执行SQL", findType, query, args)
+
 	// 执行查询
-	rows, err := t.db.QueryContext(ctx, query, args...)
+	if err := t.db.checkQueryCost(ctx, query, args); err != nil {
+		return nil, err
+	}
+	qi := t.newQueryInfo(ctx, findType, query, args)
+	t.db.beforeQuery(qi)
+	defer t.db.afterQuery(qi)
+	rows, err := t.db.QueryContext(ctx, t.db.tagQuery(ctx, query), args...)
 	if err != nil {
+		qi.err = err
 		t.db.asyncDBMetrics.RecordError()
-		t.db.logger.Error("执行查询失败", findType, query, "args", args, "error", err)
+		t.db.logger.Error("执行查询失败", findType, query, "args", t.db.redactArgs(query, args), "error", err)
 		return nil, fmt.Errorf("执行查询失败: %v", err)
 	}
 	defer rows.Close()
@@ -591,8 +1070,9 @@ func (t *Table) findAllWithContext(ctx context.Context, findType string) ([]map[
 	// 获取列名
 	columns, err := rows.Columns()
 	if err != nil {
+		qi.err = err
 		t.db.asyncDBMetrics.RecordError()
-		t.db.logger.Error("获取列信息失败", findType, query, "args", args, "error", err)
+		t.db.logger.Error("获取列信息失败", findType, query, "args", t.db.redactArgs(query, args), "error", err)
 		return nil, fmt.Errorf("获取列信息失败: %v", err)
 	}
 
@@ -618,9 +1098,11 @@ func (t *Table) findAllWithContext(ctx context.Context, findType string) ([]map[
 	for rows.Next() {
 		// 扫描数据
 		if err := rows.Scan(scanArgs...); err != nil {
+			err = newRowsScanError(err, t.rawTableName(), columns, scanArgs, len(results)+1)
+			qi.err = err
 			t.db.asyncDBMetrics.RecordError()
-			t.db.logger.Error("扫描数据失败", findType, query, "args", args, "error", err)
-			return nil, fmt.Errorf("扫描数据失败: %v", err)
+			t.db.logger.Error("扫描数据失败", findType, query, "args", t.db.redactArgs(query, args), "error", err)
+			return nil, err
 		}
 
 		row := make(map[string]interface{}, columnsLen)
@@ -643,11 +1125,13 @@ func (t *Table) findAllWithContext(ctx context.Context, findType string) ([]map[
 
 		results = append(results, row)
 	}
+	qi.rows = int64(len(results))
 
 	// 检查遍历错误
 	if err = rows.Err(); err != nil {
+		qi.err = err
 		t.db.asyncDBMetrics.RecordError()
-		t.db.logger.Error("遍历结果集失败", findType, query, "args", args, "error", err)
+		t.db.logger.Error("遍历结果集失败", findType, query, "args", t.db.redactArgs(query, args), "error", err)
 		return nil, fmt.Errorf("遍历结果集失败: %v", err)
 	}
 
@@ -659,23 +1143,42 @@ func (t *Table) findAllWithContext(ctx context.Context, findType string) ([]map[
 
 	if duration >= t.db.slowQueryThreshold {
 		t.db.asyncDBMetrics.RecordSlowQuery()
-		t.db.logger.Warn("慢查询",
+		t.db.logger.WarnContext(ctx, "慢查询",
 			"query", query,
-			"args", args,
+			"args", t.db.redactArgs(query, args),
 			"duration", duration.Seconds(),
 			"threshold", t.db.slowQueryThreshold,
 			"rows", len(results),
 		)
 	}
 
-	return results, nil
+	hc.Rows = results
+	if err := t.runHooks(AfterQuery, hc); err != nil {
+		return nil, err
+	}
+	if t.cacheKey != "" {
+		t.setCachedRows(hc.Rows)
+	}
+	return hc.Rows, nil
 }
 
 // insert 内部插入方法
-func (t *Table) insert(ctx context.Context, data interface{}, insertType string) (int64, error) {
+func (t *Table) insert(ctx context.Context, data interface{}, insertType string) (_ int64, err error) {
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, "insert", t.rawTableName(), &err)
+	if err = t.checkUse("insert"); err != nil {
+		return
+	}
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
 	defer t.Release()
+	t.resolvePrefix(ctx)
 	startTime := time.Now()
-	fields, values, err := t.extractFieldsAndValues(data)
+	hc := t.newHookContext(ctx, data)
+	if err := t.runHooks(BeforeInsert, hc); err != nil {
+		return 0, err
+	}
+	fields, values, err := t.extractFieldsAndValues(hc.Data)
 	if err != nil {
 		return 0, err
 	}
@@ -683,21 +1186,30 @@ func (t *Table) insert(ctx context.Context, data interface{}, insertType string)
 	if len(fields) == 0 {
 		return 0, errors.New("插入的数据不能为空，字段名为空")
 	}
+	fields, values = t.withTimestamps(fields, values, true)
 
 	query, err := t.buildInsertSQL(insertType, fields)
 	if err != nil {
 		return 0, err
 	}
-
-	if t.db.IsDebug() {
-		t.db.logger.Debug("执行SQL", "insert", query, "args", values)
+	if t.dryRun(ctx, "insert", query, values) {
+		return 0, nil
 	}
 
+	t.db.logQuery(ctx, "执行SQL", "insert", query, values)
+
 	// 执行SQL
-	result, err := t.db.ExecContext(ctx, query, values...)
+	qi := t.newQueryInfo(ctx, "insert", query, values)
+	t.db.beforeQuery(qi)
+	result, err := t.db.ExecContext(ctx, t.db.tagQuery(ctx, query), values...)
+	qi.err = err
+	if err == nil {
+		qi.rows, _ = result.RowsAffected()
+	}
+	t.db.afterQuery(qi)
 	if err != nil {
 		t.db.asyncDBMetrics.RecordError()
-		t.db.logger.Error("执行SQL失败", "insert", query, "args", values, "error", err)
+		t.db.logger.ErrorContext(ctx, "执行SQL失败", "insert", query, "args", t.db.redactArgs(query, values), "error", err)
 		return 0, err
 	}
 
@@ -708,70 +1220,140 @@ func (t *Table) insert(ctx context.Context, data interface{}, insertType string)
 	}
 
 	t.db.asyncDBMetrics.RecordQueryDuration("insert", time.Since(startTime))
+	t.invalidateCache()
+
+	hc.Query, hc.Args = query, values
+	hc.LastInsertId = lastInsertId
+	hc.RowsAffected, _ = result.RowsAffected()
+	if err := t.runHooks(AfterInsert, hc); err != nil {
+		return lastInsertId, err
+	}
 	return lastInsertId, nil
 }
 
 func (t *Table) update(ctx context.Context, data interface{}) (int64, error) {
+	return t.updateWithHooks(ctx, data, BeforeUpdate, AfterUpdate, "update")
+}
+
+// updateWithHooks 执行更新并触发指定的钩子，软删除复用更新逻辑但触发删除钩子
+func (t *Table) updateWithHooks(ctx context.Context, data interface{}, before, after HookType, queryType string) (_ int64, err error) {
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, queryType, t.rawTableName(), &err)
+	if err = t.checkUse(queryType); err != nil {
+		return
+	}
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
 	defer t.Release()
+	t.resolvePrefix(ctx)
 	startTime := time.Now()
-	fields, values, err := t.extractFieldsAndValues(data)
+	hc := t.newHookContext(ctx, data)
+	if err := t.runHooks(before, hc); err != nil {
+		return 0, err
+	}
+	fields, values, err := t.extractFieldsAndValues(hc.Data)
 	if err != nil {
 		return 0, err
 	}
+	if queryType == "update" {
+		fields, values = t.withTimestamps(fields, values, false)
+	}
 
 	// 构建SQL语句
-	query, whereArgs, err := t.buildUpdateSQL(fields)
+	query, args, err := t.buildUpdateSQL(fields, values)
 	if err != nil {
 		return 0, err
 	}
-
-	// 合并参数
-	args := append(values, whereArgs...)
-
-	if t.db.IsDebug() {
-		t.db.logger.Debug("执行SQL", "update", query, "args", args)
+	if t.dryRun(ctx, queryType, query, args) {
+		return 0, nil
 	}
 
+	t.db.logQuery(ctx, "执行SQL", queryType, query, args)
+
 	// 执行SQL
-	result, err := t.db.ExecContext(ctx, query, args...)
+	qi := t.newQueryInfo(ctx, queryType, query, args)
+	t.db.beforeQuery(qi)
+	result, err := t.db.ExecContext(ctx, t.db.tagQuery(ctx, query), args...)
+	qi.err = err
+	if err == nil {
+		qi.rows, _ = result.RowsAffected()
+	}
+	t.db.afterQuery(qi)
 	if err != nil {
 		t.db.asyncDBMetrics.RecordError()
-		t.db.logger.Error("执行SQL失败", "update", query, "args", args, "error", err)
+		t.db.logger.ErrorContext(ctx, "执行SQL失败", queryType, query, "args", t.db.redactArgs(query, args), "error", err)
 		return 0, err
 	}
 
 	rowsAffected, _ := result.RowsAffected()
-	if t.db.IsDebug() {
-		t.db.logger.Debug("更新操作结果", "rowsAffected", rowsAffected)
-	}
+	t.db.logSQL(ctx, "更新操作结果", "rowsAffected", rowsAffected)
+
+	t.db.asyncDBMetrics.RecordQueryDuration(queryType, time.Since(startTime))
+	t.invalidateCache()
 
-	t.db.asyncDBMetrics.RecordQueryDuration("update", time.Since(startTime))
+	hc.Query, hc.Args = query, args
+	hc.RowsAffected = rowsAffected
+	if err := t.runHooks(after, hc); err != nil {
+		return rowsAffected, err
+	}
 	return rowsAffected, nil
 }
 
-func (t *Table) delete(ctx context.Context) (int64, error) {
+func (t *Table) delete(ctx context.Context) (_ int64, err error) {
+	ctx = t.resolveContext(ctx)
+	defer t.db.recoverPanic(ctx, "delete", t.rawTableName(), &err)
+	if err = t.checkUse("delete"); err != nil {
+		return
+	}
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	t.resolvePrefix(ctx)
+	// 开启软删除时改为更新软删除字段
+	if t.softDeleteField != "" && !t.unscoped {
+		return t.softDelete(ctx)
+	}
 	defer t.Release()
 	startTime := time.Now()
+	if len(t.where) == 0 {
+		return 0, errors.New("删除操作必须指定 WHERE 条件")
+	}
 	query, args := t.buildQuery("DELETE")
 	if query == "" || args == nil {
 		return 0, errors.New("构建查询语句失败，查询语句或参数为空")
 	}
-	if t.db.IsDebug() {
-		t.db.logger.Debug("执行SQL", "delete", query, "args", args)
+	hc := t.newHookContext(ctx, nil)
+	hc.Query, hc.Args = query, args
+	if err := t.runHooks(BeforeDelete, hc); err != nil {
+		return 0, err
 	}
+	if t.dryRun(ctx, "delete", query, args) {
+		return 0, nil
+	}
+	t.db.logQuery(ctx, "执行SQL", "delete", query, args)
 	// 执行SQL
-	result, err := t.db.ExecContext(ctx, query, args...)
+	qi := t.newQueryInfo(ctx, "delete", query, args)
+	t.db.beforeQuery(qi)
+	result, err := t.db.ExecContext(ctx, t.db.tagQuery(ctx, query), args...)
+	qi.err = err
+	if err == nil {
+		qi.rows, _ = result.RowsAffected()
+	}
+	t.db.afterQuery(qi)
 	if err != nil {
 		t.db.asyncDBMetrics.RecordError()
-		t.db.logger.Error("执行SQL失败", "delete", query, "args", args, "error", err)
+		t.db.logger.ErrorContext(ctx, "执行SQL失败", "delete", query, "args", t.db.redactArgs(query, args), "error", err)
 		return 0, err
 	}
 
 	rowsAffected, _ := result.RowsAffected()
-	if t.db.IsDebug() {
-		t.db.logger.Debug("删除操作结果", "rowsAffected", rowsAffected)
-	}
+	t.db.logSQL(ctx, "删除操作结果", "rowsAffected", rowsAffected)
 	t.db.asyncDBMetrics.RecordQueryDuration("delete", time.Since(startTime))
+	t.invalidateCache()
+
+	hc.RowsAffected = rowsAffected
+	if err := t.runHooks(AfterDelete, hc); err != nil {
+		return rowsAffected, err
+	}
 	return rowsAffected, nil
 }
 
@@ -814,6 +1396,33 @@ func (t *Table) copyQueryConditions(target *Table) {
 
 	target.groupBy = t.groupBy
 	target.having = t.having
+	target.softDeleteField = t.softDeleteField
+	target.unscoped = t.unscoped
+	target.noGlobalScopes = t.noGlobalScopes
+	target.onlyTrashed = t.onlyTrashed
+	target.conditionFlags = t.conditionFlags
+	if len(t.scopes) > 0 {
+		target.scopes = append([]string(nil), t.scopes...)
+		target.scopeArgs = append([]interface{}(nil), t.scopeArgs...)
+	}
+	target.conditionIndex = t.conditionIndex
+}
+
+// cloneTable 创建复制了查询条件的新Table对象
+func (t *Table) cloneTable() *Table {
+	target := tablePool.Get().(*Table)
+	target.Reset()
+	target.db = t.db
+	target.ctx = t.ctx
+	target.name = t.name
+	target.tableName = t.tableName
+	target.prefix = t.prefix
+	target.prefixSet = t.prefixSet
+	target.shard = t.shard
+	target.timeout = t.timeout
+	target.settings = t.settings
+	t.copyQueryConditions(target)
+	return target
 }
 
 // extractFieldsAndValues 提取字段和值
@@ -840,11 +1449,12 @@ func (t *Table) buildQuery(queryType string) (string, []interface{}) {
 	query.Grow(256)
 
 	var args []interface{}
+	selectKeyword := "SELECT " + t.executionHint()
 
 	// 构建基础查询
 	switch queryType {
 	case "SELECT":
-		query.WriteString("SELECT ")
+		query.WriteString(selectKeyword)
 		if len(t.fields) > 0 {
 			query.WriteString("`")
 			query.WriteString(strings.Join(t.fields, "`, `"))
@@ -856,7 +1466,17 @@ func (t *Table) buildQuery(queryType string) (string, []interface{}) {
 		query.WriteString(t.tableName)
 
 	case "COUNT":
-		query.WriteString("SELECT COUNT(*) FROM ")
+		query.WriteString(selectKeyword + "COUNT(*) FROM ")
+		query.WriteString(t.tableName)
+
+	case "AGGREGATE":
+		query.WriteString(selectKeyword)
+		query.WriteString(t.aggregate)
+		query.WriteString(" FROM ")
+		query.WriteString(t.tableName)
+
+	case "EXISTS":
+		query.WriteString(selectKeyword + "EXISTS(SELECT 1 FROM ")
 		query.WriteString(t.tableName)
 
 	case "DELETE":
@@ -877,13 +1497,11 @@ func (t *Table) buildQuery(queryType string) (string, []interface{}) {
 	}
 
 	// 添加条件
-	if len(t.where) > 0 {
-		whereString, whereArgs := t.GetWhere(true)
-		if whereString != "" {
-			args = make([]interface{}, 0, len(whereArgs))
-			query.WriteString(whereString)
-			args = append(args, whereArgs...)
-		}
+	whereString, whereArgs := t.GetWhere(true)
+	if whereString != "" {
+		args = make([]interface{}, 0, len(whereArgs))
+		query.WriteString(whereString)
+		args = append(args, whereArgs...)
 	}
 
 	// 添加分组
@@ -897,6 +1515,15 @@ func (t *Table) buildQuery(queryType string) (string, []interface{}) {
 		}
 	}
 
+	// 聚合查询只返回一行，排序和分页没有意义；EXISTS 找到一条记录即可
+	switch queryType {
+	case "AGGREGATE":
+		return query.String(), args
+	case "EXISTS":
+		query.WriteString(" LIMIT 1)")
+		return query.String(), args
+	}
+
 	// 添加排序
 	if t.orderBy != "" {
 		query.WriteString(" ORDER BY ")
@@ -935,31 +1562,31 @@ func (t *Table) buildInsertSQL(insertType string, fields []string) (string, erro
 }
 
 // buildUpdateSQL 构建更新SQL语句
-func (t *Table) buildUpdateSQL(fields []string) (string, []interface{}, error) {
+// 返回的参数已按 SET 子句、WHERE 子句的顺序合并
+func (t *Table) buildUpdateSQL(fields []string, values []interface{}) (string, []interface{}, error) {
 
 	if len(fields) == 0 {
 		return "", nil, fmt.Errorf("更新操作必须指定字段")
 	}
 
-	whereClause, whereArgs := t.GetWhere(true)
-	if whereClause == "" {
+	// 作用域条件（如软删除过滤）不能代替查询条件
+	if len(t.where) == 0 {
 		t.db.logger.Warn("更新操作未指定 WHERE 条件，拒绝执行")
 		return "", nil, fmt.Errorf("更新操作必须指定 WHERE 条件")
 	}
+	whereClause, whereArgs := t.GetWhere(true)
 
 	// 构建SET子句
-	var clause strings.Builder
-	for _, field := range fields {
-		clause.WriteString("`")
-		clause.WriteString(field)
-		clause.WriteString("` = ?,")
+	setClause, args, err := buildSetClause(fields, values)
+	if err != nil {
+		return "", nil, err
 	}
 
 	var sql strings.Builder
 	sql.WriteString("UPDATE ")
 	sql.WriteString(t.tableName)
 	sql.WriteString(" SET ")
-	sql.WriteString(strings.TrimSuffix(clause.String(), ","))
+	sql.WriteString(setClause)
 	sql.WriteString(whereClause)
-	return sql.String(), whereArgs, nil
+	return sql.String(), append(args, whereArgs...), nil
 }
@@ -0,0 +1,90 @@
+package xlorm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetWhereParenthesizesConditionsBeforeScopes(t *testing.T) {
+	db, _ := newFakeDB(t, nil)
+
+	tests := []struct {
+		name  string
+		table *Table
+		want  string
+	}{
+		{
+			name:  "原生 OR 条件与软删除",
+			table: db.M("users").WithSoftDelete("deleted_at").Where("a = 1 OR b = 2"),
+			want:  " WHERE (a = 1 OR b = 2) AND `users`.`deleted_at` IS NULL",
+		},
+		{
+			name:  "多个 AND 条件与软删除",
+			table: db.M("users").WithSoftDelete("deleted_at").Where("a = ?", 1).Where("b = ?", 2),
+			want:  " WHERE (a = ? AND b = ?) AND `users`.`deleted_at` IS NULL",
+		},
+		{
+			name:  "无作用域时不加括号",
+			table: db.M("users").Where("a = 1 OR b = 2"),
+			want:  " WHERE a = 1 OR b = 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer tt.table.Release()
+			got, _ := tt.table.GetWhere(true)
+			if got != tt.want {
+				t.Fatalf("GetWhere() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSoftDeleteScopeDoesNotLeakTrashedRows(t *testing.T) {
+	db, connector := newFakeDB(t, nil)
+
+	if _, err := db.M("users").WithSoftDelete("deleted_at").Where("a = 1 OR b = 2").FindAll(); err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	var query string
+	for _, statement := range connector.statements() {
+		if strings.Contains(statement, "FROM `users`") {
+			query = statement
+		}
+	}
+	if !strings.Contains(query, "WHERE (a = 1 OR b = 2) AND `users`.`deleted_at` IS NULL") {
+		t.Fatalf("软删除条件未与完整查询条件以 AND 连接: %s", query)
+	}
+}
+
+func TestUpdateWhereEqualsGuardIsNotBypassedByOrConditions(t *testing.T) {
+	db, connector := newFakeDB(t, nil)
+
+	tests := []struct {
+		name  string
+		table func() *Table
+		want  string
+	}{
+		{
+			name:  "OrWhere",
+			table: func() *Table { return db.M("users").Where("id = ?", 1).OrWhere("id = ?", 2) },
+			want:  "WHERE (id = ? OR id = ?) AND (`version` = ?)",
+		},
+		{
+			name:  "原生 OR 条件",
+			table: func() *Table { return db.M("users").Where("a = 1 OR b = 2") },
+			want:  "WHERE (a = 1 OR b = 2) AND (`version` = ?)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.table().UpdateWhereEquals("version", 1, 2); err != nil {
+				t.Fatalf("UpdateWhereEquals() error = %v", err)
+			}
+			statements := connector.statements()
+			if got := statements[len(statements)-1]; !strings.Contains(got, tt.want) {
+				t.Fatalf("CAS 条件未与完整查询条件以 AND 连接: %s", got)
+			}
+		})
+	}
+}
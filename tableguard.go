@@ -0,0 +1,62 @@
+package xlorm
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// ErrTableMisuse Table 在执行后被复用或跨 goroutine 使用（需开启 Config.CheckTableMisuse）
+var ErrTableMisuse = errors.New("Table对象误用")
+
+// goroutineID 返回当前 goroutine 的编号，仅用于误用检测
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	s := strings.TrimPrefix(string(buf[:n]), "goroutine ")
+	if i := strings.IndexByte(s, ' '); i > 0 {
+		id, _ := strconv.ParseUint(s[:i], 10, 64)
+		return id
+	}
+	return 0
+}
+
+// checkMisuse 是否开启了误用检测
+func (t *Table) checkMisuse() bool {
+	return t.db != nil && t.db.config.CheckTableMisuse
+}
+
+// claim 记录创建 Table 的 goroutine
+func (t *Table) claim() {
+	if t.checkMisuse() {
+		t.owner = goroutineID()
+	}
+}
+
+// checkUse 检查 Table 是否在执行后被复用或在其他 goroutine 中使用
+func (t *Table) checkUse(op string) error {
+	if !t.checkMisuse() {
+		return nil
+	}
+	var reason string
+	if t.released {
+		reason = "Table已执行并释放，不能继续复用"
+	} else if t.owner != 0 {
+		if gid := goroutineID(); gid != t.owner {
+			reason = fmt.Sprintf("Table由 goroutine %d 创建，不能在 goroutine %d 中使用", t.owner, gid)
+		}
+	}
+	if reason == "" {
+		return nil
+	}
+	t.db.logger.Error("检测到Table对象误用", "table", t.tableName, "op", op, "reason", reason, "stack", string(debug.Stack()))
+	return fmt.Errorf("%w: %s: %s", ErrTableMisuse, op, reason)
+}
+
+// misused 链式方法的误用检查，误用时忽略本次调用
+func (t *Table) misused(op string) bool {
+	return t.checkUse(op) != nil
+}
@@ -0,0 +1,152 @@
+package xlorm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	defaultCreatedAtField = "created_at" // 默认创建时间字段
+	defaultUpdatedAtField = "updated_at" // 默认更新时间字段
+)
+
+// TableSettings 表的默认操作设置，通过 SetTableSettings 注册后由 M/Table 自动应用，
+// 每个模型只需配置一次，无需在每个调用处重复设置
+type TableSettings struct {
+	BatchSize       int           // BatchInsert、BatchUpdate 未指定每批数量时使用的默认值（未设置时为1000）
+	SoftDeleteField string        // 软删除字段，等同于每次调用 WithSoftDelete
+	Timestamps      bool          // 插入时自动写入创建时间和更新时间，更新时自动写入更新时间，数据中已有该字段时不覆盖
+	CreatedAtField  string        // 创建时间字段（默认 created_at）
+	UpdatedAtField  string        // 更新时间字段（默认 updated_at）
+	PrimaryKey      string        // 主键字段（默认 id），用于 FindInBatches 等分块读取
+	CacheTTL        time.Duration // 大于0时查询结果自动缓存，等同于每次调用 Cacheable
+}
+
+// SetTableSettings 注册表的默认操作设置，tableName 为不含表前缀的表名，例如：
+//
+//	db.SetTableSettings("orders", xlorm.TableSettings{BatchSize: 500, SoftDeleteField: "deleted_at", Timestamps: true})
+//	db.M("orders").Where("id = ?", 1).Delete() // 软删除并按设置过滤已删除记录
+func (db *DB) SetTableSettings(tableName string, settings TableSettings) error {
+	if tableName == "" {
+		return errors.New("表名不能为空")
+	}
+	if settings.BatchSize < 0 {
+		return fmt.Errorf("批量操作的每批数量不能为负数: %d", settings.BatchSize)
+	}
+	if settings.CacheTTL < 0 {
+		return fmt.Errorf("缓存时间不能为负数: %v", settings.CacheTTL)
+	}
+	if settings.CreatedAtField == "" {
+		settings.CreatedAtField = defaultCreatedAtField
+	}
+	if settings.UpdatedAtField == "" {
+		settings.UpdatedAtField = defaultUpdatedAtField
+	}
+	if settings.PrimaryKey == "" {
+		settings.PrimaryKey = defaultChunkField
+	}
+	for _, field := range []string{settings.CreatedAtField, settings.UpdatedAtField, settings.PrimaryKey} {
+		if !isValidFieldName(field) {
+			return fmt.Errorf("字段名非法: %s", field)
+		}
+	}
+	if settings.SoftDeleteField != "" && !isValidFieldName(settings.SoftDeleteField) {
+		return fmt.Errorf("软删除字段非法: %s", settings.SoftDeleteField)
+	}
+	db.tableSettings.Store(tableName, &settings)
+	return nil
+}
+
+// TableSettings 获取表的默认操作设置
+func (db *DB) TableSettings(tableName string) (TableSettings, bool) {
+	if v, ok := db.tableSettings.Load(tableName); ok {
+		return *v.(*TableSettings), true
+	}
+	return TableSettings{}, false
+}
+
+// RemoveTableSettings 移除表的默认操作设置
+func (db *DB) RemoveTableSettings(tableName string) {
+	db.tableSettings.Delete(tableName)
+}
+
+// applySettings 应用表的默认操作设置，之后的链式调用可覆盖
+func (t *Table) applySettings() {
+	v, ok := t.db.tableSettings.Load(t.name)
+	if !ok {
+		return
+	}
+	settings := v.(*TableSettings)
+	t.settings = settings
+	if settings.SoftDeleteField != "" {
+		t.softDeleteField = settings.SoftDeleteField
+	}
+	if settings.CacheTTL > 0 {
+		t.cacheAuto = true
+		t.cacheTTL = settings.CacheTTL
+	}
+}
+
+// defaultBatchSize 批量操作未指定每批数量时使用的默认值
+func (t *Table) defaultBatchSize() int {
+	if t.settings != nil && t.settings.BatchSize > 0 {
+		return t.settings.BatchSize
+	}
+	return defaultBatchSize
+}
+
+// primaryKey 主键字段
+func (t *Table) primaryKey() string {
+	if t.settings != nil {
+		return t.settings.PrimaryKey
+	}
+	return defaultChunkField
+}
+
+// withTimestamps 开启 Timestamps 时追加创建时间（仅插入）和更新时间字段，数据中已有的字段不覆盖
+func (t *Table) withTimestamps(fields []string, values []interface{}, insert bool) ([]string, []interface{}) {
+	if t.settings == nil || !t.settings.Timestamps {
+		return fields, values
+	}
+	now := time.Now()
+	if insert && !slices.Contains(fields, t.settings.CreatedAtField) {
+		fields = append(fields[:len(fields):len(fields)], t.settings.CreatedAtField)
+		values = append(values[:len(values):len(values)], now)
+	}
+	if !slices.Contains(fields, t.settings.UpdatedAtField) {
+		fields = append(fields[:len(fields):len(fields)], t.settings.UpdatedAtField)
+		values = append(values[:len(values):len(values)], now)
+	}
+	return fields, values
+}
+
+// stampRows 开启 Timestamps 时为批量插入的每条记录补充创建时间和更新时间，返回新的记录，不修改调用方的数据
+func (t *Table) stampRows(rows []map[string]interface{}) []map[string]interface{} {
+	if t.settings == nil || !t.settings.Timestamps || len(rows) == 0 {
+		return rows
+	}
+	now := time.Now()
+	stamped := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		_, hasCreated := row[t.settings.CreatedAtField]
+		_, hasUpdated := row[t.settings.UpdatedAtField]
+		if hasCreated && hasUpdated {
+			stamped[i] = row
+			continue
+		}
+		copied := make(map[string]interface{}, len(row)+2)
+		for k, v := range row {
+			copied[k] = v
+		}
+		if !hasCreated {
+			copied[t.settings.CreatedAtField] = now
+		}
+		if !hasUpdated {
+			copied[t.settings.UpdatedAtField] = now
+		}
+		stamped[i] = copied
+	}
+	return stamped
+}
@@ -0,0 +1,145 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 内置后台任务名称
+const (
+	taskKeepAlive            = "keepalive"
+	taskPoolStats            = "pool-stats"
+	taskAutoIncrementMonitor = "auto-increment-monitor"
+	taskQueryKiller          = "query-killer"
+	taskDebugSignals         = "debug-signals"
+	taskDebugControlFile     = "debug-control-file"
+	taskShadow               = "shadow"
+	taskDualWrite            = "dual-write"
+)
+
+// TaskStatus 后台任务状态
+type TaskStatus struct {
+	Name      string    // 任务名称
+	Running   bool      // 是否运行中
+	StartedAt time.Time // 启动时间
+	StoppedAt time.Time // 结束时间，运行中为零值
+	Err       error     // 任务返回的错误或 panic
+}
+
+// taskRegistry 后台任务注册表，Close 时取消并等待全部任务退出
+type taskRegistry struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	tasks  map[string]*task
+	closed bool
+}
+
+// task 后台任务
+type task struct {
+	cancel    context.CancelFunc
+	done      chan struct{}
+	startedAt time.Time
+	stoppedAt time.Time
+	err       error
+}
+
+// newTaskRegistry 创建后台任务注册表
+func newTaskRegistry() *taskRegistry {
+	return &taskRegistry{tasks: make(map[string]*task)}
+}
+
+// RegisterTask 注册并启动后台任务，ctx 在 StopTask 或 Close 时取消，fn 应在 ctx 取消后尽快返回
+// 同名任务运行中时返回错误，已结束的同名任务会被替换
+func (db *DB) RegisterTask(name string, fn func(ctx context.Context) error) error {
+	if name == "" || fn == nil {
+		return errors.New("任务名称和函数不能为空")
+	}
+	r := db.tasks
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return errors.New("数据库连接已关闭")
+	}
+	if existing, ok := r.tasks[name]; ok && existing.running() {
+		return fmt.Errorf("任务 %s 已在运行", name)
+	}
+
+	ctx, cancel := context.WithCancel(db.ctx)
+	t := &task{cancel: cancel, done: make(chan struct{}), startedAt: time.Now()}
+	r.tasks[name] = t
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		err := runTask(ctx, fn)
+		cancel()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			db.logger.Error("后台任务异常退出", "task", name, "error", err)
+		}
+		r.mu.Lock()
+		t.stoppedAt = time.Now()
+		t.err = err
+		r.mu.Unlock()
+		close(t.done)
+	}()
+	return nil
+}
+
+// StopTask 停止后台任务并等待其退出，任务已结束时直接返回
+func (db *DB) StopTask(name string) error {
+	db.tasks.mu.Lock()
+	t, ok := db.tasks.tasks[name]
+	db.tasks.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("任务 %s 不存在", name)
+	}
+	t.cancel()
+	<-t.done
+	return nil
+}
+
+// Tasks 获取全部后台任务的状态，按名称排序
+func (db *DB) Tasks() []TaskStatus {
+	db.tasks.mu.Lock()
+	defer db.tasks.mu.Unlock()
+	statuses := make([]TaskStatus, 0, len(db.tasks.tasks))
+	for name, t := range db.tasks.tasks {
+		statuses = append(statuses, TaskStatus{
+			Name:      name,
+			Running:   t.running(),
+			StartedAt: t.startedAt,
+			StoppedAt: t.stoppedAt,
+			Err:       t.err,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// wait 禁止注册新任务并等待全部任务退出，调用前需取消 db.ctx
+func (r *taskRegistry) wait() {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+	r.wg.Wait()
+}
+
+// running 任务是否运行中，调用方需持有注册表的锁
+func (t *task) running() bool {
+	return t.stoppedAt.IsZero()
+}
+
+// runTask 执行任务函数，将 panic 转换为错误
+func runTask(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("任务 panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,53 @@
+package xlorm
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Timeout 设置本次操作每条语句的超时时间，覆盖 Config.DefaultQueryTimeout，超时后查询被取消并返回 context.DeadlineExceeded
+// 开启 Config.MaxExecutionTimeHint 时同时为 SELECT 添加 MAX_EXECUTION_TIME 提示，由服务端中止超时的查询
+func (t *Table) Timeout(d time.Duration) *Table {
+	if t.misused("Timeout") {
+		return t
+	}
+	if d <= 0 {
+		t.db.logger.Error("查询超时时间必须为正数", "timeout", d)
+		return t
+	}
+	t.timeout = d
+	return t
+}
+
+// queryTimeout 获取每条语句的超时时间，为0时不限制
+func (t *Table) queryTimeout() time.Duration {
+	if t.timeout > 0 {
+		return t.timeout
+	}
+	return t.db.config.DefaultQueryTimeout
+}
+
+// withTimeout 为语句的执行设置超时时间，未设置超时时间时返回原上下文
+func (t *Table) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withQueryTimeout(ctx, t.queryTimeout())
+}
+
+// executionHint 获取 SELECT 的 MAX_EXECUTION_TIME 提示，未开启或未设置超时时间时返回空字符串
+func (t *Table) executionHint() string {
+	timeout := t.queryTimeout()
+	if !t.db.config.MaxExecutionTimeHint || timeout <= 0 {
+		return ""
+	}
+	// MAX_EXECUTION_TIME 的单位为毫秒，且必须为正数
+	ms := max(timeout.Milliseconds(), 1)
+	return "/*+ MAX_EXECUTION_TIME(" + strconv.FormatInt(ms, 10) + ") */ "
+}
+
+// withQueryTimeout 为语句的执行设置超时时间，timeout 不大于0时返回原上下文
+func withQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
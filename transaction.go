@@ -1,16 +1,21 @@
 package xlorm
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
 // Transaction 事务管理器结构体
 type Transaction struct {
 	*sql.Tx
-	db      *DB
-	traceID string // 事务跟踪ID
+	db           *DB
+	traceID      string      // 事务跟踪ID
+	savepointSeq int         // 嵌套事务保存点序号
+	startTime    time.Time   // 事务开始时间
+	dualWrites   dualWriteTx // 提交后复制到双写副库的语句
 }
 
 // Commit 提交事务
@@ -28,10 +33,14 @@ func (tx *Transaction) Commit() error {
 	}
 	if err := tx.Tx.Commit(); err != nil {
 		tx.db.asyncDBMetrics.RecordError()
-		return fmt.Errorf("提交事务失败: %v, trace_id:%s", err, tx.traceID)
+		err = fmt.Errorf("提交事务失败: %v, trace_id:%s", err, tx.traceID)
+		tx.notifyPlugins(TxCommit, err)
+		return err
 	}
 
 	tx.db.asyncDBMetrics.RecordQueryDuration("commit_transaction", time.Since(startTime))
+	tx.db.commitDualWrites(context.Background(), tx.dualWrites.take())
+	tx.notifyPlugins(TxCommit, nil)
 	return nil
 }
 
@@ -45,9 +54,12 @@ func (tx *Transaction) Rollback() error {
 	if tx.db.IsDebug() {
 		tx.db.logger.Debug("回滚事务", "trace_id", tx.traceID)
 	}
+	tx.dualWrites.take()
 	if err := tx.Tx.Rollback(); err != nil {
 		tx.db.asyncDBMetrics.RecordError()
-		return fmt.Errorf("回滚事务失败: %v, trace_id:%s", err, tx.traceID)
+		err = fmt.Errorf("回滚事务失败: %v, trace_id:%s", err, tx.traceID)
+		tx.notifyPlugins(TxRollback, err)
+		return err
 	}
 
 	if tx.db.IsDebug() {
@@ -57,10 +69,107 @@ func (tx *Transaction) Rollback() error {
 		)
 	}
 	tx.db.asyncDBMetrics.RecordQueryDuration("rollback_transaction", time.Since(startTime))
+	tx.notifyPlugins(TxRollback, nil)
 	return nil
 }
 
+// notifyPlugins 通知插件事务事件
+func (tx *Transaction) notifyPlugins(typ TxEventType, err error) {
+	if !tx.db.hasPlugins() {
+		return
+	}
+	tx.db.pluginsOnTx(TxEvent{Type: typ, TraceID: tx.traceID, Duration: time.Since(tx.startTime), Err: err})
+}
+
 // DB 获取数据库实例
 func (tx *Transaction) DB() *DB {
 	return tx.db
 }
+
+// Savepoint 创建保存点
+func (tx *Transaction) Savepoint(name string) error {
+	if err := tx.execSavepoint("SAVEPOINT", name, "savepoint"); err != nil {
+		return err
+	}
+	tx.dualWrites.savepoint(name)
+	return nil
+}
+
+// RollbackTo 回滚到指定保存点，保存点之后的操作被撤销，事务仍可继续使用
+func (tx *Transaction) RollbackTo(name string) error {
+	if err := tx.execSavepoint("ROLLBACK TO SAVEPOINT", name, "rollback_to_savepoint"); err != nil {
+		return err
+	}
+	tx.dualWrites.rollbackTo(name)
+	return nil
+}
+
+// ReleaseSavepoint 释放保存点
+func (tx *Transaction) ReleaseSavepoint(name string) error {
+	return tx.execSavepoint("RELEASE SAVEPOINT", name, "release_savepoint")
+}
+
+// ExecTx 在当前事务中执行嵌套事务
+// 嵌套调用会映射为保存点：fn 返回错误时仅回滚到保存点，不影响外层事务
+// 因此由多个事务函数组合而成的逻辑可以在外层事务中安全复用
+func (tx *Transaction) ExecTx(fn func(*Transaction) error) error {
+	if tx == nil || tx.Tx == nil {
+		return fmt.Errorf("事务为空")
+	}
+
+	tx.savepointSeq++
+	name := fmt.Sprintf("xlorm_sp_%d", tx.savepointSeq)
+	if err := tx.Savepoint(name); err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.RollbackTo(name)
+			tx.db.logger.Error("嵌套事务异常回滚",
+				"error", "panic",
+				"savepoint", name,
+				"trace_id", tx.traceID,
+			)
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.RollbackTo(name); rbErr != nil {
+			tx.db.logger.Error("回滚到保存点失败",
+				"error", rbErr,
+				"original_error", err,
+				"savepoint", name,
+				"trace_id", tx.traceID,
+			)
+			return fmt.Errorf("执行嵌套事务失败: %v, 回滚失败: %v, trace_id:%s", err, rbErr, tx.traceID)
+		}
+		return fmt.Errorf("执行嵌套事务失败: %v, trace_id:%s", err, tx.traceID)
+	}
+
+	return tx.ReleaseSavepoint(name)
+}
+
+// execSavepoint 执行保存点相关语句
+func (tx *Transaction) execSavepoint(stmt, name, queryType string) error {
+	if tx == nil || tx.Tx == nil {
+		return fmt.Errorf("事务为空")
+	}
+	if name == "" || !isValidFieldName(name) || strings.Contains(name, ".") {
+		return fmt.Errorf("保存点名称非法: %s, trace_id:%s", name, tx.traceID)
+	}
+
+	startTime := time.Now()
+	query := stmt + " `" + name + "`"
+	if tx.db.IsDebug() {
+		tx.db.logger.Debug("执行SQL", queryType, query, "trace_id", tx.traceID)
+	}
+	if _, err := tx.Tx.Exec(query); err != nil {
+		tx.db.asyncDBMetrics.RecordError()
+		tx.db.logger.Error("执行保存点语句失败", queryType, query, "error", err, "trace_id", tx.traceID)
+		return fmt.Errorf("执行 %s 失败: %v, trace_id:%s", stmt, err, tx.traceID)
+	}
+	tx.db.asyncDBMetrics.RecordQueryDuration(queryType, time.Since(startTime))
+	return nil
+}
@@ -9,35 +9,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
-	"unicode"
 )
 
-// 新增：SQL标识符转义函数
-func escapeSQLIdentifier(name string) string {
-	// 添加对保留字的过滤
-	reservedWords := map[string]bool{
-		"select": true,
-		"insert": true,
-		"update": true,
-		"delete": true,
-	}
-	if reservedWords[strings.ToLower(name)] {
-		return "`invalid`"
-	}
-
-	// 过滤非法字符，仅允许字母、数字、下划线和点
-	var safeName strings.Builder
-	for _, r := range name {
-		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' {
-			safeName.WriteRune(r)
-		}
-	}
-	if safeName.Len() == 0 {
-		return "``"
-	}
-	return "`" + safeName.String() + "`"
-}
-
 // safeTimeout 带最小值的超时时间
 func safeTimeout(d time.Duration) string {
 	if d <= 1 {
@@ -93,14 +66,47 @@ func isValidFieldName(field string) bool {
 	return true
 }
 
-// isValidSafeOrderBy 检查是否是安全的OrderBy字符串是否只包含字母、数字、下划线、逗号或空格
-func isValidSafeOrderBy(s string) bool {
-	for _, c := range s {
-		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != ',' && c != ' ' {
-			return false
+// buildInCondition 将切片展开为 IN/NOT IN 条件
+// values 必须为切片或数组；空切片时 IN 生成恒假条件 1 = 0，NOT IN 生成恒真条件 1 = 1
+func buildInCondition(field string, values interface{}, not bool) (string, []interface{}, error) {
+	if !isValidFieldName(field) {
+		return "", nil, fmt.Errorf("字段名非法: %s", field)
+	}
+	val := reflect.ValueOf(values)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return "", nil, fmt.Errorf("IN 条件的值必须为切片或数组: %T", values)
+	}
+	if val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Uint8 {
+		return "", nil, fmt.Errorf("IN 条件的值不支持 []byte 类型")
+	}
+
+	n := val.Len()
+	if n == 0 {
+		if not {
+			return "1 = 1", nil, nil
 		}
+		return "1 = 0", nil, nil
 	}
-	return true
+
+	args := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		args[i] = val.Index(i).Interface()
+	}
+
+	var condition strings.Builder
+	condition.Grow(len(field) + n*2 + 16)
+	condition.WriteString(quoteColumn(field))
+	if not {
+		condition.WriteString(" NOT IN (")
+	} else {
+		condition.WriteString(" IN (")
+	}
+	condition.WriteString(strings.Repeat("?,", n-1))
+	condition.WriteString("?)")
+	return condition.String(), args, nil
 }
 
 // extractFromMapSlice 从map切片提取字段
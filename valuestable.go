@@ -0,0 +1,132 @@
+package xlorm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValuesTable 将内存中的数据构建为派生表，用于与其他表关联而无需创建临时表
+// 服务端为 MySQL 8.0.19 及以上版本时生成 (VALUES ROW(?, ?), ROW(?, ?)) AS `t` (`a`, `b`)，
+// 其他服务端（含 MariaDB、TiDB 及未检测到版本时）生成 (SELECT ? AS `a`, ? AS `b` UNION ALL SELECT ?, ?) AS `t`
+// 返回派生表片段及参数，每行的值数量需与 columns 一致
+func (db *DB) ValuesTable(alias string, columns []string, rows [][]interface{}) (string, []interface{}, error) {
+	if !isValidFieldName(alias) || strings.Contains(alias, ".") {
+		return "", nil, fmt.Errorf("派生表别名非法: %s", alias)
+	}
+	if len(columns) == 0 {
+		return "", nil, errors.New("派生表必须指定字段")
+	}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		if !isValidFieldName(column) || strings.Contains(column, ".") {
+			return "", nil, fmt.Errorf("派生表字段非法: %s", column)
+		}
+		quoted[i] = quoteColumn(column)
+	}
+	if len(rows) == 0 {
+		return "", nil, errors.New("派生表至少需要一行数据")
+	}
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return "", nil, fmt.Errorf("派生表第 %d 行的值数量（%d）与字段数量（%d）不一致", i+1, len(row), len(columns))
+		}
+		args = append(args, row...)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	var query strings.Builder
+	query.WriteByte('(')
+	if db != nil && db.useValuesConstructor() {
+		query.WriteString("VALUES ")
+		for i := range rows {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString("ROW(")
+			query.WriteString(placeholders)
+			query.WriteByte(')')
+		}
+		query.WriteString(") AS ")
+		query.WriteString(quoteColumn(alias))
+		query.WriteString(" (")
+		query.WriteString(strings.Join(quoted, ", "))
+		query.WriteByte(')')
+		return query.String(), args, nil
+	}
+
+	// 第一行使用别名确定字段名
+	query.WriteString("SELECT ")
+	for i, column := range quoted {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("? AS ")
+		query.WriteString(column)
+	}
+	for range rows[1:] {
+		query.WriteString(" UNION ALL SELECT ")
+		query.WriteString(placeholders)
+	}
+	query.WriteString(") AS ")
+	query.WriteString(quoteColumn(alias))
+	return query.String(), args, nil
+}
+
+// useValuesConstructor 是否使用 VALUES 表值构造器，只在确认服务端支持时使用
+func (db *DB) useValuesConstructor() bool {
+	info := db.ServerInfo()
+	return info.Flavor != FlavorUnknown && info.Supports(FeatureValuesTable)
+}
+
+// NewValuesBuilder 创建以内存数据派生表为查询来源的查询构建器，例如：
+//
+//	db.NewValuesBuilder("t", []string{"id", "qty"}, [][]interface{}{{1, 10}, {2, 20}}).
+//		InnerJoin("products p", "p.id = t.id")
+func (db *DB) NewValuesBuilder(alias string, columns []string, rows [][]interface{}) *builder {
+	table, args, err := db.ValuesTable(alias, columns, rows)
+	if err != nil {
+		b := db.NewBuilder(alias)
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b := db.NewBuilder(table)
+	// 派生表位于 FROM 子句，参数排在连接参数之前
+	b.joinArgs = append(b.joinArgs, args...)
+	return b
+}
+
+// JoinValues 与内存数据构建的派生表关联，kind 为 INNER JOIN、LEFT JOIN 或 RIGHT JOIN，例如：
+//
+//	db.NewBuilder("products p").
+//		JoinValues("INNER JOIN", "t", []string{"id", "qty"}, [][]interface{}{{1, 10}, {2, 20}}, "t.id = p.id")
+func (b *builder) JoinValues(kind, alias string, columns []string, rows [][]interface{}, on string, args ...interface{}) *builder {
+	kind = strings.ToUpper(strings.Join(strings.Fields(kind), " "))
+	switch kind {
+	case "JOIN", "INNER JOIN", "LEFT JOIN", "RIGHT JOIN":
+	default:
+		b.errs = append(b.errs, fmt.Errorf("不支持的连接类型: %s", kind))
+		return b
+	}
+	if strings.TrimSpace(on) == "" {
+		b.errs = append(b.errs, fmt.Errorf("%s 的连接条件不能为空: %s", kind, alias))
+		return b
+	}
+	table, valuesArgs, err := b.db.ValuesTable(alias, columns, rows)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	if strings.Count(on, "?") != len(args) {
+		b.errs = append(b.errs, fmt.Errorf("Join参数数量不匹配: join:%s,args_count:%d", on, len(args)))
+		return b
+	}
+	if strings.ContainsAny(on, ";\x00") {
+		b.errs = append(b.errs, fmt.Errorf("Join检测到可能的SQL注入尝试: %s", on))
+		return b
+	}
+	b.joins = append(b.joins, kind+" "+table+" ON "+on)
+	b.joinArgs = append(append(b.joinArgs, valuesArgs...), args...)
+	return b
+}
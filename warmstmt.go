@@ -0,0 +1,67 @@
+package xlorm
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// stmtWarmer 连接预热的语句注册表，新连接建立时预编译全部语句
+type stmtWarmer struct {
+	queries atomic.Pointer[map[string]struct{}] // 需要预编译的语句
+	logger  atomic.Pointer[slog.Logger]         // DB 创建完成后设置
+}
+
+// newStmtWarmer 创建语句注册表
+func newStmtWarmer(queries []string) *stmtWarmer {
+	w := &stmtWarmer{}
+	set := make(map[string]struct{}, len(queries))
+	for _, query := range queries {
+		if query = strings.TrimSpace(query); query != "" {
+			set[query] = struct{}{}
+		}
+	}
+	w.queries.Store(&set)
+	return w
+}
+
+// add 添加需要预编译的语句
+func (w *stmtWarmer) add(queries ...string) {
+	for {
+		old := w.queries.Load()
+		set := make(map[string]struct{}, len(*old)+len(queries))
+		for query := range *old {
+			set[query] = struct{}{}
+		}
+		for _, query := range queries {
+			set[query] = struct{}{}
+		}
+		if w.queries.CompareAndSwap(old, &set) {
+			return
+		}
+	}
+}
+
+// contains 语句是否需要预编译
+func (w *stmtWarmer) contains(query string) bool {
+	_, ok := (*w.queries.Load())[query]
+	return ok
+}
+
+// WarmStatements 注册关键语句，连接池每个新建的连接都会预先编译这些语句，
+// 之后在该连接上执行相同语句（带参数查询、Prepare）时直接复用，热路径上不再产生预编译的往返
+// 语句需与执行时的 SQL 完全一致；已建立的连接在首次使用该语句时编译并缓存；
+// 预热语句执行时不追加查询标签（WithQueryTag、WithQueryTags）及追踪ID注释
+func (db *DB) WarmStatements(queries ...string) error {
+	if db.stmtWarmer == nil {
+		return errors.New("当前连接不支持语句预热")
+	}
+	for i, query := range queries {
+		if queries[i] = strings.TrimSpace(query); queries[i] == "" {
+			return errors.New("预热语句不能为空")
+		}
+	}
+	db.stmtWarmer.add(queries...)
+	return nil
+}
@@ -0,0 +1,236 @@
+package xlorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 批量写操作类型
+const (
+	batchOpInsert = "insert"
+	batchOpUpdate = "update"
+	batchOpDelete = "delete"
+)
+
+// Batch 批量写操作，按添加顺序在同一事务中执行
+// 可先通过 ValidateBatch 校验、RenderBatch 渲染为SQL供审查，再通过 RunBatch 原子执行，例如：
+//
+//	var b xlorm.Batch
+//	b.Insert("orders", order).
+//		Update("users", map[string]interface{}{"balance": xlorm.Dec(100)}, "id = ?", uid).
+//		Delete("carts", "user_id = ?", uid)
+//	results, err := db.RunBatch(ctx, &b)
+type Batch struct {
+	ops []batchOp
+}
+
+// batchOp 单个写操作
+type batchOp struct {
+	kind      string        // 操作类型
+	table     string        // 表名（不含表前缀）
+	data      interface{}   // 插入/更新数据
+	condition string        // 更新/删除条件
+	args      []interface{} // 条件参数
+}
+
+// BatchStatement 批量写操作渲染出的SQL语句
+type BatchStatement struct {
+	Table string        // 表名
+	Query string        // SQL语句
+	Args  []interface{} // 参数
+}
+
+// BatchResult 批量写操作中单条语句的执行结果
+type BatchResult struct {
+	RowsAffected int64 // 影响行数
+	LastInsertId int64 // 插入记录的ID，仅插入操作有效
+}
+
+// Insert 添加插入操作，data 支持 map[string]interface{} 和结构体
+func (b *Batch) Insert(table string, data interface{}) *Batch {
+	b.ops = append(b.ops, batchOp{kind: batchOpInsert, table: table, data: data})
+	return b
+}
+
+// Update 添加更新操作，condition 为必填的 WHERE 条件
+func (b *Batch) Update(table string, data interface{}, condition string, args ...interface{}) *Batch {
+	b.ops = append(b.ops, batchOp{kind: batchOpUpdate, table: table, data: data, condition: condition, args: args})
+	return b
+}
+
+// Delete 添加删除操作，condition 为必填的 WHERE 条件
+func (b *Batch) Delete(table string, condition string, args ...interface{}) *Batch {
+	b.ops = append(b.ops, batchOp{kind: batchOpDelete, table: table, condition: condition, args: args})
+	return b
+}
+
+// Len 获取操作数量
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+// ValidateBatch 校验批量写操作，返回所有操作的错误
+func (db *DB) ValidateBatch(b *Batch) error {
+	_, err := db.RenderBatch(b)
+	return err
+}
+
+// RenderBatch 将批量写操作渲染为SQL语句，不执行
+func (db *DB) RenderBatch(b *Batch) ([]BatchStatement, error) {
+	return db.renderBatch(db.GetContext(), b)
+}
+
+// renderBatch 渲染批量写操作，表前缀可由上下文指定
+func (db *DB) renderBatch(ctx context.Context, b *Batch) ([]BatchStatement, error) {
+	if b == nil || len(b.ops) == 0 {
+		return nil, errors.New("批量写操作为空")
+	}
+	statements := make([]BatchStatement, 0, len(b.ops))
+	var errs []error
+	for i, op := range b.ops {
+		stmt, err := db.renderBatchOp(ctx, op)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("第 %d 个操作(%s %s)非法: %v", i+1, op.kind, op.table, err))
+			continue
+		}
+		statements = append(statements, stmt)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return statements, nil
+}
+
+// RunBatch 在同一事务中按顺序执行批量写操作，任一操作失败时回滚全部操作；试运行时只收集SQL不执行
+func (db *DB) RunBatch(ctx context.Context, b *Batch) ([]BatchResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	statements, err := db.renderBatch(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]BatchResult, 0, len(statements))
+	if db.dryRunCaptureFor(ctx) != nil {
+		// 试运行只收集SQL，各操作返回零值结果
+		for i, stmt := range statements {
+			db.captureDryRun(ctx, "batch_"+b.ops[i].kind, stmt.Table, stmt.Query, stmt.Args)
+			results = append(results, BatchResult{})
+		}
+		return results, nil
+	}
+
+	startTime := time.Now()
+	err = db.ExecTx(func(tx *Transaction) error {
+		for i, stmt := range statements {
+			db.logQuery(ctx, "执行SQL", "runBatch", stmt.Query, stmt.Args)
+			qi := &queryInfo{ctx: ctx, operation: "batch_" + b.ops[i].kind, table: stmt.Table, query: stmt.Query, args: stmt.Args, dualWriteTx: &tx.dualWrites}
+			db.beforeQuery(qi)
+			result, err := tx.ExecContext(ctx, db.tagQuery(ctx, stmt.Query), stmt.Args...)
+			qi.err = err
+			if err != nil {
+				db.afterQuery(qi)
+				db.asyncDBMetrics.RecordError()
+				db.logger.ErrorContext(ctx, "执行SQL失败", "runBatch", stmt.Query, "args", db.redactArgs(stmt.Query, stmt.Args), "error", err)
+				return fmt.Errorf("第 %d 个操作(%s %s)执行失败: %v", i+1, b.ops[i].kind, stmt.Table, err)
+			}
+			var res BatchResult
+			res.RowsAffected, _ = result.RowsAffected()
+			if b.ops[i].kind == batchOpInsert {
+				res.LastInsertId, _ = result.LastInsertId()
+			}
+			qi.rows = res.RowsAffected
+			db.afterQuery(qi)
+			results = append(results, res)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var totalAffected int64
+	for _, res := range results {
+		totalAffected += res.RowsAffected
+	}
+	db.asyncDBMetrics.RecordQueryDuration("run_batch", time.Since(startTime))
+	db.asyncDBMetrics.RecordAffectedRows(totalAffected)
+
+	tables := make([]string, len(statements))
+	for i, stmt := range statements {
+		tables[i] = stmt.Table
+	}
+	if err := db.invalidateTables(tables...); err != nil {
+		db.logger.Error("使查询缓存失效失败", "tables", tables, "error", err)
+	}
+	return results, nil
+}
+
+// renderBatchOp 渲染单个写操作
+func (db *DB) renderBatchOp(ctx context.Context, op batchOp) (BatchStatement, error) {
+	if op.table == "" {
+		return BatchStatement{}, errors.New("表名不能为空")
+	}
+	if strings.ContainsAny(op.table, ";\x00") {
+		return BatchStatement{}, fmt.Errorf("表名检测到可能的SQL注入尝试: %s", op.table)
+	}
+	if op.kind != batchOpInsert {
+		if op.condition == "" {
+			return BatchStatement{}, errors.New("必须指定 WHERE 条件")
+		}
+		if strings.Count(op.condition, "?") != len(op.args) {
+			return BatchStatement{}, fmt.Errorf("条件参数数量不匹配: condition:%s,args_count:%d", op.condition, len(op.args))
+		}
+		if strings.ContainsAny(op.condition, ";\x00") {
+			return BatchStatement{}, fmt.Errorf("条件检测到可能的SQL注入尝试: %s", op.condition)
+		}
+	}
+
+	t := db.M(op.table)
+	defer t.Release()
+	t.resolvePrefix(ctx)
+	stmt := BatchStatement{Table: t.rawTableName()}
+
+	var fields []string
+	var values []interface{}
+	if op.kind != batchOpDelete {
+		var err error
+		fields, values, err = t.extractFieldsAndValues(op.data)
+		if err != nil {
+			return BatchStatement{}, err
+		}
+		if len(fields) == 0 {
+			return BatchStatement{}, errors.New("数据不能为空")
+		}
+		for _, field := range fields {
+			if !isValidFieldName(field) {
+				return BatchStatement{}, fmt.Errorf("字段包含非法字符: %s", field)
+			}
+		}
+	}
+
+	switch op.kind {
+	case batchOpInsert:
+		query, err := t.buildInsertSQL("INSERT", fields)
+		if err != nil {
+			return BatchStatement{}, err
+		}
+		stmt.Query, stmt.Args = query, values
+	case batchOpUpdate:
+		t.Where(op.condition, op.args...)
+		query, args, err := t.buildUpdateSQL(fields, values)
+		if err != nil {
+			return BatchStatement{}, err
+		}
+		stmt.Query, stmt.Args = query, args
+	case batchOpDelete:
+		t.Where(op.condition, op.args...)
+		stmt.Query, stmt.Args = t.buildQuery("DELETE")
+	default:
+		return BatchStatement{}, fmt.Errorf("不支持的操作类型: %s", op.kind)
+	}
+	return stmt, nil
+}
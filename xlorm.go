@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -21,27 +22,68 @@ const (
 // DB 数据库操作主结构体
 type DB struct {
 	*sql.DB
-	dbName             string          // 数据库名称
-	tablePre           string          // 表前缀
-	wg                 sync.WaitGroup  // 等待组,用于等待所有任务携程退出
-	ctxMu              *sync.RWMutex   // 改为指针类型
-	logLevelVar        *slog.LevelVar  // 当前日志级别
-	asyncDBMetrics     *asyncDBMetrics // 异步性能指标
-	logger             *slog.Logger    // 日志记录器
-	structFieldsCache  *shardedCache   // 结构体字段缓存
-	placeholderCache   *shardedCache   // 占位符缓存
-	StructMapper       *StructMapper   // 回调函数注册表
-	startTime          time.Time       // 启动时间
-	slowQueryThreshold time.Duration   // 慢查询阈值
-	closed             atomic.Bool     // 是否已关闭
+	dbName             string                                   // 数据库名称
+	tablePre           string                                   // 表前缀
+	tableSchema        string                                   // 表默认所属数据库
+	tablePreResolver   func(ctx context.Context) (string, bool) // 根据上下文解析表前缀
+	ctxMu              *sync.RWMutex                            // 改为指针类型
+	logLevelVar        *slog.LevelVar                           // 当前日志级别
+	logSampler         *logSampler                              // 日志采样器
+	asyncDBMetrics     *asyncDBMetrics                          // 异步性能指标
+	logger             *slog.Logger                             // 日志记录器
+	structFieldsCache  *shardedCache                            // 结构体字段缓存
+	placeholderCache   *shardedCache                            // 占位符缓存
+	StructMapper       *StructMapper                            // 回调函数注册表
+	startTime          time.Time                                // 启动时间
+	slowQueryThreshold time.Duration                            // 慢查询阈值
+	closed             atomic.Bool                              // 是否已关闭
 	ctx                context.Context
 	cancel             context.CancelFunc
 	poolStatsEnabled   atomic.Bool   // 原子状态标识
-	poolStatsTicker    *time.Ticker  // 统计定时器
-	poolStatsStop      chan struct{} // 停止信号
 	poolStatsMutex     *sync.Mutex   // 互斥锁保护
 	poolStatsInterval  time.Duration // 连接池统计间隔
-	debug              bool          // 调试模式
+	debug              atomic.Bool   // 调试模式
+
+	autoIncrementWarnRatio float64                           // 自增ID使用率告警阈值
+	onAutoIncrementWarning func(AutoIncrementUsage)          // 自增ID告警回调
+	instanceID             string                            // 实例标识，写入连接属性用于识别本实例的连接
+	queryKillerAllowlist   []string                          // 长查询终止白名单
+	hooks                  *hookRegistry                     // 钩子注册表
+	events                 chan QueryEvent                   // 查询事件流
+	eventsEnabled          atomic.Bool                       // 是否投递查询事件
+	eventsClosed           bool                              // 查询事件流是否已关闭
+	eventsMu               *sync.RWMutex                     // 保护查询事件流关闭
+	droppedEvents          atomic.Uint64                     // 丢弃的查询事件数量
+	anomalyDetector        *anomalyDetector                  // 查询异常检测器
+	onAnomaly              func(AnomalyAlert)                // 查询异常回调
+	profileSlowQueries     bool                              // 是否在慢查询时采集快照
+	profileDir             string                            // 慢查询快照目录
+	profileMinInterval     time.Duration                     // 两次快照的最小间隔
+	lastProfileTime        atomic.Int64                      // 上次采集快照的时间（纳秒）
+	queryCache             *queryCacheState                  // 查询结果缓存
+	namedQueries           *namedQueryRegistry               // 命名查询注册表
+	plugins                *pluginRegistry                   // 插件注册表
+	config                 Config                            // 补全默认值后的配置
+	slowLog                *slowLog                          // 最近的慢查询
+	costGuard              *costGuard                        // 执行前的查询成本检查
+	levelBeforeDebug       slog.Level                        // 通过信号开启调试模式前的日志级别
+	tasks                  *taskRegistry                     // 后台任务注册表
+	dryRun                 atomic.Bool                       // 全局试运行模式
+	dryRunCapture          *SQLCapture                       // 全局试运行模式下收集的SQL
+	explainBusy            atomic.Bool                       // 是否正在自动 EXPLAIN 慢查询
+	shadow                 atomic.Pointer[shadowMirror]      // 影子流量镜像
+	queryTags              atomic.Pointer[map[string]string] // 追加到每条SQL的全局查询标签
+	shards                 sync.Map                          // 表名到分表规则的映射
+	fieldAllowlists        sync.Map                          // 表名到字段白名单的映射
+	tableSettings          sync.Map                          // 表名到默认操作设置的映射
+	dualWrite              atomic.Pointer[DualWriter]        // 所属的双写器
+	stmtWarmer             *stmtWarmer                       // 新连接预编译的语句
+	poolHistory            *poolStatsHistory                 // 连接池统计历史
+	poolAlerts             *poolAlertState                   // 连接池告警状态
+	externalDB             bool                              // 连接池是否由调用方通过 NewFromDB 传入
+	serverInfo             atomic.Pointer[ServerInfo]        // 连接建立时检测到的服务端版本
+	scopes                 *scopeRegistry                    // 全局和表级作用域
+	relations              relationRegistry                  // 通过 RegisterRelation 注册的关联
 }
 
 // New 创建新的数据库连接
@@ -49,7 +91,18 @@ func New(cfg *Config) (*DB, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("数据库参数配置有误: %v", err)
 	}
-	// 设置默认值
+	cfg.setDefaults()
+
+	switch cfg.Driver {
+	case "mysql":
+		return newMySQL(cfg)
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", cfg.Driver)
+	}
+}
+
+// setDefaults 设置默认值
+func (cfg *Config) setDefaults() {
 	if cfg.DBName == "" {
 		cfg.DBName = "master"
 	}
@@ -90,9 +143,39 @@ func New(cfg *Config) (*DB, error) {
 			cfg.PoolStatsInterval = 60 * time.Second // 默认60秒
 		}
 	}
+	if cfg.AutoIncrementCheckInterval > 0 {
+		if cfg.AutoIncrementCheckInterval < time.Minute {
+			cfg.AutoIncrementCheckInterval = time.Minute
+		}
+		if cfg.AutoIncrementWarnRatio <= 0 || cfg.AutoIncrementWarnRatio > 1 {
+			cfg.AutoIncrementWarnRatio = defaultAutoIncrementWarnRatio
+		}
+	}
+	if cfg.QueryKillerInterval > 0 {
+		if cfg.QueryKillerInterval < time.Second {
+			cfg.QueryKillerInterval = time.Second
+		}
+		if cfg.QueryKillerTimeout < time.Second {
+			cfg.QueryKillerTimeout = 5 * time.Minute
+		}
+	}
 	if cfg.DBMetricsBufferSize == 0 {
 		cfg.DBMetricsBufferSize = 1000 // 默认1000
 	}
+	if cfg.AnomalyFactor > 0 && cfg.AnomalyFactor <= 1 {
+		cfg.AnomalyFactor = 3 // 倍数必须大于1，默认3倍
+	}
+	if cfg.ProfileSlowQueries {
+		if cfg.ProfileDir == "" {
+			cfg.ProfileDir = filepath.Join(cfg.LogDir, "profiles")
+		}
+		if cfg.ProfileMinInterval <= 0 {
+			cfg.ProfileMinInterval = time.Minute // 默认1分钟
+		}
+	}
+	if cfg.EventBufferSize <= 0 {
+		cfg.EventBufferSize = 1000 // 默认1000
+	}
 	if cfg.LogDir == "" {
 		cfg.LogDir = "./logs"
 	}
@@ -102,15 +185,18 @@ func New(cfg *Config) (*DB, error) {
 		cfg.LogRotationMaxAge = 30 // 默认保留30天
 	}
 
+	if cfg.SlowLogSize == 0 {
+		cfg.SlowLogSize = defaultSlowLogSize
+	}
+	if cfg.PoolStatsHistorySize <= 0 {
+		cfg.PoolStatsHistorySize = defaultPoolHistorySize
+	}
+
 	if cfg.LogBufferSize == 0 {
 		cfg.LogBufferSize = 5000
 	}
-
-	switch cfg.Driver {
-	case "mysql":
-		return newMySQL(cfg)
-	default:
-		return nil, fmt.Errorf("不支持的数据库驱动: %s", cfg.Driver)
+	if cfg.LogOverflowPolicy == LogOverflowBlock && cfg.LogBlockTimeout == 0 {
+		cfg.LogBlockTimeout = 100 * time.Millisecond
 	}
 }
 
@@ -124,6 +210,7 @@ func (db *DB) Table(tableName string) *Table {
 	t := tablePool.Get().(*Table)
 	t.Reset()
 	t.db = db
+	t.claim()
 	if tableName == "" {
 		db.logger.Error("tableName不能为空", "table", tableName)
 		return t
@@ -133,20 +220,47 @@ func (db *DB) Table(tableName string) *Table {
 		db.logger.Error("检测到可能的SQL注入尝试", "table", tableName)
 		return t
 	}
+	t.name = tableName
 	t.tableName = db.GetTableName(tableName)
+	t.applySettings()
 	return t
 }
 
 // GetTableName 获取数据库完整表名
+// 支持 schema.table 形式跨库访问同一服务器上的其他数据库，表前缀仅加在表名部分；
+// 未指定 schema 时使用配置的 TableSchema
 func (db *DB) GetTableName(tableName string) string {
+	return db.buildTableName(tableName, db.tablePre)
+}
+
+// buildTableName 使用指定表前缀生成完整表名
+func (db *DB) buildTableName(tableName, prefix string) string {
+	schema, table := splitTableName(tableName)
+	if schema == "" {
+		schema = db.tableSchema
+	}
 	var builder strings.Builder
+	if schema != "" {
+		builder.WriteString("`")
+		builder.WriteString(schema)
+		builder.WriteString("`.")
+	}
 	builder.WriteString("`")
-	builder.WriteString(db.tablePre)
-	builder.WriteString(tableName)
+	builder.WriteString(prefix)
+	builder.WriteString(table)
 	builder.WriteString("`")
 	return builder.String()
 }
 
+// splitTableName 拆分 schema.table 形式的表名，表名可带反引号
+func splitTableName(tableName string) (schema, table string) {
+	tableName = strings.ReplaceAll(tableName, "`", "")
+	if i := strings.IndexByte(tableName, '.'); i >= 0 {
+		return tableName[:i], tableName[i+1:]
+	}
+	return "", tableName
+}
+
 // WithContext 设置上下文
 func (db *DB) WithContext(ctx context.Context) *DB {
 	db.ctxMu.Lock()
@@ -155,15 +269,23 @@ func (db *DB) WithContext(ctx context.Context) *DB {
 	return db
 }
 
-// GetContext 获取上下文
+// GetContext 获取上下文，未带 ctx 参数的查询和 Table 操作使用该上下文
 func (db *DB) GetContext() context.Context {
 	db.ctxMu.RLock()
 	defer db.ctxMu.RUnlock()
+	if db.ctx == nil {
+		return context.Background()
+	}
 	return db.ctx
 }
 
 // Begin 开始事务
 func (db *DB) Begin() (*Transaction, error) {
+	return db.BeginWithContext(context.Background())
+}
+
+// BeginWithContext 带上下文的Begin，ctx 取消时事务自动回滚
+func (db *DB) BeginWithContext(ctx context.Context) (*Transaction, error) {
 	if db == nil || db.DB == nil {
 		return nil, errors.New("数据库连接为空")
 	}
@@ -172,14 +294,16 @@ func (db *DB) Begin() (*Transaction, error) {
 	if db.IsDebug() {
 		db.logger.Debug("开始事务", "trace_id", traceID)
 	}
-	tx, err := db.DB.Begin()
+	tx, err := db.DB.BeginTx(ctx, nil)
 	if err != nil {
 		db.asyncDBMetrics.RecordError()
 		return nil, fmt.Errorf("开始事务失败: %v, trace_id:%s", err, traceID)
 	}
 
 	db.asyncDBMetrics.RecordQueryDuration("begin_transaction", time.Since(startTime))
-	return &Transaction{tx, db, traceID}, nil
+	transaction := &Transaction{Tx: tx, db: db, traceID: traceID, startTime: startTime}
+	transaction.notifyPlugins(TxBegin, nil)
+	return transaction, nil
 }
 
 // ExecTx 在事务中执行操作
@@ -250,8 +374,18 @@ func (db *DB) WithCache(cache Cache, key string, expiration time.Duration, fn fu
 	return value, nil
 }
 
-// InvalidateCache 使缓存失效
+// InvalidateCache 使缓存失效，缓存实现了 MultiDeleter 时批量删除
 func (db *DB) InvalidateCache(cache Cache, keys ...string) error {
+	if md, ok := cache.(MultiDeleter); ok && len(keys) > 1 {
+		if err := md.DeleteMulti(keys...); err != nil {
+			db.logger.Error("批量删除缓存失败",
+				"keys", keys,
+				"error", err,
+			)
+			return newDBError("InvalidateCache", err, "", nil)
+		}
+		return nil
+	}
 	for _, key := range keys {
 		if err := cache.Delete(key); err != nil {
 			db.logger.Error("删除缓存失败",
@@ -313,19 +447,25 @@ func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
 		return nil, errors.New("执行查询失败，查询语句为空")
 	}
 
+	ctx := db.GetContext()
+	if err := db.checkQueryCost(ctx, query, args); err != nil {
+		return nil, err
+	}
+
 	startTime := time.Now()
-	db.logger.Debug("执行查询",
-		"query", query,
-		"args", args,
-	)
+	db.logQuery(ctx, "执行查询", "query", query, args)
 
-	rows, err := db.DB.Query(query, args...)
+	qi := &queryInfo{ctx: ctx, operation: "query", query: query, args: args, rowsUnknown: true}
+	db.beforeQuery(qi)
+	rows, err := db.QueryContext(ctx, db.tagQuery(ctx, query), args...)
 	duration := time.Since(startTime)
+	qi.err = err
+	db.afterQuery(qi)
 	if err != nil {
 		db.asyncDBMetrics.RecordError()
-		db.logger.Error("查询失败",
+		db.logger.ErrorContext(ctx, "查询失败",
 			"query", query,
-			"args", args,
+			"args", db.redactArgs(query, args),
 			"error", err,
 			"duration", duration,
 		)
@@ -337,9 +477,9 @@ func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
 	// 检查是否是慢查询
 	if duration > db.slowQueryThreshold {
 		db.asyncDBMetrics.RecordSlowQuery()
-		db.logger.Warn("慢查询",
+		db.logger.WarnContext(ctx, "慢查询",
 			"query", query,
-			"args", args,
+			"args", db.redactArgs(query, args),
 			"duration", duration.Seconds(),
 		)
 	}
@@ -352,20 +492,23 @@ func (db *DB) QueryWithContext(ctx context.Context, query string, args ...interf
 	if db == nil || db.DB == nil {
 		return nil, errors.New("数据库连接为空")
 	}
-	startTime := time.Now()
-	if db.IsDebug() {
-		db.logger.Debug("执行查询",
-			"query", query,
-			"args", args,
-		)
+	if err := db.checkQueryCost(ctx, query, args); err != nil {
+		return nil, err
 	}
-	rows, err := db.DB.QueryContext(ctx, query, args...)
+
+	startTime := time.Now()
+	db.logQuery(ctx, "执行查询", "query", query, args)
+	qi := &queryInfo{ctx: ctx, operation: "queryWithContext", query: query, args: args, rowsUnknown: true}
+	db.beforeQuery(qi)
+	rows, err := db.QueryContext(ctx, db.tagQuery(ctx, query), args...)
 	duration := time.Since(startTime)
+	qi.err = err
+	db.afterQuery(qi)
 	if err != nil {
 		db.asyncDBMetrics.RecordError()
-		db.logger.Error("查询失败",
+		db.logger.ErrorContext(ctx, "查询失败",
 			"query", query,
-			"args", args,
+			"args", db.redactArgs(query, args),
 			"error", err,
 			"duration", duration.Seconds(),
 		)
@@ -377,9 +520,9 @@ func (db *DB) QueryWithContext(ctx context.Context, query string, args ...interf
 	// 检查是否是慢查询
 	if duration > db.slowQueryThreshold {
 		db.asyncDBMetrics.RecordSlowQuery()
-		db.logger.Warn("慢查询",
+		db.logger.WarnContext(ctx, "慢查询",
 			"query", query,
-			"args", args,
+			"args", db.redactArgs(query, args),
 			"duration", duration.Seconds(),
 		)
 	}
@@ -396,19 +539,23 @@ func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
 		return nil, errors.New("执行更新失败，查询语句为空")
 	}
 	startTime := time.Now()
-	if db.IsDebug() {
-		db.logger.Debug("执行更新",
-			"query", query,
-			"args", args,
-		)
-	}
-	result, err := db.DB.Exec(query, args...)
+	db.logQuery(db.GetContext(), "执行更新", "query", query, args)
+	ctx, cancel := withQueryTimeout(db.GetContext(), db.config.DefaultQueryTimeout)
+	defer cancel()
+	qi := &queryInfo{ctx: ctx, operation: "exec", query: query, args: args}
+	db.beforeQuery(qi)
+	result, err := db.ExecContext(ctx, db.tagQuery(ctx, query), args...)
 	duration := time.Since(startTime)
+	qi.err = err
+	if err == nil {
+		qi.rows, _ = result.RowsAffected()
+	}
+	db.afterQuery(qi)
 	if err != nil {
 		db.asyncDBMetrics.RecordError()
 		db.logger.Error("更新失败",
 			"query", query,
-			"args", args,
+			"args", db.redactArgs(query, args),
 			"error", err,
 			"duration", duration.Seconds(),
 		)
@@ -422,7 +569,7 @@ func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
 		db.asyncDBMetrics.RecordSlowQuery()
 		db.logger.Warn("慢更新",
 			"query", query,
-			"args", args,
+			"args", db.redactArgs(query, args),
 			"duration", duration.Seconds(),
 		)
 	}
@@ -467,14 +614,13 @@ func (db *DB) SetDBMetricsEnable(enable bool) {
 	}
 	db.poolStatsEnabled.Store(enable)
 	if enable {
-		go db.collectPoolStats(db.poolStatsInterval)
-	} else {
-		// 安全停止
-		if db.poolStatsTicker != nil {
-			db.poolStatsTicker.Stop()
+		if err := db.RegisterTask(taskPoolStats, db.collectPoolStats); err != nil {
+			db.poolStatsEnabled.Store(false)
+			db.logger.Error("开启连接池统计失败", "error", err)
 		}
-		close(db.poolStatsStop)
-		db.poolStatsStop = make(chan struct{})
+	} else {
+		// 停止并等待统计协程退出
+		_ = db.StopTask(taskPoolStats)
 		poolStats.init()
 	}
 }
@@ -496,22 +642,21 @@ func (db *DB) Logger() *slog.Logger {
 
 // AsyncLogger 获取异步日志实例
 func (db *DB) AsyncLogger() *asyncLogger {
-	if asyncLogger, ok := db.logger.Handler().(*asyncLogger); ok {
+	if asyncLogger, ok := unwrapLogHandler(db.logger.Handler()).(*asyncLogger); ok {
 		return asyncLogger
 	}
 	return nil
 }
 
-func (db *DB) SetDebug(bool) *DB {
-	db.ctxMu.Lock()
-	defer db.ctxMu.Unlock()
-	db.debug = true
+// SetDebug 开启或关闭调试模式
+func (db *DB) SetDebug(debug bool) *DB {
+	db.debug.Store(debug)
 	return db
 }
 
 // IsDebug 判断日志功能是否启用
 func (db *DB) IsDebug() bool {
-	return db.debug
+	return db.debug.Load()
 }
 
 // SetLogLevel 动态调整日志级别
@@ -552,26 +697,37 @@ func (db *DB) Close() error {
 	defer db.asyncDBMetrics.Stop()
 	// 取消上下文，触发所有协程退出
 	db.cancel()
-	// 等待所有后台协程退出（探活、统计等）
-	db.wg.Wait()
+	// 等待所有后台任务退出（探活、统计等）
+	db.tasks.wait()
+	// 关闭查询事件流
+	db.closeEvents()
 
 	var errs []error
-	// 关闭数据库连接
-	if err := db.DB.Close(); err != nil {
-		errs = append(errs, fmt.Errorf("关闭数据库连接失败: %w", err))
+	// 关闭插件
+	if err := db.closePlugins(); err != nil {
+		errs = append(errs, err)
 	}
 
-	// 关闭日志文件
-	if rotatingHandler, ok := db.logger.Handler().(*rotatingFileHandler); ok {
-		if err := rotatingHandler.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("关闭日志文件失败: %w", err))
+	// 关闭数据库连接，通过 NewFromDB 传入的连接池由调用方关闭
+	if !db.externalDB {
+		if err := db.DB.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭数据库连接失败: %w", err))
 		}
 	}
 
-	// 异步关闭日志处理器
-	if handler, ok := db.logger.Handler().(*asyncLogger); ok {
-		if err := handler.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("关闭日志处理器失败: %w", err))
+	// 关闭日志文件，外部日志实例由调用方管理
+	if db.config.Logger == nil {
+		if rotatingHandler, ok := unwrapLogHandler(db.logger.Handler()).(*rotatingFileHandler); ok {
+			if err := rotatingHandler.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("关闭日志文件失败: %w", err))
+			}
+		}
+
+		// 异步关闭日志处理器
+		if handler, ok := unwrapLogHandler(db.logger.Handler()).(*asyncLogger); ok {
+			if err := handler.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("关闭日志处理器失败: %w", err))
+			}
 		}
 	}
 	// 停止统计协程
@@ -588,19 +744,17 @@ func (db *DB) Close() error {
 	return nil
 }
 
-// 添加定期Ping
-func (db *DB) startKeepAlive() {
+// keepAlive 定期Ping
+func (db *DB) keepAlive(ctx context.Context) error {
 	ticker := time.NewTicker(30 * time.Second)
-	db.wg.Add(1)
-	defer db.wg.Done()
 	defer ticker.Stop()
 	db.logger.Debug("开启连接探活协程")
 	for {
 		select {
 		case <-ticker.C:
 			// 执行探活逻辑
-			ctx, cancel := context.WithTimeout(db.ctx, 5*time.Second)
-			err := db.PingContext(ctx)
+			pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			err := db.PingContext(pingCtx)
 			cancel()
 
 			if err != nil && !errors.Is(err, context.Canceled) {
@@ -609,39 +763,32 @@ func (db *DB) startKeepAlive() {
 				)
 			}
 
-		case <-db.ctx.Done():
+		case <-ctx.Done():
 			// 上下文已取消，退出循环
 			db.logger.Debug("停止连接探活协程")
-			return
+			return nil
 		}
 	}
 }
 
 // collectPoolStats 定期收集连接池统计信息
-func (db *DB) collectPoolStats(poolStatsInterval time.Duration) {
-	db.poolStatsMutex.Lock()
-	defer db.poolStatsMutex.Unlock()
-	db.wg.Add(1)
-	defer db.wg.Done()
-	db.poolStatsTicker = time.NewTicker(poolStatsInterval)
+func (db *DB) collectPoolStats(ctx context.Context) error {
+	ticker := time.NewTicker(db.poolStatsInterval)
+	defer ticker.Stop()
 	db.logger.Debug("开启连接池统计协程")
 	poolStats.init()
 	for {
 		select {
-		case <-db.poolStatsTicker.C:
-			if !db.poolStatsEnabled.Load() {
-				return
-			}
+		case <-ticker.C:
 			stats := db.DB.Stats()
 			poolStats.update(&stats)
-		case <-db.poolStatsStop:
+			sample := PoolStatsSample{Time: time.Now(), Stats: stats}
+			db.poolHistory.add(sample)
+			db.checkPoolAlerts(sample)
+		case <-ctx.Done():
 			poolStats.init()
 			db.logger.Debug("停止连接池统计协程")
-			return
-		case <-db.ctx.Done():
-			poolStats.init()
-			db.logger.Debug("结束连接池统计协程")
-			return
+			return nil
 		}
 	}
 }